@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/faciam-dev/goquent/orm/migration"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+func runApprove(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printApproveUsage(stderr)
+		return 2
+	}
+	switch args[0] {
+	case "sign":
+		return runApproveSign(args[1:], stdout, stderr)
+	case "-h", "--help", "help":
+		printApproveUsage(stdout)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown approve command %q\n", args[0])
+		printApproveUsage(stderr)
+		return 2
+	}
+}
+
+func runApproveSign(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("goquent approve sign", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	keyPath := fs.String("key", "", "PEM ed25519 private key of the approver")
+	approver := fs.String("approver", "", "approver identity included in the signature")
+	reason := fs.String("reason", "", "approval reason")
+	fingerprint := fs.String("fingerprint", "", "QueryPlan fingerprint to approve")
+	scope := fs.String("scope", "", "optional approval scope")
+	expires := fs.String("expires", "", "expiry date (YYYY-MM-DD or RFC3339)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: goquent approve sign --key key.pem --approver name --reason text (--fingerprint sha256:... | <migration.sql ...>)")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if strings.TrimSpace(*keyPath) == "" || strings.TrimSpace(*reason) == "" {
+		fmt.Fprintln(stderr, "goquent approve sign requires --key and --reason")
+		return 2
+	}
+
+	subject := strings.TrimSpace(*fingerprint)
+	switch {
+	case subject != "" && fs.NArg() > 0:
+		fmt.Fprintln(stderr, "goquent approve sign takes either --fingerprint or migration SQL files, not both")
+		return 2
+	case subject == "":
+		sqlText, err := readMigrationSQL(fs.Args())
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		subject = migration.Checksum(sqlText)
+	}
+
+	keyPEM, err := os.ReadFile(*keyPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	key, err := query.ParsePrivateKey(keyPEM)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	approval := query.Approval{
+		Reason:    strings.TrimSpace(*reason),
+		Scope:     strings.TrimSpace(*scope),
+		CreatedBy: strings.TrimSpace(*approver),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if strings.TrimSpace(*expires) != "" {
+		expiresAt, err := parseApprovalTime(*expires)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		approval.ExpiresAt = &expiresAt
+	}
+	if err := query.SignApproval(&approval, subject, key); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	record := query.ApprovalRecord{
+		Fingerprint: approval.Fingerprint,
+		Reason:      approval.Reason,
+		Approver:    approval.CreatedBy,
+		Scope:       approval.Scope,
+		CreatedAt:   approval.CreatedAt,
+		ExpiresAt:   approval.ExpiresAt,
+		KeyID:       approval.KeyID,
+		Signature:   approval.Signature,
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(record); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	return 0
+}
+
+// loadApprovalRecord reads one signed approval written by goquent approve
+// sign.
+func loadApprovalRecord(path string) (query.Approval, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return query.Approval{}, err
+	}
+	var record query.ApprovalRecord
+	if err := json.Unmarshal(b, &record); err != nil {
+		return query.Approval{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if strings.TrimSpace(record.Reason) == "" {
+		return query.Approval{}, fmt.Errorf("%s: %w", path, query.ErrApprovalReasonRequired)
+	}
+	return *record.Approval(), nil
+}
+
+func parseApprovalTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry %q: use YYYY-MM-DD or RFC3339", value)
+	}
+	return t.UTC(), nil
+}
+
+func printApproveUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: goquent approve <command>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  sign      sign an approval for a QueryPlan fingerprint or migration SQL")
+}
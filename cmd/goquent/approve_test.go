@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestKeyPair(t *testing.T, dir string) (keyPath, pubPath string) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath = filepath.Join(dir, "approver.pem")
+	pubPath = filepath.Join(dir, "trusted.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return keyPath, pubPath
+}
+
+func TestApproveSignAndVerifyMigration(t *testing.T) {
+	dir := t.TempDir()
+	keyPath, pubPath := writeTestKeyPair(t, dir)
+	migrationPath := filepath.Join(dir, "001_drop.sql")
+	if err := os.WriteFile(migrationPath, []byte("ALTER TABLE users DROP COLUMN legacy_id;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"approve", "sign", "--key", keyPath, "--approver", "dba", "--reason", "legacy column unused", "--expires", "2999-01-01", migrationPath}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("sign exit code %d stderr=%s", code, stderr.String())
+	}
+	approvalPath := filepath.Join(dir, "approval.json")
+	if err := os.WriteFile(approvalPath, stdout.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"migrate", "dry-run", "--trusted-keys", pubPath, "--approve", "looks fine", migrationPath}, &stdout, &stderr)
+	if code != 1 || !bytes.Contains(stderr.Bytes(), []byte("not signed")) {
+		t.Fatalf("expected unsigned approval to fail, got %d stderr=%s", code, stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"migrate", "dry-run", "--trusted-keys", pubPath, "--approval-file", approvalPath, migrationPath}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected signed approval to pass, got %d stderr=%s", code, stderr.String())
+	}
+
+	changed := filepath.Join(dir, "002_drop.sql")
+	if err := os.WriteFile(changed, []byte("ALTER TABLE users DROP COLUMN email;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"migrate", "dry-run", "--trusted-keys", pubPath, "--approval-file", approvalPath, changed}, &stdout, &stderr)
+	if code != 1 || !bytes.Contains(stderr.Bytes(), []byte("signature is not valid")) {
+		t.Fatalf("expected mismatched approval to fail, got %d stderr=%s", code, stderr.String())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/faciam-dev/goquent/orm/config"
 	"github.com/faciam-dev/goquent/orm/review"
 )
 
@@ -25,6 +26,10 @@ func run(args []string, stdout, stderr io.Writer) int {
 		return runReview(args[1:], stdout, stderr)
 	case "migrate":
 		return runMigrate(args[1:], stdout, stderr)
+	case "approve":
+		return runApprove(args[1:], stdout, stderr)
+	case "plans":
+		return runPlans(args[1:], stdout, stderr)
 	case "manifest":
 		return runManifest(args[1:], stdout, stderr)
 	case "operation":
@@ -49,9 +54,11 @@ func runReview(args []string, stdout, stderr io.Writer) int {
 	failOn := fs.String("fail-on", "high", "risk threshold that returns exit code 1")
 	format := fs.String("format", "pretty", "output format: pretty, json, github")
 	showSuppressed := fs.Bool("show-suppressed", false, "include suppressed findings in the primary output")
-	configPath := fs.String("config", "", "reserved path to a goquent review config file")
+	configPath := fs.String("config", "", "goquent.yaml config with risk rules, policies, review patterns, and suppressions")
+	env := fs.String("env", "", "config environment whose risk rules apply (default: the config environment)")
 	manifestPath := fs.String("manifest", "", "manifest JSON path for freshness warnings")
 	requireFreshManifest := fs.Bool("require-fresh-manifest", false, "return exit code 3 when the manifest is stale")
+	approvalsPath := fs.String("approvals", "", "approvals file applied to findings and checked for stale approvals")
 	fs.Usage = func() {
 		fmt.Fprintln(stderr, "Usage: goquent review [flags] [path ...]")
 		fs.PrintDefaults()
@@ -59,7 +66,26 @@ func runReview(args []string, stdout, stderr io.Writer) int {
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
-	_ = strings.TrimSpace(*configPath)
+	var cfg *config.Config
+	if strings.TrimSpace(*configPath) != "" {
+		loaded, err := config.Load(*configPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		cfg = loaded
+		if _, ok := cfg.Environments[*env]; *env != "" && len(cfg.Environments) > 0 && !ok {
+			fmt.Fprintf(stderr, "environment %q is not defined in %s\n", *env, *configPath)
+			return 2
+		}
+		if !flagSet(fs, "fail-on") && cfg.FailOn(*env) != "" {
+			*failOn = string(cfg.FailOn(*env))
+		}
+		if *manifestPath == "" {
+			*manifestPath = cfg.Manifest.Path
+		}
+		*requireFreshManifest = *requireFreshManifest || cfg.Manifest.RequireFresh
+	}
 
 	threshold, err := review.ParseRiskLevel(*failOn)
 	if err != nil {
@@ -79,6 +105,9 @@ func runReview(args []string, stdout, stderr io.Writer) int {
 		ShowSuppressed:       *showSuppressed,
 		ManifestPath:         *manifestPath,
 		RequireFreshManifest: *requireFreshManifest,
+		ApprovalsPath:        *approvalsPath,
+		Config:               cfg,
+		Environment:          *env,
 	})
 	if err != nil {
 		fmt.Fprintln(stderr, err)
@@ -107,12 +136,25 @@ func runReview(args []string, stdout, stderr io.Writer) int {
 	return 0
 }
 
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 func printUsage(w io.Writer) {
 	fmt.Fprintln(w, "Usage: goquent <command>")
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, "Commands:")
 	fmt.Fprintln(w, "  review    review Go source, QueryPlan JSON, and raw SQL files")
 	fmt.Fprintln(w, "  migrate   plan, dry-run, or apply migration SQL")
+	fmt.Fprintln(w, "  approve   sign approvals for query fingerprints and migrations")
+	fmt.Fprintln(w, "  plans     export plan fingerprint allowlists")
 	fmt.Fprintln(w, "  manifest  generate and verify AI-readable schema manifests")
 	fmt.Fprintln(w, "  operation compile structured read-only OperationSpec")
 	fmt.Fprintln(w, "  mcp       run read-only MCP server over stdio")
@@ -2,9 +2,12 @@ package main
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/faciam-dev/goquent/orm/query"
 )
 
 func TestReviewCommandExitCodesAndJSON(t *testing.T) {
@@ -31,6 +34,34 @@ func TestReviewCommandExitCodesAndJSON(t *testing.T) {
 	}
 }
 
+func TestReviewCommandAppliesApprovalsFile(t *testing.T) {
+	dir := t.TempDir()
+	sqlText := "SELECT * FROM users"
+	if err := os.WriteFile(filepath.Join(dir, "query.sql"), []byte(sqlText), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	approvals := filepath.Join(t.TempDir(), "goquent.approvals.json")
+	body := fmt.Sprintf(`{"approvals": [
+  {"fingerprint": %q, "reason": "admin export reviewed", "approver": "dba"},
+  {"fingerprint": "sha256:gone", "reason": "removed report"}
+]}`, query.NewRawPlan(sqlText).Fingerprint)
+	if err := os.WriteFile(approvals, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"review", "--approvals", approvals, "--fail-on", "high", dir}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected approved findings to pass, got %d stdout=%s stderr=%s", code, stdout.String(), stderr.String())
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("approval_reason: admin export reviewed")) {
+		t.Fatalf("expected approved finding, got %s", stdout.String())
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte(query.WarningApprovalStale)) || !bytes.Contains(stdout.Bytes(), []byte("sha256:gone")) {
+		t.Fatalf("expected stale approval finding, got %s", stdout.String())
+	}
+}
+
 func TestReviewCommandRejectsBadFormat(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	code := run([]string{"review", "--format", "sarif"}, &stdout, &stderr)
@@ -38,3 +69,42 @@ func TestReviewCommandRejectsBadFormat(t *testing.T) {
 		t.Fatalf("expected config error exit code, got %d", code)
 	}
 }
+
+func TestReviewCommandUsesConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	sqlPath := filepath.Join(dir, "report.sql")
+	if err := os.WriteFile(sqlPath, []byte("SELECT id FROM users"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(dir, "goquent.yaml")
+	if err := os.WriteFile(cfgPath, []byte(`
+environment: ci
+risk:
+  fail_on: medium
+  rules:
+    RAW_SQL_USED:
+      severity: medium
+environments:
+  ci: {}
+  production:
+    fail_on: blocked
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"review", "--config", cfgPath, sqlPath}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected config fail_on medium to fail, got %d stdout=%s stderr=%s", code, stdout.String(), stderr.String())
+	}
+	stdout.Reset()
+	if code := run([]string{"review", "--config", cfgPath, "--fail-on", "high", sqlPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected --fail-on to override config, got %d stdout=%s", code, stdout.String())
+	}
+	if code := run([]string{"review", "--config", cfgPath, "--env", "production", sqlPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected production fail_on to apply, got %d", code)
+	}
+	stderr.Reset()
+	if code := run([]string{"review", "--config", cfgPath, "--env", "staging", sqlPath}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected unknown environment to fail with 2, got %d stderr=%s", code, stderr.String())
+	}
+}
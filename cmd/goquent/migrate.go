@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"flag"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/faciam-dev/goquent/orm"
 	"github.com/faciam-dev/goquent/orm/migration"
+	"github.com/faciam-dev/goquent/orm/query"
 	"github.com/faciam-dev/goquent/orm/review"
 )
 
@@ -42,6 +44,8 @@ func runMigrateCommand(mode string, args []string, stdout, stderr io.Writer) int
 	format := fs.String("format", "pretty", "output format: pretty, json")
 	failOn := fs.String("fail-on", "", "optional risk threshold that returns exit code 1")
 	approve := fs.String("approve", "", "approval reason for applying risky migrations")
+	approvalFile := fs.String("approval-file", "", "signed approval written by goquent approve sign")
+	trustedKeysPath := fs.String("trusted-keys", "", "PEM public keys; risky migrations need an approval signed by one of them")
 	driverName := fs.String("driver", "", "database driver for apply: mysql or postgres")
 	dsn := fs.String("dsn", "", "database DSN for apply")
 	fs.Usage = func() {
@@ -81,6 +85,23 @@ func runMigrateCommand(mode string, args []string, stdout, stderr io.Writer) int
 	if strings.TrimSpace(*approve) != "" {
 		migrator.RequireApproval(*approve)
 	}
+	if strings.TrimSpace(*approvalFile) != "" {
+		approval, err := loadApprovalRecord(*approvalFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		migrator.WithApproval(approval)
+	}
+	var trustedKeys []ed25519.PublicKey
+	if strings.TrimSpace(*trustedKeysPath) != "" {
+		trustedKeys, err = query.LoadTrustedKeys(*trustedKeysPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		migrator.WithTrustedKeys(trustedKeys...)
+	}
 
 	ctx := context.Background()
 	plan, err := migrator.Plan(ctx)
@@ -90,7 +111,7 @@ func runMigrateCommand(mode string, args []string, stdout, stderr io.Writer) int
 	}
 
 	if mode == "dry-run" || mode == "apply" {
-		if err := migration.EnsureExecutable(plan); err != nil {
+		if err := migration.EnsureExecutable(plan, trustedKeys...); err != nil {
 			_ = writeMigrationOutput(stdout, outputFormat, plan)
 			fmt.Fprintln(stderr, err)
 			return 1
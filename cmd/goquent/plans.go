@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/faciam-dev/goquent/orm/config"
+	"github.com/faciam-dev/goquent/orm/query"
+	"github.com/faciam-dev/goquent/orm/review"
+)
+
+func runPlans(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printPlansUsage(stderr)
+		return 2
+	}
+	switch args[0] {
+	case "export":
+		return runPlansExport(args[1:], stdout, stderr)
+	case "-h", "--help", "help":
+		printPlansUsage(stdout)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown plans command %q\n", args[0])
+		printPlansUsage(stderr)
+		return 2
+	}
+}
+
+func runPlansExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("goquent plans export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	out := fs.String("out", "", "write the allowlist to this file instead of stdout")
+	configPath := fs.String("config", "", "goquent.yaml config whose policies and dialect builder chains are replayed with")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: goquent plans export [--config goquent.yaml] [--out "+query.DefaultAllowlistFile+"] [path ...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var cfg *config.Config
+	if strings.TrimSpace(*configPath) != "" {
+		loaded, err := config.Load(*configPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		cfg = loaded
+	}
+	entries, err := review.CollectPlans(fs.Args(), cfg)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	b, err := query.NewAllowlist(entries...).ToJSON()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	b = append(b, '\n')
+	if strings.TrimSpace(*out) == "" {
+		if _, err := stdout.Write(b); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		return 0
+	}
+	if err := os.WriteFile(*out, b, 0o644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	return 0
+}
+
+func printPlansUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: goquent plans <command>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  export    write an allowlist of plan fingerprints for WithAllowlist")
+}
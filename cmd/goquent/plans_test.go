@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+func TestPlansExportWritesAllowlist(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"queries.sql": "-- name: FindUser :one\nSELECT id, name FROM users WHERE id = :id;\n",
+		"repo.go": `package repo
+
+func cleanup(db DB) {
+	db.RequireRawApproval("nightly").Exec("DELETE FROM sessions WHERE expires_at < NOW()")
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(dir, "allowlist", query.DefaultAllowlistFile)
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"plans", "export", "--out", out, dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d stderr=%s", code, stderr.String())
+	}
+	allowlist, err := query.LoadAllowlist(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sql := range []string{
+		"SELECT id, name FROM users WHERE id = :id",
+		"DELETE FROM sessions WHERE expires_at < NOW()",
+	} {
+		if fp := query.NewRawPlan(sql).Fingerprint; !allowlist.Allows(fp) {
+			t.Fatalf("expected %q (%s) in allowlist: %+v", sql, fp, allowlist.Entries())
+		}
+	}
+	if len(allowlist.Entries()) != 2 {
+		t.Fatalf("expected two entries, got %+v", allowlist.Entries())
+	}
+}
+
+func TestPlansExportReplaysChainsWithConfiguredPoliciesAndDialect(t *testing.T) {
+	t.Cleanup(query.ResetPolicyRegistry)
+	dir := t.TempDir()
+	files := map[string]string{
+		"goquent.yaml": "dialect: postgres\npolicies:\n  - table: posts\n    soft_delete_column: deleted_at\n",
+		"repo.go": `package repo
+
+func recent(db DB) {
+	db.Table("posts").Select("id").Where("author_id", 1).Limit(10).GetMaps(nil)
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"plans", "export", "--config", filepath.Join(dir, "goquent.yaml"), dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d stderr=%s", code, stderr.String())
+	}
+	allowlist, err := query.ParseAllowlist(stdout.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime, err := query.New(nil, "posts", driver.PostgresDialect{}).Select("id").Where("author_id", 7).Limit(10).Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(runtime.SQL, "deleted_at") || !allowlist.Allows(runtime.Fingerprint) {
+		t.Fatalf("expected runtime plan %q in allowlist: %+v", runtime.SQL, allowlist.Entries())
+	}
+}
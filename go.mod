@@ -7,6 +7,7 @@ require (
 	github.com/faciam-dev/goquent-query-builder v0.1.0
 	github.com/go-sql-driver/mysql v1.9.3
 	github.com/lib/pq v1.10.9
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require filippo.io/edwards25519 v1.1.1 // indirect
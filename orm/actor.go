@@ -0,0 +1,30 @@
+package orm
+
+import (
+	"context"
+
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+type Actor = query.Actor
+
+const (
+	WarningColumnAccessDenied = query.WarningColumnAccessDenied
+	WarningTableAccessDenied  = query.WarningTableAccessDenied
+)
+
+// WithActor returns a context whose builder queries run as actor: plans
+// record it in metadata and selects are checked against the column access
+// rules declared with ModelPolicyBuilder.Allow. Query.As overrides it for a
+// single query. WithLogger logs the actor of every execution run with ctx.
+//
+//	ctx = orm.WithActor(r.Context(), orm.Actor{ID: userID, Roles: []string{"support"}})
+//	err := db.Table("users").WithContext(ctx).Select("id", "email").Limit(10).GetMaps(&rows)
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return query.WithActor(ctx, actor)
+}
+
+// ActorFromContext returns the actor installed by WithActor.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	return query.ActorFromContext(ctx)
+}
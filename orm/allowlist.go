@@ -0,0 +1,91 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// AllowlistOption configures WithAllowlist.
+type AllowlistOption func(*allowlistGuard)
+
+// AllowlistReportOnly logs each unknown fingerprint once to logger
+// (slog.Default() when nil) at warn level and lets the execution proceed,
+// so an allowlist can be rolled out before it is enforced.
+func AllowlistReportOnly(logger *slog.Logger) AllowlistOption {
+	return func(g *allowlistGuard) {
+		g.reportOnly = true
+		g.logger = logger
+	}
+}
+
+// WithAllowlist refuses to execute builder, raw, named, catalog and generic
+// CRUD queries whose plan fingerprint is not in a, returning
+// ErrPlanNotAllowed before any SQL is sent. Allowlists are written by
+// goquent plans export. The fingerprint is recomputed from the plan rather
+// than read from it. Statements run by Migrator.Apply from a verified
+// migration plan are gated by their migration approval instead. The check is added before the interceptors already
+// configured.
+//
+//	allowlist, err := orm.LoadAllowlist(orm.DefaultAllowlistFile)
+//	if err != nil {
+//		return err
+//	}
+//	db := orm.NewDB(sqlDB, driver.MySQLDialect{}, orm.WithAllowlist(allowlist))
+func WithAllowlist(a *Allowlist, opts ...AllowlistOption) Option {
+	guard := NewAllowlistInterceptor(a, opts...)
+	return func(db *DB) {
+		db.interceptors = append([]Interceptor{guard}, db.interceptors...)
+	}
+}
+
+// NewAllowlistInterceptor returns the interceptor installed by WithAllowlist,
+// for use with Query.WithInterceptors or to control its position in the
+// chain.
+func NewAllowlistInterceptor(a *Allowlist, opts ...AllowlistOption) Interceptor {
+	g := &allowlistGuard{allowlist: a}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g.intercept
+}
+
+// LoadAllowlist reads an allowlist file written by goquent plans export.
+func LoadAllowlist(path string) (*Allowlist, error) {
+	return query.LoadAllowlist(path)
+}
+
+type allowlistGuard struct {
+	allowlist  *Allowlist
+	reportOnly bool
+	logger     *slog.Logger
+	reported   sync.Map
+}
+
+func (g *allowlistGuard) intercept(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+	if isVerifiedMigration(plan) {
+		return next(ctx, plan)
+	}
+	fingerprint := query.PlanFingerprint(plan)
+	if g.allowlist.Allows(fingerprint) {
+		return next(ctx, plan)
+	}
+	if !g.reportOnly {
+		return ExecResult{Rows: -1}, fmt.Errorf("%w: %s %s", ErrPlanNotAllowed, plan.Operation, fingerprint)
+	}
+	if _, seen := g.reported.LoadOrStore(fingerprint, true); !seen {
+		logger := g.logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.LogAttrs(ctx, slog.LevelWarn, "goquent query not in allowlist",
+			slog.String("fingerprint", fingerprint),
+			slog.String("operation", string(plan.Operation)),
+			slog.String("sql", plan.SQL),
+		)
+	}
+	return next(ctx, plan)
+}
@@ -0,0 +1,110 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/migration"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+func TestWithAllowlistRefusesUnknownPlans(t *testing.T) {
+	ctx := context.Background()
+	planner, _ := newSQLMockDB(t, driver.MySQLDialect{})
+	listed, err := planner.Table("users").Select("id").Where("id", 1).Limit(1).Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowlist := query.NewAllowlist(query.NewAllowlistEntry(listed, "test"))
+
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithAllowlist(allowlist))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `users` WHERE `id` = ? LIMIT 1")).
+		WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+
+	var row map[string]any
+	if err := db.Table("users").Select("id").Where("id", 42).Limit(1).FirstMap(&row); err != nil {
+		t.Fatalf("listed query: %v", err)
+	}
+	if err := db.Table("users").Select("id", "email").Where("id", 42).Limit(1).FirstMap(&row); !errors.Is(err, ErrPlanNotAllowed) {
+		t.Fatalf("expected unlisted builder query to be refused, got %v", err)
+	}
+	if _, err := db.RequireRawApproval("reviewed").ExecContext(ctx, "DELETE FROM sessions"); !errors.Is(err, ErrPlanNotAllowed) {
+		t.Fatalf("expected unlisted raw SQL to be refused, got %v", err)
+	}
+	if _, err := Insert(ctx, db, map[string]any{"name": "bob"}, Table("users")); !errors.Is(err, ErrPlanNotAllowed) {
+		t.Fatalf("expected unlisted generic insert to be refused, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAllowlistRecomputesFingerprintsAndExemptsOnlyPlannedMigrations(t *testing.T) {
+	ctx := context.Background()
+	planner, _ := newSQLMockDB(t, driver.MySQLDialect{})
+	listed, err := planner.Table("users").Select("id").Where("id", 1).Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowlist := query.NewAllowlist(query.NewAllowlistEntry(listed, "test"))
+	forge := func(op OperationType) Interceptor {
+		return func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+			plan.Fingerprint = listed.Fingerprint
+			if op != "" {
+				plan.Operation = op
+			}
+			return next(ctx, plan)
+		}
+	}
+
+	for _, op := range []OperationType{"", OperationMigration} {
+		db, _ := newSQLMockDB(t, driver.MySQLDialect{}, WithInterceptors(forge(op), NewAllowlistInterceptor(allowlist)))
+		if _, err := db.RequireRawApproval("reviewed").ExecContext(ctx, "DELETE FROM sessions"); !errors.Is(err, ErrPlanNotAllowed) {
+			t.Fatalf("expected forged %q plan to be refused, got %v", op, err)
+		}
+	}
+
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithAllowlist(allowlist))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE audit_logs (id BIGINT PRIMARY KEY)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if _, err := migration.New("CREATE TABLE audit_logs (id BIGINT PRIMARY KEY);").Apply(ctx, db); err != nil {
+		t.Fatalf("expected planned migration to run, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithAllowlistReportOnlyLogsUnknownFingerprintsOnce(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithAllowlist(query.NewAllowlist(), AllowlistReportOnly(logger)))
+	for _, id := range []int{1, 2} {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM sessions WHERE id = ?")).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	for _, id := range []int{1, 2} {
+		if _, err := db.RequireRawApproval("cleanup").ExecContext(context.Background(), "DELETE FROM sessions WHERE id = ?", id); err != nil {
+			t.Fatalf("report-only exec: %v", err)
+		}
+	}
+	if n := strings.Count(buf.String(), "goquent query not in allowlist"); n != 1 {
+		t.Fatalf("expected one report, got %d: %s", n, buf.String())
+	}
+	if !strings.Contains(buf.String(), query.NewRawPlan("DELETE FROM sessions WHERE id = ?").Fingerprint) {
+		t.Fatalf("expected fingerprint in report: %s", buf.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,47 @@
+package orm
+
+import (
+	"crypto/ed25519"
+
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// WithApprovals checks risky plans against r before execution: builder, raw,
+// named, catalog and generic CRUD plans run only when their fingerprint is
+// approved in r, and RequireApproval or RequireRawApproval reasons count only
+// when r.AllowInline is set.
+//
+//	approvals, err := orm.LoadApprovals(orm.DefaultApprovalsFile)
+//	if err != nil {
+//		return err
+//	}
+//	db := orm.NewDB(sqlDB, driver.MySQLDialect{}, orm.WithApprovals(approvals))
+func WithApprovals(r *ApprovalRegistry) Option {
+	return func(db *DB) { db.approvals = r }
+}
+
+// LoadApprovals reads an approvals file; see query.ApprovalRegistry.
+func LoadApprovals(path string) (*ApprovalRegistry, error) {
+	return query.LoadApprovals(path)
+}
+
+// WithTrustedKeys requires approvals of risky queries and migrations run
+// through the DB to be signed by one of keys (see query.SignApproval):
+// unsigned, expired or mismatched approvals are refused with
+// ErrApprovalRequired.
+//
+//	keys, err := orm.LoadTrustedKeys("approvers.pem")
+//	db := orm.NewDB(sqlDB, dialect, orm.WithApprovals(approvals), orm.WithTrustedKeys(keys...))
+func WithTrustedKeys(keys ...ed25519.PublicKey) Option {
+	return func(db *DB) { db.trustedKeys = append([]ed25519.PublicKey(nil), keys...) }
+}
+
+// LoadTrustedKeys reads ed25519 public keys from a PEM file.
+func LoadTrustedKeys(path string) ([]ed25519.PublicKey, error) {
+	return query.LoadTrustedKeys(path)
+}
+
+// ensureExecutable checks plan against the DB's approvals and trusted keys.
+func (db *DB) ensureExecutable(plan *QueryPlan) error {
+	return db.approvals.EnsurePlanExecutable(plan, db.trustedKeys...)
+}
@@ -0,0 +1,69 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+func TestWithApprovalsRequiresFingerprintApproval(t *testing.T) {
+	const cleanup = "DELETE FROM sessions WHERE expires_at < ?"
+	fingerprint := query.NewRawPlan(cleanup).Fingerprint
+	registry, err := query.ParseApprovals([]byte(`{"approvals": [
+		{"fingerprint": "` + fingerprint + `", "reason": "nightly session cleanup", "approver": "dba"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithApprovals(registry))
+	mock.ExpectExec(regexp.QuoteMeta(cleanup)).
+		WithArgs("2026-01-01").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, cleanup, "2026-01-01"); err != nil {
+		t.Fatalf("approved fingerprint: %v", err)
+	}
+	_, err = db.RequireRawApproval("looks fine to me").ExecContext(ctx, "DELETE FROM users WHERE id = ?", 1)
+	if !errors.Is(err, ErrApprovalRequired) || !strings.Contains(err.Error(), "not in the approval registry") {
+		t.Fatalf("expected inline approval to be refused, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithApprovalsAllowInlineAndExpiry(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	registry, err := query.NewApprovalRegistry(query.ApprovalRecord{
+		Fingerprint: query.NewRawPlan("DELETE FROM sessions").Fingerprint,
+		Reason:      "old cleanup",
+		ExpiresAt:   &expired,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.AllowInline = true
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithApprovals(registry))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users WHERE id = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	if _, err := db.RequireRawApproval("support ticket 42").ExecContext(ctx, "DELETE FROM users WHERE id = ?", 1); err != nil {
+		t.Fatalf("inline approval with allow_inline: %v", err)
+	}
+	if _, err := db.RequireRawApproval("support ticket 42").ExecContext(ctx, "DELETE FROM sessions"); !errors.Is(err, ErrApprovalRequired) || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("expected expired registry approval, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,45 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/faciam-dev/goquent/orm/internal/codec"
+	"github.com/faciam-dev/goquent/orm/internal/pgarray"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// WithPostgresArrayIn makes WhereIn/WhereNotIn on PostgreSQL compile
+// []string and []int64 lists to "= ANY($1)" with a single array parameter.
+func WithPostgresArrayIn() Option {
+	return func(db *DB) { db.arrayIn = true }
+}
+
+func isArrayField(t reflect.Type) bool {
+	scannerType := reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	return pgarray.Supported(t) && !reflect.PointerTo(t).Implements(scannerType)
+}
+
+func decodeArray(dst reflect.Value, src any, _ BoolScanPolicy) error {
+	handled, err := pgarray.Scan(dst, src)
+	if handled {
+		return err
+	}
+	fv := reflect.ValueOf(src)
+	switch {
+	case fv.Type().AssignableTo(dst.Type()):
+		dst.Set(fv)
+	case fv.Type().ConvertibleTo(dst.Type()):
+		dst.Set(fv.Convert(dst.Type()))
+	default:
+		return fmt.Errorf("type conversion failed: %s -> %s", fv.Type(), dst.Type())
+	}
+	return nil
+}
+
+// bindWriteArgs prepares write arguments for the driver: registered codecs
+// encode their types and Postgres slices become array literals.
+func bindWriteArgs(db *DB, args []any) []any {
+	return query.BindArrayArgs(db.drv.Dialect, codec.WrapArgs(args, db.drv.Dialect))
+}
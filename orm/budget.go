@@ -0,0 +1,40 @@
+package orm
+
+import (
+	"context"
+
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+type Budget = query.Budget
+type BudgetEvent = query.BudgetEvent
+type BudgetReport = query.BudgetReport
+type DuplicateShape = query.DuplicateShape
+type QueryBudget = query.QueryBudget
+
+const (
+	WarningNPlusOneSuspected   = query.WarningNPlusOneSuspected
+	WarningQueryBudgetExceeded = query.WarningQueryBudgetExceeded
+)
+
+var ErrQueryBudgetExceeded = query.ErrQueryBudgetExceeded
+
+// WithQueryBudget returns a context that counts the builder, raw, named,
+// catalog and generic CRUD executions run with it by plan fingerprint, and
+// the budget holding the per-request report. Queries run without a context,
+// or with another one, are not counted.
+//
+//	ctx, budget := orm.WithQueryBudget(r.Context(), orm.Budget{MaxQueries: 50, MaxDuplicateShapes: 5})
+//	handler.ServeHTTP(w, r.WithContext(ctx))
+//	if report := budget.Report(); report.Exceeded {
+//		slog.Warn("query budget exceeded", "queries", report.Queries, "duplicates", report.Duplicates)
+//	}
+func WithQueryBudget(ctx context.Context, b Budget) (context.Context, *QueryBudget) {
+	return query.WithQueryBudget(ctx, b)
+}
+
+// QueryBudgetFromContext returns the budget installed by WithQueryBudget, or
+// nil.
+func QueryBudgetFromContext(ctx context.Context) *QueryBudget {
+	return query.QueryBudgetFromContext(ctx)
+}
@@ -0,0 +1,109 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/driver"
+)
+
+func TestQueryBudgetReportsNPlusOne(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{})
+	var events []BudgetEvent
+	ctx, budget := WithQueryBudget(context.Background(), Budget{
+		MaxDuplicateShapes: 2,
+		OnEvent: func(ctx context.Context, event BudgetEvent) {
+			events = append(events, event)
+		},
+	})
+
+	for id := 1; id <= 3; id++ {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `posts` WHERE `user_id` = ? LIMIT 10")).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(id * 10)).AddRow(int64(id*10 + 1)))
+		var rows []map[string]any
+		if err := db.Table("posts").Select("id").Where("user_id", id).Limit(10).WithContext(ctx).GetMaps(&rows); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM sessions WHERE id = ?")).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := db.RequireRawApproval("cleanup").ExecContext(ctx, "DELETE FROM sessions WHERE id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	var unbudgeted []map[string]any
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if err := db.Table("posts").Select("id").Where("user_id", 4).Limit(10).GetMaps(&unbudgeted); err != nil {
+		t.Fatal(err)
+	}
+
+	report := budget.Report()
+	if report.Queries != 4 || report.Rows != 7 || !report.Exceeded {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Duplicates) != 1 || report.Duplicates[0].Count != 3 || !report.Duplicates[0].Suspected {
+		t.Fatalf("unexpected duplicates: %+v", report.Duplicates)
+	}
+	if len(events) != 1 || events[0].Code != WarningNPlusOneSuspected || events[0].Count != 3 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryBudgetFailsWhenExceeded(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{})
+	ctx, budget := WithQueryBudget(context.Background(), Budget{MaxQueries: 1, FailWhenExceeded: true})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `users` WHERE `id` = ? LIMIT 1")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	var row map[string]any
+	if err := db.Table("users").Select("id").Where("id", 1).Limit(1).WithContext(ctx).FirstMap(&row); err != nil {
+		t.Fatal(err)
+	}
+	err := db.Table("users").Select("id").Where("id", 2).Limit(1).WithContext(ctx).FirstMap(&row)
+	if !errors.Is(err, ErrQueryBudgetExceeded) {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	report := budget.Report()
+	if report.Queries != 1 || report.Refused != 1 || !report.Exceeded || len(report.Events) != 1 || report.Events[0].Code != WarningQueryBudgetExceeded {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryBudgetRowLimitFailsOnlyAfterItIsPassed(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{})
+	ctx, budget := WithQueryBudget(context.Background(), Budget{MaxRows: 2, FailWhenExceeded: true})
+
+	for _, ids := range [][]int64{{1, 2}, {3}} {
+		rows := sqlmock.NewRows([]string{"id"})
+		for _, id := range ids {
+			rows.AddRow(id)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `users` LIMIT 10")).WillReturnRows(rows)
+	}
+	for i := 0; i < 2; i++ {
+		var rows []map[string]any
+		if err := db.Table("users").Select("id").Limit(10).WithContext(ctx).GetMaps(&rows); err != nil {
+			t.Fatalf("query %d: %v", i+1, err)
+		}
+	}
+	var rows []map[string]any
+	if err := db.Table("users").Select("id").Limit(10).WithContext(ctx).GetMaps(&rows); !errors.Is(err, ErrQueryBudgetExceeded) {
+		t.Fatalf("expected budget error after the row limit was passed, got %v", err)
+	}
+	report := budget.Report()
+	if report.Rows != 3 || report.Refused != 1 || len(report.Events) != 1 || report.Events[0].Count != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
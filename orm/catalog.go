@@ -0,0 +1,168 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// CatalogEntry is one named query loaded by LoadQueries.
+type CatalogEntry = query.CatalogEntry
+
+// QueryCatalog holds named queries loaded from SQL files.
+type QueryCatalog struct {
+	entries map[string]CatalogEntry
+	names   []string
+}
+
+// LoadQueries parses every file in fsys matching pattern (fs.Glob syntax)
+// for `-- name: GetActiveUsers :many` annotated queries:
+//
+//	//go:embed queries/*.sql
+//	var queryFS embed.FS
+//
+//	catalog, err := orm.LoadQueries(queryFS, "queries/*.sql")
+//
+// Query names must be unique across the catalog.
+func LoadQueries(fsys fs.FS, pattern string) (*QueryCatalog, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("goquent: no query files match %q", pattern)
+	}
+	c := &QueryCatalog{entries: make(map[string]CatalogEntry)}
+	for _, file := range files {
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := query.ParseCatalog(file, string(b))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if prev, ok := c.entries[e.Name]; ok {
+				return nil, fmt.Errorf("goquent: catalog query %q defined in %s:%d and %s:%d",
+					e.Name, prev.Location.File, prev.Location.Line, e.Location.File, e.Location.Line)
+			}
+			c.entries[e.Name] = e
+			c.names = append(c.names, e.Name)
+		}
+	}
+	return c, nil
+}
+
+// Names returns the query names in file order.
+func (c *QueryCatalog) Names() []string {
+	return append([]string(nil), c.names...)
+}
+
+// Lookup returns the named query.
+func (c *QueryCatalog) Lookup(name string) (CatalogEntry, bool) {
+	e, ok := c.entries[name]
+	return e, ok
+}
+
+// CatalogStmt executes one catalog query and scans its rows into T.
+type CatalogStmt[T any] struct {
+	entry CatalogEntry
+	err   error
+}
+
+// CatalogQuery returns a typed executor for the named catalog query. An
+// unknown name is reported when the query runs.
+//
+//	activeUsers := orm.CatalogQuery[User](catalog, "GetActiveUsers")
+//	users, err := activeUsers.All(ctx, db, map[string]any{"active": true})
+func CatalogQuery[T any](c *QueryCatalog, name string) *CatalogStmt[T] {
+	if c == nil {
+		return &CatalogStmt[T]{err: fmt.Errorf("goquent: catalog is nil")}
+	}
+	e, ok := c.entries[name]
+	if !ok {
+		return &CatalogStmt[T]{err: fmt.Errorf("goquent: unknown catalog query %q", name)}
+	}
+	return &CatalogStmt[T]{entry: e}
+}
+
+// Name returns the catalog query name.
+func (s *CatalogStmt[T]) Name() string {
+	return s.entry.Name
+}
+
+// Plan builds the query plan without executing it. params is a
+// map[string]any or struct supplying the query's :name parameters, or nil.
+func (s *CatalogStmt[T]) Plan(ctx context.Context, db *DB, params any) (*QueryPlan, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if db.rawErr != nil {
+		return nil, db.rawErr
+	}
+	plan, err := s.entry.Plan(db.drv.Dialect, params)
+	if err != nil {
+		return nil, err
+	}
+	db.evaluatePlan(plan, s.entry.Suppressions...)
+	if plan.Approval == nil && db.rawApproval != nil {
+		copied := *db.rawApproval
+		plan.Approval = &copied
+	}
+	return plan, nil
+}
+
+// All runs the query and scans every row into T.
+func (s *CatalogStmt[T]) All(ctx context.Context, db *DB, params any) ([]T, error) {
+	plan, err := s.queryPlan(ctx, db, params)
+	if err != nil {
+		return nil, err
+	}
+	return selectAllPlan[T](ctx, db, plan)
+}
+
+// One runs the query and scans the first row into T.
+func (s *CatalogStmt[T]) One(ctx context.Context, db *DB, params any) (T, error) {
+	var zero T
+	plan, err := s.queryPlan(ctx, db, params)
+	if err != nil {
+		return zero, err
+	}
+	return selectOnePlan[T](ctx, db, plan)
+}
+
+// Exec runs a statement that returns no rows.
+func (s *CatalogStmt[T]) Exec(ctx context.Context, db *DB, params any) (sql.Result, error) {
+	plan, err := s.executablePlan(ctx, db, params)
+	if err != nil {
+		return nil, err
+	}
+	return db.execPlan(ctx, plan)
+}
+
+func (s *CatalogStmt[T]) queryPlan(ctx context.Context, db *DB, params any) (*QueryPlan, error) {
+	if s.err == nil && s.entry.Kind == query.CatalogExec {
+		return nil, fmt.Errorf("goquent: catalog query %q is :exec; use Exec", s.entry.Name)
+	}
+	return s.executablePlan(ctx, db, params)
+}
+
+func (s *CatalogStmt[T]) executablePlan(ctx context.Context, db *DB, params any) (*QueryPlan, error) {
+	plan, err := s.Plan(ctx, db, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ensureExecutable(plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
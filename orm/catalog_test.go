@@ -0,0 +1,102 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+var catalogFS = fstest.MapFS{
+	"queries/users.sql": {Data: []byte(`-- Queries for the users report.
+
+-- name: ActiveUsers :many
+-- goquent:approve reason="users report reviewed"
+SELECT id, name
+FROM users
+WHERE active = :active AND id IN (:ids);
+
+-- name: DeactivateUser :exec
+UPDATE users SET active = 0 WHERE id = :id;
+`)},
+}
+
+type catalogUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestCatalogQueryRunsApprovedNamedQuery(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db := NewDB(sqlDB, driver.MySQLDialect{})
+
+	catalog, err := LoadQueries(catalogFS, "queries/*.sql")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := strings.Join(catalog.Names(), ","); got != "ActiveUsers,DeactivateUser" {
+		t.Fatalf("unexpected names: %s", got)
+	}
+
+	active := CatalogQuery[catalogUser](catalog, "ActiveUsers")
+	params := map[string]any{"active": true, "ids": []int64{1, 2}}
+	plan, err := active.Plan(context.Background(), db, params)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.SQL != "SELECT id, name\nFROM users\nWHERE active = ? AND id IN (?, ?)" {
+		t.Fatalf("unexpected SQL: %q", plan.SQL)
+	}
+	if plan.Metadata[query.CatalogQueryMetadataKey] != "ActiveUsers" || plan.Approval == nil {
+		t.Fatalf("expected named, approved plan, got %#v", plan)
+	}
+
+	mock.ExpectQuery(`SELECT id, name\s+FROM users\s+WHERE active = \? AND id IN \(\?, \?\)`).
+		WithArgs(true, int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "alice"))
+	users, err := active.All(context.Background(), db, params)
+	if err != nil {
+		t.Fatalf("all: %v", err)
+	}
+	if len(users) != 1 || users[0].Name != "alice" {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+
+	deactivate := CatalogQuery[struct{}](catalog, "DeactivateUser")
+	if _, err := deactivate.Exec(context.Background(), db, map[string]any{"id": 1}); !errors.Is(err, query.ErrApprovalRequired) {
+		t.Fatalf("expected unapproved catalog query to be rejected, got %v", err)
+	}
+	if _, err := deactivate.All(context.Background(), db, map[string]any{"id": 1}); err == nil || !strings.Contains(err.Error(), "use Exec") {
+		t.Fatalf("expected :exec query to reject All, got %v", err)
+	}
+	if _, err := CatalogQuery[catalogUser](catalog, "Missing").All(context.Background(), db, nil); err == nil || !strings.Contains(err.Error(), `unknown catalog query "Missing"`) {
+		t.Fatalf("expected unknown query error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadQueriesRejectsInvalidCatalogs(t *testing.T) {
+	for name, files := range map[string]fstest.MapFS{
+		"duplicate": {
+			"a.sql": {Data: []byte("-- name: Q :one\nSELECT 1;\n")},
+			"b.sql": {Data: []byte("-- name: Q :one\nSELECT 2;\n")},
+		},
+		"kind":   {"a.sql": {Data: []byte("-- name: Q :rows\nSELECT 1;\n")}},
+		"no sql": {"a.sql": {Data: []byte("-- name: Q :one\n-- nothing here\n")}},
+	} {
+		if _, err := LoadQueries(files, "*.sql"); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
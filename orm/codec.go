@@ -0,0 +1,77 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/internal/codec"
+)
+
+// CodecOption configures RegisterCodec.
+type CodecOption func(*codecConfig)
+
+type codecConfig struct {
+	name    string
+	dialect driver.Dialect
+}
+
+// CodecName registers the codec only under name, for fields tagged
+// `db:"column,codec=name"`, instead of for every field of type T.
+func CodecName(name string) CodecOption {
+	return func(c *codecConfig) { c.name = name }
+}
+
+// CodecDialect limits the codec to d, overriding the dialect-independent
+// registration for the same type or name.
+func CodecDialect(d driver.Dialect) CodecOption {
+	return func(c *codecConfig) { c.dialect = d }
+}
+
+// RegisterCodec teaches goquent to bind and scan values of type T without a
+// sql.Scanner or driver.Valuer implementation. encode converts T to a driver
+// value; decode converts a non-NULL database value to T. Either may be nil to
+// keep the default conversion for that direction. Codecs apply to struct
+// scanning (SelectOne/SelectAll, Query.First/Get), conv.As, and write
+// arguments. Register codecs during program initialization.
+func RegisterCodec[T any](encode func(T) (any, error), decode func(src any) (T, error), opts ...CodecOption) {
+	var cfg codecConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	c := codec.Codec{Type: typ}
+	if encode != nil {
+		c.Encode = func(v any) (any, error) {
+			t, ok := v.(T)
+			if !ok {
+				rv := reflect.ValueOf(v)
+				if !rv.IsValid() || !rv.Type().ConvertibleTo(typ) {
+					return nil, fmt.Errorf("goquent: codec for %s cannot encode %T", typ, v)
+				}
+				t = rv.Convert(typ).Interface().(T)
+			}
+			return encode(t)
+		}
+	}
+	if decode != nil {
+		c.Decode = func(src any) (any, error) { return decode(src) }
+	}
+	codec.Register(cfg.name, cfg.dialect, c)
+}
+
+// decodeCodec applies the codec registered for the field, if any. It reports
+// whether the value was handled.
+func (fm *fieldMeta) decodeCodec(d driver.Dialect, dst reflect.Value, src any) (bool, error) {
+	c, err := codec.Lookup(dst.Type(), fm.Codec, d)
+	if err != nil || c == nil {
+		return err != nil, err
+	}
+	return codec.DecodeInto(c, dst, src)
+}
+
+// fieldArg returns the write argument for a struct field, deferring codec
+// encoding to bind time.
+func fieldArg(db *DB, fm *fieldMeta, fv reflect.Value) any {
+	return codec.Wrap(fv.Interface(), fm.Codec, db.drv.Dialect)
+}
@@ -0,0 +1,146 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/conv"
+	ormdriver "github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/internal/codec"
+)
+
+type cents int64
+
+type invoiceRow struct {
+	ID     int64  `db:"id,pk"`
+	Amount cents  `db:"amount"`
+	Due    *cents `db:"due"`
+	Code   string `db:"code,codec=upper"`
+}
+
+func (invoiceRow) TableName() string { return "invoices" }
+
+func registerTestCodecs(t *testing.T) {
+	t.Helper()
+	codec.Reset()
+	t.Cleanup(codec.Reset)
+	RegisterCodec(
+		func(c cents) (any, error) { return fmt.Sprintf("%d.%02d", c/100, c%100), nil },
+		func(src any) (cents, error) {
+			s := fmt.Sprint(src)
+			if b, ok := src.([]byte); ok {
+				s = string(b)
+			}
+			whole, frac, _ := strings.Cut(s, ".")
+			n, err := strconv.ParseInt(whole+frac, 10, 64)
+			return cents(n), err
+		},
+	)
+	RegisterCodec(
+		func(c cents) (any, error) { return int64(c), nil },
+		nil,
+		CodecDialect(ormdriver.PostgresDialect{}),
+	)
+	RegisterCodec(
+		func(s string) (any, error) { return strings.ToUpper(s), nil },
+		func(src any) (string, error) { return strings.ToLower(fmt.Sprintf("%s", src)), nil },
+		CodecName("upper"),
+	)
+}
+
+func TestSelectDecodesRegisteredCodecs(t *testing.T) {
+	registerTestCodecs(t)
+	db, mock := newMockDB(t, BoolCompat)
+
+	rows := sqlmock.NewRows([]string{"id", "amount", "due", "code"}).AddRow(int64(1), []byte("12.34"), "0.50", "ABC")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	r, err := SelectOne[invoiceRow](context.Background(), db, "SELECT id, amount, due, code FROM invoices")
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if r.Amount != 1234 || r.Due == nil || *r.Due != 50 || r.Code != "abc" {
+		t.Fatalf("unexpected decoded row: %+v", r)
+	}
+
+	rows = sqlmock.NewRows([]string{"id", "amount", "due", "code"}).AddRow(int64(2), "x.1", nil, "A")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	if _, err := SelectOne[invoiceRow](context.Background(), db, "SELECT id, amount, due, code FROM invoices"); err == nil || !strings.Contains(err.Error(), "scan amount") {
+		t.Fatalf("expected decode error for amount, got %v", err)
+	}
+}
+
+func TestWriteEncodesRegisteredCodecs(t *testing.T) {
+	registerTestCodecs(t)
+	for _, tc := range []struct {
+		name    string
+		dialect ormdriver.Dialect
+		amount  driver.Value
+	}{
+		{name: "mysql", dialect: ormdriver.MySQLDialect{}, amount: "12.34"},
+		{name: "postgres override", dialect: ormdriver.PostgresDialect{}, amount: int64(1234)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, exec := newCaptureWriteDB(tc.dialect)
+			if _, err := Insert(context.Background(), db, invoiceRow{ID: 1, Amount: 1234, Code: "abc"}, Columns("amount", "code", "due")); err != nil {
+				t.Fatalf("insert: %v", err)
+			}
+			got := map[string]driver.Value{}
+			for i, col := range insertedColumns(exec.query) {
+				valuer, ok := exec.args[i].(driver.Valuer)
+				if !ok {
+					t.Fatalf("column %s was not wrapped by its codec: %#v", col, exec.args[i])
+				}
+				v, err := valuer.Value()
+				if err != nil {
+					t.Fatalf("encode %s: %v", col, err)
+				}
+				got[col] = v
+			}
+			if got["amount"] != tc.amount || got["code"] != "ABC" || got["due"] != nil {
+				t.Fatalf("unexpected encoded values: %#v", got)
+			}
+		})
+	}
+}
+
+func TestUnknownCodecNameFailsAtBind(t *testing.T) {
+	codec.Reset()
+	t.Cleanup(codec.Reset)
+	db, exec := newCaptureWriteDB(ormdriver.MySQLDialect{})
+	if _, err := Insert(context.Background(), db, invoiceRow{Code: "abc"}, Columns("code")); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err := exec.args[0].(driver.Valuer).Value(); err == nil || !strings.Contains(err.Error(), `codec "upper" is not registered`) {
+		t.Fatalf("expected unknown codec error, got %v", err)
+	}
+}
+
+func TestConvAsUsesRegisteredCodec(t *testing.T) {
+	registerTestCodecs(t)
+	got, err := conv.As[cents]("1.05")
+	if err != nil || got != 105 {
+		t.Fatalf("conv.As: got %d err=%v", got, err)
+	}
+	var row invoiceRow
+	if err := conv.MapToStruct(map[string]any{"amount": "2.00", "code": "XY"}, &row); err != nil {
+		t.Fatalf("MapToStruct: %v", err)
+	}
+	if row.Amount != 200 || row.Code != "xy" {
+		t.Fatalf("unexpected struct: %+v", row)
+	}
+}
+
+func insertedColumns(sqlStr string) []string {
+	open := strings.Index(sqlStr, "(")
+	end := strings.Index(sqlStr, ")")
+	var cols []string
+	for _, c := range strings.Split(sqlStr[open+1:end], ",") {
+		cols = append(cols, strings.Trim(strings.TrimSpace(c), "`\""))
+	}
+	return cols
+}
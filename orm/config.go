@@ -0,0 +1,77 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/faciam-dev/goquent/orm/config"
+	"github.com/faciam-dev/goquent/orm/manifest"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// Config is a parsed goquent.yaml; see package config.
+type Config = config.Config
+
+// DefaultConfigFile is the conventional name of the configuration file.
+const DefaultConfigFile = config.DefaultFile
+
+// LoadConfig reads a goquent.yaml or JSON configuration and returns the
+// option applying it for environment (the file's environment when empty),
+// so that production evaluates plans with the same rules as goquent review
+// --config in CI. When the file names a manifest, its indexes enable the
+// PREDICATE_NOT_INDEXED and ORDER_BY_NOT_INDEXED rules. A missing manifest
+// turns those rules off, or is an error when manifest.require_fresh is set.
+//
+//	opt, err := orm.LoadConfig(orm.DefaultConfigFile, "production")
+//	if err != nil {
+//		return err
+//	}
+//	db := orm.NewDB(sqlDB, driver.MySQLDialect{}, opt)
+func LoadConfig(path, environment string) (Option, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cfg.Environments[environment]; environment != "" && len(cfg.Environments) > 0 && !ok {
+		return nil, fmt.Errorf("goquent: environment %q is not defined in %s", environment, path)
+	}
+	var m *manifest.Manifest
+	if cfg.Manifest.Path != "" {
+		m, err = manifest.Load(cfg.Manifest.Path)
+		if err != nil && (cfg.Manifest.RequireFresh || !errors.Is(err, fs.ErrNotExist)) {
+			return nil, err
+		}
+	}
+	return withConfig(cfg, environment, m), nil
+}
+
+// WithConfig applies cfg for environment: its table policies are
+// registered when the DB is created, builder, raw, named and catalog plans
+// are evaluated with its risk rules, and its suppressions without a path
+// apply to every plan.
+func WithConfig(cfg *Config, environment string) Option {
+	return withConfig(cfg, environment, nil)
+}
+
+func withConfig(cfg *Config, environment string, m *manifest.Manifest) Option {
+	engine := query.NewRiskEngine(cfg.RiskConfig(environment))
+	if m != nil {
+		engine = manifest.NewRiskEngine(m, cfg.RiskConfig(environment))
+	}
+	suppressions := cfg.SuppressionsFor("")
+	return func(db *DB) {
+		db.config = cfg
+		db.riskEngine = engine
+		db.suppressions = suppressions
+	}
+}
+
+// evaluatePlan re-evaluates a raw, named or catalog plan with the configured
+// risk rules, adding the configured suppressions to suppressions.
+func (db *DB) evaluatePlan(plan *QueryPlan, suppressions ...Suppression) {
+	if db.riskEngine == nil {
+		return
+	}
+	query.EvaluatePlan(plan, db.riskEngine, append(suppressions, db.suppressions...)...)
+}
@@ -0,0 +1,359 @@
+// Package config loads goquent.yaml, the shared risk and review
+// configuration used by goquent review in CI and by orm.LoadConfig at runtime.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// DefaultFile is the conventional name of the configuration file.
+const DefaultFile = "goquent.yaml"
+
+// Config is a parsed goquent configuration. YAML and JSON files share the
+// same field names.
+type Config struct {
+	Version int `json:"version,omitempty"`
+	// Environment selects the Environments entry used when callers do not
+	// name one.
+	Environment string `json:"environment,omitempty"`
+	// Dialect is the SQL dialect, mysql or postgres (the default is mysql),
+	// that goquent review and goquent plans export build builder chains
+	// with, so their fingerprints match the runtime plans.
+	Dialect      string              `json:"dialect,omitempty"`
+	Risk         Risk                `json:"risk,omitempty"`
+	Environments map[string]Risk     `json:"environments,omitempty"`
+	Policies     []query.TablePolicy `json:"policies,omitempty"`
+	Manifest     Manifest            `json:"manifest,omitempty"`
+	Review       Review              `json:"review,omitempty"`
+	Suppressions []Suppression       `json:"suppressions,omitempty"`
+
+	dir string
+}
+
+// Risk configures risk rules and the review failure threshold. Entries of
+// Config.Environments override the base Risk rule by rule.
+type Risk struct {
+	FailOn query.RiskLevel                 `json:"fail_on,omitempty"`
+	Rules  map[string]query.RiskRuleConfig `json:"rules,omitempty"`
+	// DeepOffset and LargeInList set the DEEP_OFFSET_PAGINATION and
+	// LARGE_IN_LIST thresholds (see query.RiskConfig).
+	DeepOffset  int64 `json:"deep_offset,omitempty"`
+	LargeInList int   `json:"large_in_list,omitempty"`
+}
+
+// Manifest locates the manifest used for freshness checks.
+type Manifest struct {
+	Path         string `json:"path,omitempty"`
+	RequireFresh bool   `json:"require_fresh,omitempty"`
+}
+
+// Review limits the files goquent review reads. Patterns are slash-separated
+// globs relative to the config file; ** matches any number of directories.
+// InlineApprovals lets goquent:approve comments in SQL catalogs count as
+// approvals without an approvals file.
+type Review struct {
+	Include         []string `json:"include,omitempty"`
+	Exclude         []string `json:"exclude,omitempty"`
+	InlineApprovals bool     `json:"inline_approvals,omitempty"`
+}
+
+// Suppression is a config-scope suppression. An empty Path applies it to
+// every file and to runtime plans; otherwise it applies to reviewed files
+// matching the glob only.
+type Suppression struct {
+	Code    string `json:"code"`
+	Path    string `json:"path,omitempty"`
+	Reason  string `json:"reason"`
+	Owner   string `json:"owner,omitempty"`
+	Expires string `json:"expires,omitempty"`
+
+	expiresAt *time.Time
+}
+
+// Parse parses a YAML or JSON configuration. Unknown fields are rejected.
+func Parse(b []byte) (*Config, error) {
+	var doc any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("goquent: parse config: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	// Round-trip through JSON so that YAML and JSON files are decoded by the
+	// same struct tags.
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("goquent: parse config: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.DisallowUnknownFields()
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("goquent: parse config: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	cfg.dir = "."
+	return &cfg, nil
+}
+
+// Load reads and parses the configuration file at path. Review globs are
+// resolved relative to the directory of path.
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.dir = filepath.Dir(path)
+	if cfg.Manifest.Path != "" && !filepath.IsAbs(cfg.Manifest.Path) {
+		cfg.Manifest.Path = filepath.Join(cfg.dir, cfg.Manifest.Path)
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	if c.Version > 1 {
+		return fmt.Errorf("goquent: config version %d is not supported", c.Version)
+	}
+	if err := validateRisk("risk", c.Risk); err != nil {
+		return err
+	}
+	for name, risk := range c.Environments {
+		if err := validateRisk("environments."+name, risk); err != nil {
+			return err
+		}
+	}
+	if c.Environment != "" && len(c.Environments) > 0 {
+		if _, ok := c.Environments[c.Environment]; !ok {
+			return fmt.Errorf("goquent: config environment %q is not defined in environments", c.Environment)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Dialect)) {
+	case "", "mysql", "postgres":
+	default:
+		return fmt.Errorf("goquent: config dialect %q is not supported: use mysql or postgres", c.Dialect)
+	}
+	for i, policy := range c.Policies {
+		if strings.TrimSpace(policy.Table) == "" {
+			return fmt.Errorf("goquent: config policies[%d]: table is required", i)
+		}
+	}
+	for _, pattern := range append(append([]string(nil), c.Review.Include...), c.Review.Exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return fmt.Errorf("goquent: config review pattern %q: %w", pattern, err)
+		}
+	}
+	for i := range c.Suppressions {
+		s := &c.Suppressions[i]
+		s.Code = strings.TrimSpace(s.Code)
+		s.Reason = strings.TrimSpace(s.Reason)
+		if s.Code == "" || s.Reason == "" {
+			return fmt.Errorf("goquent: config suppressions[%d]: code and reason are required", i)
+		}
+		if strings.TrimSpace(s.Expires) == "" {
+			continue
+		}
+		t, err := parseExpiry(s.Expires)
+		if err != nil {
+			return fmt.Errorf("goquent: config suppressions[%d]: %w", i, err)
+		}
+		s.expiresAt = &t
+	}
+	return nil
+}
+
+func validateRisk(name string, risk Risk) error {
+	if risk.DeepOffset < 0 || risk.LargeInList < 0 {
+		return fmt.Errorf("goquent: config %s: thresholds must not be negative", name)
+	}
+	if risk.FailOn != "" && !validLevel(risk.FailOn) {
+		return fmt.Errorf("goquent: config %s.fail_on: unknown risk level %q", name, risk.FailOn)
+	}
+	for code, rule := range risk.Rules {
+		if rule.Severity != nil && !validLevel(*rule.Severity) {
+			return fmt.Errorf("goquent: config %s.rules.%s: unknown severity %q", name, code, *rule.Severity)
+		}
+	}
+	return nil
+}
+
+func validLevel(level query.RiskLevel) bool {
+	switch level {
+	case query.RiskLow, query.RiskMedium, query.RiskHigh, query.RiskDestructive, query.RiskBlocked:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseExpiry(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expires %q: use YYYY-MM-DD or RFC3339", value)
+	}
+	return t.UTC(), nil
+}
+
+func (c *Config) environment(env string) string {
+	if env == "" {
+		return c.Environment
+	}
+	return env
+}
+
+// RiskConfig returns the risk rules for env, or for c.Environment when env is
+// empty: the base rules with the environment's rules merged field by field.
+// Thresholds set by the environment replace the base thresholds.
+func (c *Config) RiskConfig(env string) query.RiskConfig {
+	env = c.environment(env)
+	rules := make(map[string]query.RiskRuleConfig, len(c.Risk.Rules))
+	for code, rule := range c.Risk.Rules {
+		rules[code] = rule
+	}
+	for code, override := range c.Environments[env].Rules {
+		rule := rules[code]
+		if override.Enabled != nil {
+			rule.Enabled = override.Enabled
+		}
+		if override.Severity != nil {
+			rule.Severity = override.Severity
+		}
+		if override.Suppressible != nil {
+			rule.Suppressible = override.Suppressible
+		}
+		if override.RequiresReason != nil {
+			rule.RequiresReason = override.RequiresReason
+		}
+		rules[code] = rule
+	}
+	deepOffset, largeInList := c.Risk.DeepOffset, c.Risk.LargeInList
+	if v := c.Environments[env].DeepOffset; v != 0 {
+		deepOffset = v
+	}
+	if v := c.Environments[env].LargeInList; v != 0 {
+		largeInList = v
+	}
+	return query.RiskConfig{Environment: env, Rules: rules, DeepOffset: deepOffset, LargeInList: largeInList}
+}
+
+// FailOn returns the review failure threshold for env, falling back to the
+// base threshold. It is empty when neither is set.
+func (c *Config) FailOn(env string) query.RiskLevel {
+	if level := c.Environments[c.environment(env)].FailOn; level != "" {
+		return level
+	}
+	return c.Risk.FailOn
+}
+
+// SQLDialect returns the configured dialect, MySQL when none is set.
+func (c *Config) SQLDialect() driver.Dialect {
+	if strings.EqualFold(strings.TrimSpace(c.Dialect), "postgres") {
+		return driver.PostgresDialect{}
+	}
+	return driver.MySQLDialect{}
+}
+
+// RegisterPolicies registers the configured table policies (see
+// query.RegisterTablePolicy).
+func (c *Config) RegisterPolicies() error {
+	for _, policy := range c.Policies {
+		if err := query.RegisterTablePolicy(policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SuppressionsFor returns the config-scope suppressions that apply to file.
+// With an empty file only suppressions without a Path are returned, as used
+// for runtime plans.
+func (c *Config) SuppressionsFor(file string) []query.Suppression {
+	var out []query.Suppression
+	for _, s := range c.Suppressions {
+		if s.Path != "" && (file == "" || !c.match(s.Path, file)) {
+			continue
+		}
+		out = append(out, query.Suppression{
+			Code:      s.Code,
+			Reason:    s.Reason,
+			Scope:     query.SuppressionScopeConfig,
+			ExpiresAt: s.expiresAt,
+			Owner:     s.Owner,
+		})
+	}
+	return out
+}
+
+// IncludesFile reports whether goquent review should read file according to
+// the review include and exclude patterns.
+func (c *Config) IncludesFile(file string) bool {
+	if len(c.Review.Include) > 0 && !c.matchAny(c.Review.Include, file) {
+		return false
+	}
+	return !c.matchAny(c.Review.Exclude, file)
+}
+
+func (c *Config) matchAny(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if c.match(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// match matches file, relative to the config directory, against pattern.
+func (c *Config) match(pattern, file string) bool {
+	rel := file
+	if base, err := filepath.Abs(c.dir); err == nil {
+		if abs, err := filepath.Abs(file); err == nil {
+			if r, err := filepath.Rel(base, abs); err == nil && !strings.HasPrefix(r, "..") {
+				rel = r
+			}
+		}
+	}
+	rel = strings.TrimPrefix(filepath.ToSlash(rel), "./")
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "./"), "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
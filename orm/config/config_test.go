@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+const testConfig = `
+version: 1
+environment: ci
+risk:
+  fail_on: high
+  deep_offset: 5000
+  rules:
+    LIMIT_MISSING:
+      severity: high
+      suppressible: false
+environments:
+  ci:
+    rules:
+      LIMIT_MISSING:
+        enabled: false
+  production:
+    fail_on: medium
+    large_in_list: 200
+    rules:
+      RAW_SQL_USED:
+        severity: blocked
+policies:
+  - table: users
+    tenant_column: tenant_id
+    pii_columns: [email]
+manifest:
+  path: .goquent/manifest.json
+  require_fresh: true
+review:
+  include: ["internal/**/*.go", "queries/*.sql"]
+  exclude: ["internal/generated/**"]
+suppressions:
+  - code: LIMIT_MISSING
+    path: internal/admin/**/*.go
+    reason: admin export is unbounded by design
+    owner: platform-team
+    expires: 2999-07-01
+  - code: SELECT_STAR_USED
+    reason: legacy reports
+`
+
+func TestLoadResolvesEnvironmentsAndPaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, DefaultFile)
+	if err := os.WriteFile(path, []byte(testConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ci := cfg.RiskConfig("")
+	rule := ci.Rules[query.WarningLimitMissing]
+	if ci.Environment != "ci" || rule.Enabled == nil || *rule.Enabled || rule.Severity == nil || *rule.Severity != query.RiskHigh || rule.Suppressible == nil || *rule.Suppressible {
+		t.Fatalf("unexpected merged ci rule: %+v", rule)
+	}
+	prod := cfg.RiskConfig("production")
+	if sev := prod.Rules[query.WarningRawSQLUsed].Severity; sev == nil || *sev != query.RiskBlocked {
+		t.Fatalf("unexpected production rules: %+v", prod.Rules)
+	}
+	if _, ok := prod.Rules[query.WarningLimitMissing]; !ok || prod.Rules[query.WarningLimitMissing].Enabled != nil {
+		t.Fatalf("production must only inherit base rules: %+v", prod.Rules)
+	}
+	if ci.DeepOffset != 5000 || ci.LargeInList != 0 || prod.DeepOffset != 5000 || prod.LargeInList != 200 {
+		t.Fatalf("unexpected thresholds ci=%+v production=%+v", ci, prod)
+	}
+	if cfg.FailOn("") != query.RiskHigh || cfg.FailOn("production") != query.RiskMedium {
+		t.Fatalf("fail_on ci=%s production=%s", cfg.FailOn(""), cfg.FailOn("production"))
+	}
+	if cfg.Manifest.Path != filepath.Join(dir, ".goquent", "manifest.json") || !cfg.Manifest.RequireFresh {
+		t.Fatalf("unexpected manifest %+v", cfg.Manifest)
+	}
+
+	for file, want := range map[string]bool{
+		filepath.Join(dir, "internal", "users", "repo.go"):       true,
+		filepath.Join(dir, "internal", "generated", "models.go"): false,
+		filepath.Join(dir, "queries", "users.sql"):               true,
+		filepath.Join(dir, "queries", "nested", "users.sql"):     false,
+		filepath.Join(dir, "cmd", "main.go"):                     false,
+	} {
+		if got := cfg.IncludesFile(file); got != want {
+			t.Errorf("IncludesFile(%s) = %v, want %v", file, got, want)
+		}
+	}
+
+	admin := cfg.SuppressionsFor(filepath.Join(dir, "internal", "admin", "export", "handler.go"))
+	if len(admin) != 2 || admin[0].Scope != query.SuppressionScopeConfig || admin[0].Owner != "platform-team" || admin[0].ExpiresAt == nil || admin[0].ExpiresAt.Year() != 2999 {
+		t.Fatalf("unexpected admin suppressions: %+v", admin)
+	}
+	if runtime := cfg.SuppressionsFor(""); len(runtime) != 1 || runtime[0].Code != query.WarningSelectStarUsed {
+		t.Fatalf("unexpected runtime suppressions: %+v", runtime)
+	}
+}
+
+func TestParseAcceptsJSONAndRejectsInvalidConfig(t *testing.T) {
+	cfg, err := Parse([]byte(`{"risk":{"fail_on":"medium"},"policies":[{"table":"orders","required_filter_columns":["status"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.FailOn("") != query.RiskMedium || cfg.Policies[0].RequiredFilterColumns[0] != "status" {
+		t.Fatalf("unexpected JSON config: %+v", cfg)
+	}
+
+	for name, body := range map[string]string{
+		"unknown field":      "risk:\n  failon: high\n",
+		"severity":           "risk:\n  rules:\n    LIMIT_MISSING:\n      severity: severe\n",
+		"suppression reason": "suppressions:\n  - code: LIMIT_MISSING\n",
+		"expires":            "suppressions:\n  - code: LIMIT_MISSING\n    reason: r\n    expires: soon\n",
+		"environment":        "environment: staging\nenvironments:\n  production: {}\n",
+		"version":            "version: 2\n",
+		"threshold":          "risk:\n  deep_offset: -1\n",
+		"dialect":            "dialect: oracle\n",
+	} {
+		if _, err := Parse([]byte(body)); err == nil || !strings.HasPrefix(err.Error(), "goquent: ") {
+			t.Errorf("%s: expected goquent error, got %v", name, err)
+		}
+	}
+}
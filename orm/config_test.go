@@ -0,0 +1,166 @@
+package orm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+func TestLoadConfigAppliesRiskRulesAndSuppressions(t *testing.T) {
+	query.ResetPolicyRegistry()
+	t.Cleanup(query.ResetPolicyRegistry)
+	path := filepath.Join(t.TempDir(), DefaultConfigFile)
+	if err := os.WriteFile(path, []byte(`
+environment: development
+risk:
+  rules:
+    LIMIT_MISSING:
+      severity: high
+environments:
+  development: {}
+  production:
+    rules:
+      RAW_SQL_USED:
+        severity: blocked
+policies:
+  - table: accounts
+    tenant_column: tenant_id
+suppressions:
+  - code: SELECT_STAR_USED
+    reason: legacy reports select every column
+    owner: reporting
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path, "staging"); err == nil {
+		t.Fatal("expected undefined environment to fail")
+	}
+	missing := filepath.Join(filepath.Dir(path), "fresh.yaml")
+	if err := os.WriteFile(missing, []byte("manifest:\n  path: missing.json\n  require_fresh: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(missing, ""); err == nil {
+		t.Fatal("expected a missing manifest to fail when require_fresh is set")
+	}
+	opt, err := LoadConfig(path, "production")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, _ := newSQLMockDB(t, driver.MySQLDialect{}, opt)
+	ctx := context.Background()
+
+	plan, err := db.Table("users").Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := map[string]query.RiskLevel{}
+	for _, w := range plan.Warnings {
+		codes[w.Code] = w.Level
+	}
+	if codes[query.WarningLimitMissing] != query.RiskHigh || !plan.RequiredApproval {
+		t.Fatalf("expected LIMIT_MISSING re-leveled to high: %#v", plan.Warnings)
+	}
+	if _, ok := codes[query.WarningSelectStarUsed]; ok || len(plan.SuppressedWarnings) != 1 {
+		t.Fatalf("expected config suppression of SELECT *: %#v", plan)
+	}
+
+	raw, err := db.NamedPlan(ctx, "SELECT id FROM users WHERE id = :id", map[string]any{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw.RiskLevel != query.RiskBlocked || !raw.Blocked {
+		t.Fatalf("expected production to block raw SQL: %#v", raw.Warnings)
+	}
+	if _, err := db.RequireRawApproval("reviewed").ExecContext(ctx, "DELETE FROM sessions WHERE id = ?", 1); err == nil {
+		t.Fatal("expected blocked raw SQL to be refused")
+	}
+
+	tenant, err := db.Table("accounts").Select("id").Limit(1).Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, w := range tenant.Warnings {
+		found = found || w.Code == query.WarningTenantFilterMissing
+	}
+	if !found {
+		t.Fatalf("expected configured tenant policy to apply: %#v", tenant.Warnings)
+	}
+}
+
+func TestWithConfigRegistersPoliciesWhenTheDBIsCreated(t *testing.T) {
+	query.ResetPolicyRegistry()
+	t.Cleanup(query.ResetPolicyRegistry)
+	opt := WithConfig(&Config{Policies: []query.TablePolicy{{Table: "accounts", TenantColumn: "tenant_id"}}}, "")
+	if _, ok := query.PolicyForTable("accounts"); ok {
+		t.Fatal("expected policies to be registered by NewDB, not by WithConfig")
+	}
+	newSQLMockDB(t, driver.MySQLDialect{}, opt)
+	if _, ok := query.PolicyForTable("accounts"); !ok {
+		t.Fatal("expected NewDB to register the configured policy")
+	}
+
+	db, _ := newSQLMockDB(t, driver.MySQLDialect{}, WithConfig(&Config{Policies: []query.TablePolicy{{TenantColumn: "tenant_id"}}}, ""))
+	var rows []map[string]any
+	if err := db.Table("users").Select("id").Limit(1).GetMaps(&rows); err == nil || !strings.Contains(err.Error(), "policy table is required") {
+		t.Fatalf("expected executions to fail with the registration error, got %v", err)
+	}
+	if _, err := db.RequireRawApproval("reviewed").ExecContext(context.Background(), "DELETE FROM sessions WHERE id = ?", 1); err == nil || !strings.Contains(err.Error(), "policy table is required") {
+		t.Fatalf("expected raw executions to fail with the registration error, got %v", err)
+	}
+}
+
+func TestLoadConfigUsesManifestIndexes(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manifest{Version: ManifestVersion, Tables: []ManifestTable{{
+		Name:    "users",
+		Columns: []ManifestColumn{{Name: "id", Primary: true}, {Name: "email"}, {Name: "name"}},
+		Indexes: []ManifestIndex{{Name: "users_email_idx", Columns: []string{"email"}, Unique: true}},
+	}}}
+	b, err := m.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "goquent.manifest.json"), b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, DefaultConfigFile)
+	if err := os.WriteFile(path, []byte("manifest:\n  path: goquent.manifest.json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opt, err := LoadConfig(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, _ := newSQLMockDB(t, driver.MySQLDialect{}, opt)
+	ctx := context.Background()
+
+	plan, err := db.Table("users").Select("id").Where("name", "alice").Limit(1).Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasWarningCode(plan.Warnings, query.WarningPredicateNotIndexed) {
+		t.Fatalf("expected unindexed predicate warning: %#v", plan.Warnings)
+	}
+	update, err := db.Table("users").Where("email", "a@example.com").PlanUpdate(ctx, map[string]any{"name": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasWarningCode(update.Warnings, query.WarningBulkUpdateDetected) {
+		t.Fatalf("unique index update should not be bulk: %#v", update.Warnings)
+	}
+}
+
+func hasWarningCode(warnings []query.Warning, code string) bool {
+	for _, w := range warnings {
+		if w.Code == code {
+			return true
+		}
+	}
+	return false
+}
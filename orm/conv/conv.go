@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/faciam-dev/goquent/orm/internal/codec"
 	"github.com/faciam-dev/goquent/orm/internal/stringutil"
 )
 
@@ -14,8 +15,20 @@ func As[T any](v any) (T, error) {
 	if v == nil {
 		return zero, fmt.Errorf("value is nil")
 	}
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
 	rv := reflect.ValueOf(v)
-	rt := reflect.TypeOf(zero)
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	if c, err := codec.Lookup(rt, "", nil); err == nil && c != nil {
+		out := reflect.New(rt).Elem()
+		if handled, err := codec.DecodeInto(c, out, v); handled {
+			if err != nil {
+				return zero, err
+			}
+			return out.Interface().(T), nil
+		}
+	}
 	if !rv.Type().ConvertibleTo(rt) {
 		return zero, fmt.Errorf("cannot convert %T to %T", v, zero)
 	}
@@ -49,7 +62,8 @@ func MapToStruct(m map[string]any, dest any) error {
 	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
 		sf := t.Field(i)
-		col := sf.Tag.Get("db")
+		dbTag := sf.Tag.Get("db")
+		col, _ := splitTag(dbTag)
 		if col == "" || col == "-" {
 			col = parseTag(sf.Tag.Get("orm"))
 		}
@@ -59,7 +73,15 @@ func MapToStruct(m map[string]any, dest any) error {
 		if val, ok := findValue(m, col); ok && val != nil {
 			fv := reflect.ValueOf(val)
 			f := v.Field(i)
-			if fv.Type().ConvertibleTo(f.Type()) {
+			c, err := codec.Lookup(f.Type(), codec.TagName(dbTag), nil)
+			if err != nil {
+				return err
+			}
+			if handled, err := codec.DecodeInto(c, f, val); handled {
+				if err != nil {
+					return fmt.Errorf("decode field %s: %w", sf.Name, err)
+				}
+			} else if fv.Type().ConvertibleTo(f.Type()) {
 				f.Set(fv.Convert(f.Type()))
 			} else {
 				return fmt.Errorf("cannot convert %s to field %s", fv.Type(), sf.Name)
@@ -0,0 +1,231 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/faciam-dev/goquent/orm/migration"
+	"github.com/faciam-dev/goquent/orm/model"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// MigrationLineMetadataKey is the QueryPlan.Metadata key holding the source
+// line of a migration statement passed to interceptors.
+const MigrationLineMetadataKey = "migration_line"
+
+// WithInterceptors wraps every execution with interceptors: builder queries
+// from Model/Table, raw SQL, named and catalog queries, generic CRUD helpers
+// and migrations applied with Migrator.Apply. The first interceptor is the
+// outermost. Interceptors run after approval and block checks; returning an
+// error without calling next prevents the SQL from being sent.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(db *DB) {
+		db.interceptors = append(db.interceptors[:len(db.interceptors):len(db.interceptors)], interceptors...)
+	}
+}
+
+// handlerContext returns the context the terminal handler passes to the
+// executor: nil when the caller passed none and no interceptor replaced the
+// background context, so the executor's context-free methods run as before.
+func handlerContext(caller, ctx context.Context) context.Context {
+	if caller == nil && ctx == context.Background() {
+		return nil
+	}
+	return ctx
+}
+
+// spanContext returns ctx, or the transaction span context of a
+// transaction-scoped DB when the caller passed no context.
+func (db *DB) spanContext(ctx context.Context) context.Context {
+	if ctx == nil && db.txCtx != nil {
+		return db.txCtx
+	}
+	return ctx
+}
+
+// execPlan runs plan through the interceptors. Callers check executability.
+func (db *DB) execPlan(ctx context.Context, plan *query.QueryPlan) (sql.Result, error) {
+	var res sql.Result
+	ctx = db.spanContext(ctx)
+	_, err := query.Intercept(ctx, plan, db.interceptors, func(hctx context.Context, plan *query.QueryPlan) (ExecResult, error) {
+		var err error
+		res, err = db.execContextTrusted(handlerContext(ctx, hctx), db.executedSQL(hctx, plan), plan.Params...)
+		if err != nil {
+			return ExecResult{Rows: -1}, err
+		}
+		return ExecResult{Rows: query.ResultRows(res)}, nil
+	})
+	return res, err
+}
+
+// scanPlan runs plan through the interceptors and passes the rows to scan,
+// which returns the number of rows it read.
+func (db *DB) scanPlan(ctx context.Context, plan *query.QueryPlan, scan func(*sql.Rows) (int64, error)) error {
+	ctx = db.spanContext(ctx)
+	_, err := query.Intercept(ctx, plan, db.interceptors, func(hctx context.Context, plan *query.QueryPlan) (ExecResult, error) {
+		rows, err := db.queryContextTrusted(handlerContext(ctx, hctx), db.executedSQL(hctx, plan), plan.Params...)
+		if err != nil {
+			return ExecResult{Rows: -1}, err
+		}
+		defer rows.Close()
+		n, err := scan(rows)
+		if err != nil {
+			return ExecResult{Rows: -1}, err
+		}
+		return ExecResult{Rows: n}, nil
+	})
+	return err
+}
+
+// queryPlan runs plan through the interceptors and returns the unread rows,
+// so interceptors see Rows as -1.
+func (db *DB) queryPlan(ctx context.Context, plan *query.QueryPlan) (*sql.Rows, error) {
+	var rows *sql.Rows
+	ctx = db.spanContext(ctx)
+	_, err := query.Intercept(ctx, plan, db.interceptors, func(hctx context.Context, plan *query.QueryPlan) (ExecResult, error) {
+		var err error
+		rows, err = db.queryContextTrusted(handlerContext(ctx, hctx), db.executedSQL(hctx, plan), plan.Params...)
+		return ExecResult{Rows: -1}, err
+	})
+	if err != nil {
+		if rows != nil {
+			rows.Close()
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
+// queryRowPlan runs plan through the interceptors and returns the unread row.
+// Query errors stay on the row for Scan, as with sql.DB.QueryRowContext.
+func (db *DB) queryRowPlan(ctx context.Context, plan *query.QueryPlan) (*sql.Row, error) {
+	var row *sql.Row
+	ctx = db.spanContext(ctx)
+	_, err := query.Intercept(ctx, plan, db.interceptors, func(hctx context.Context, plan *query.QueryPlan) (ExecResult, error) {
+		sqlStr := db.executedSQL(hctx, plan)
+		if hctx = handlerContext(ctx, hctx); hctx == nil {
+			row = db.exec.QueryRow(sqlStr, plan.Params...)
+		} else {
+			row = db.exec.QueryRowContext(hctx, sqlStr, plan.Params...)
+		}
+		if row == nil {
+			return ExecResult{Rows: -1}, nil
+		}
+		return ExecResult{Rows: -1}, row.Err()
+	})
+	if err != nil && (row == nil || err != row.Err()) {
+		return nil, err
+	}
+	return row, nil
+}
+
+func selectOnePlan[T any](ctx context.Context, db *DB, plan *query.QueryPlan) (T, error) {
+	var out T
+	err := db.scanPlan(ctx, plan, func(rows *sql.Rows) (int64, error) {
+		v, err := scanRowsOne[T](db, rows)
+		if err != nil {
+			return 0, err
+		}
+		out = v
+		return 1, nil
+	})
+	return out, err
+}
+
+func selectAllPlan[T any](ctx context.Context, db *DB, plan *query.QueryPlan) ([]T, error) {
+	var out []T
+	err := db.scanPlan(ctx, plan, func(rows *sql.Rows) (int64, error) {
+		v, err := scanRowsAll[T](db, rows)
+		if err != nil {
+			return 0, err
+		}
+		out = v
+		return int64(len(v)), nil
+	})
+	return out, err
+}
+
+// writePlan describes SQL built by the generic CRUD helpers. The statements
+// are generated from the model, so they are not risk-checked; updates and
+// upserts are only checked against the table's write policy.
+func writePlan(op query.OperationType, table, sqlStr string, args []any) *query.QueryPlan {
+	plan := &query.QueryPlan{
+		Operation:         op,
+		SQL:               sqlStr,
+		Params:            append([]any(nil), args...),
+		RiskLevel:         query.RiskLow,
+		AnalysisPrecision: query.AnalysisPrecise,
+	}
+	if table != "" {
+		plan.Tables = []query.TableRef{{Name: table}}
+	}
+	plan.Fingerprint = query.PlanFingerprint(plan)
+	return plan
+}
+
+func writeTableName(v any, o *writeOptions) string {
+	if o.table != "" {
+		return o.table
+	}
+	if v == nil || isMapStringInterface(reflect.TypeOf(v)) {
+		return ""
+	}
+	return model.TableName(v)
+}
+
+// ExecMigrationStatement executes one statement of an executable migration
+// plan through the interceptors. Migrator.Apply uses it instead of
+// ExecContext, so migrations are gated by their migration plan approval
+// rather than by raw SQL approval. The statement must be one of the plan's
+// statements.
+func (db *DB) ExecMigrationStatement(ctx context.Context, plan *MigrationPlan, statement MigrationStatement) (sql.Result, error) {
+	if err := migration.EnsureExecutable(plan, db.trustedKeys...); err != nil {
+		return nil, err
+	}
+	if !migration.HasStatement(plan, statement) {
+		return nil, fmt.Errorf("goquent: statement at line %d is not part of the migration plan", statement.Line)
+	}
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	qp := &query.QueryPlan{
+		Operation:         query.OperationMigration,
+		SQL:               statement.SQL,
+		RiskLevel:         plan.RiskLevel,
+		AnalysisPrecision: plan.AnalysisPrecision,
+		RequiredApproval:  plan.RequiredApproval,
+		Approval:          plan.Approval,
+		Metadata:          map[string]any{MigrationLineMetadataKey: statement.Line},
+	}
+	for _, step := range plan.Steps {
+		if step.Line != statement.Line {
+			continue
+		}
+		qp.RiskLevel = step.RiskLevel
+		qp.AnalysisPrecision = step.AnalysisPrecision
+		qp.Warnings = step.Warnings
+		if step.Table != "" {
+			qp.Tables = []query.TableRef{{Name: step.Table}}
+		}
+		break
+	}
+	qp.Fingerprint = query.PlanFingerprint(qp)
+	verifiedMigrations.Store(qp, statement.SQL)
+	defer verifiedMigrations.Delete(qp)
+	return db.execPlan(ctx, qp)
+}
+
+// verifiedMigrations holds the plans ExecMigrationStatement is running, with
+// the statement SQL checked against their migration plan, so the allowlist
+// exempts only those.
+var verifiedMigrations sync.Map
+
+func isVerifiedMigration(plan *QueryPlan) bool {
+	sqlStr, ok := verifiedMigrations.Load(plan)
+	return ok && plan.Operation == OperationMigration && sqlStr == plan.SQL
+}
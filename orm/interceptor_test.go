@@ -0,0 +1,180 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/migration"
+)
+
+type interceptedCall struct {
+	op   OperationType
+	sql  string
+	rows int64
+	err  error
+}
+
+func recordingInterceptor(calls *[]interceptedCall) Interceptor {
+	return func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+		res, err := next(ctx, plan)
+		*calls = append(*calls, interceptedCall{op: plan.Operation, sql: plan.SQL, rows: res.Rows, err: err})
+		return res, err
+	}
+}
+
+func TestInterceptorsRunInOrderAroundBuilderQueries(t *testing.T) {
+	var order []string
+	named := func(name string) Interceptor {
+		return func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+			order = append(order, name+">")
+			res, err := next(ctx, plan)
+			order = append(order, "<"+name)
+			return res, err
+		}
+	}
+	var calls []interceptedCall
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithInterceptors(named("outer"), named("inner"), recordingInterceptor(&calls)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `name` FROM `users` WHERE `active` = ? LIMIT 10")).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "alice").AddRow(int64(2), "bob"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `name` = ? WHERE `id` = ?")).
+		WithArgs("carol", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	var users []map[string]any
+	if err := db.Table("users").Select("id", "name").Where("active", true).Limit(10).GetMaps(&users); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if _, err := db.Table("users").Where("id", 1).Update(map[string]any{"name": "carol"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	want := []string{"outer>", "inner>", "<inner", "<outer", "outer>", "inner>", "<inner", "<outer"}
+	if len(order) != len(want) {
+		t.Fatalf("unexpected order %v", order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("unexpected order %v", order)
+		}
+	}
+	if len(calls) != 2 || calls[0].op != OperationSelect || calls[0].rows != 2 || calls[1].op != OperationUpdate || calls[1].rows != 1 {
+		t.Fatalf("unexpected calls %#v", calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInterceptorVetoPreventsExecution(t *testing.T) {
+	veto := errors.New("vetoed")
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithInterceptors(func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+		if plan.Operation == OperationDelete {
+			return ExecResult{}, veto
+		}
+		return next(ctx, plan)
+	}))
+
+	if _, err := db.Table("users").Where("id", 1).Delete(); !errors.Is(err, veto) {
+		t.Fatalf("expected veto, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInterceptorWithoutNextReportsNotExecuted(t *testing.T) {
+	db, _ := newSQLMockDB(t, driver.MySQLDialect{}, WithInterceptors(func(context.Context, *QueryPlan, Handler) (ExecResult, error) {
+		return ExecResult{}, nil
+	}))
+
+	if _, err := db.RequireRawApproval("test").ExecContext(context.Background(), "DELETE FROM sessions"); !errors.Is(err, ErrNotExecuted) {
+		t.Fatalf("expected ErrNotExecuted, got %v", err)
+	}
+}
+
+func TestInterceptorsSeeRawAndGenericWritePlans(t *testing.T) {
+	var calls []interceptedCall
+	tag := func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+		if plan.Metadata == nil {
+			plan.Metadata = map[string]any{}
+		}
+		plan.Metadata["request_id"] = "r-1"
+		return next(ctx, plan)
+	}
+	var tagged int
+	check := func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+		if plan.Metadata["request_id"] == "r-1" {
+			tagged++
+		}
+		return next(ctx, plan)
+	}
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithInterceptors(tag, check, recordingInterceptor(&calls)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM users WHERE id = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`name`) VALUES (?)")).
+		WithArgs("bob").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	ctx := context.Background()
+	if _, err := SelectAll[map[string]any](ctx, db.RequireRawApproval("reviewed lookup"), "SELECT id FROM users WHERE id = ?", 1); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if _, err := Insert(ctx, db, map[string]any{"name": "bob"}, Table("users")); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	if tagged != 2 {
+		t.Fatalf("expected metadata on both plans, got %d", tagged)
+	}
+	if len(calls) != 2 || calls[0].op != OperationRaw || calls[0].rows != 1 || calls[1].op != OperationInsert || calls[1].rows != 1 {
+		t.Fatalf("unexpected calls %#v", calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInterceptorsSeeMigrationStatements(t *testing.T) {
+	var calls []interceptedCall
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithInterceptors(recordingInterceptor(&calls)))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE audit_logs (id BIGINT PRIMARY KEY)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := migration.New("CREATE TABLE audit_logs (id BIGINT PRIMARY KEY);").Apply(context.Background(), db); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(calls) != 1 || calls[0].op != OperationMigration {
+		t.Fatalf("unexpected calls %#v", calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecMigrationStatementRequiresAPlannedStatement(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{})
+	ctx := context.Background()
+
+	if _, err := db.ExecMigrationStatement(ctx, nil, MigrationStatement{SQL: "DELETE FROM users"}); err == nil {
+		t.Fatalf("expected a nil plan to be rejected")
+	}
+	if _, err := db.ExecMigrationStatement(ctx, &MigrationPlan{}, MigrationStatement{SQL: "DELETE FROM users"}); err == nil {
+		t.Fatalf("expected an empty plan to be rejected")
+	}
+	plan, err := migration.New("CREATE TABLE audit_logs (id BIGINT PRIMARY KEY);").Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecMigrationStatement(ctx, plan, MigrationStatement{SQL: "DELETE FROM users", Line: 1}); err == nil {
+		t.Fatalf("expected a statement outside the plan to be rejected")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,247 @@
+// Package codec holds the process-wide registry of value codecs used to
+// convert between Go types and database values without sql.Scanner or
+// driver.Valuer implementations on the Go type itself.
+package codec
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/faciam-dev/goquent/orm/internal/stringutil"
+)
+
+// Codec converts values of Type to and from database values. Either function
+// may be nil, in which case that direction uses the default conversion.
+type Codec struct {
+	Type   reflect.Type
+	Encode func(v any) (any, error)
+	Decode func(src any) (any, error)
+}
+
+type registration struct {
+	base     *Codec
+	dialects map[reflect.Type]*Codec
+}
+
+var (
+	mu     sync.RWMutex
+	byType = map[any]*registration{}
+	byName = map[any]*registration{}
+)
+
+// Register installs c for c.Type, or only for the column tag name when name is
+// non-empty. A non-nil dialect restricts c to that dialect type and takes
+// precedence over the dialect-independent registration.
+func Register(name string, dialect any, c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+	regs, key := byType, any(c.Type)
+	if name != "" {
+		regs, key = byName, name
+	}
+	r := regs[key]
+	if r == nil {
+		r = &registration{}
+		regs[key] = r
+	}
+	if dialect == nil {
+		r.base = &c
+		return
+	}
+	if r.dialects == nil {
+		r.dialects = make(map[reflect.Type]*Codec)
+	}
+	r.dialects[reflect.TypeOf(dialect)] = &c
+}
+
+// Reset removes every registered codec. Intended for tests.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	byType = map[any]*registration{}
+	byName = map[any]*registration{}
+}
+
+// Lookup returns the codec registered under name, or for t (or the element
+// type of pointer t) when name is empty. An error is returned only for an
+// unknown name.
+func Lookup(t reflect.Type, name string, dialect any) (*Codec, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	var r *registration
+	if name != "" {
+		if r = byName[name]; r == nil {
+			return nil, fmt.Errorf("goquent: codec %q is not registered", name)
+		}
+	} else if t != nil {
+		if r = byType[t]; r == nil && t.Kind() == reflect.Pointer {
+			r = byType[t.Elem()]
+		}
+	}
+	if r == nil {
+		return nil, nil
+	}
+	if dialect != nil {
+		if c, ok := r.dialects[reflect.TypeOf(dialect)]; ok {
+			return c, nil
+		}
+	}
+	if r.base == nil && name != "" {
+		return nil, fmt.Errorf("goquent: codec %q is not registered for %T", name, dialect)
+	}
+	return r.base, nil
+}
+
+// TagName returns the codec name from a db tag such as "amount,codec=decimal".
+func TagName(tag string) string {
+	for _, part := range strings.Split(tag, ",")[1:] {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(part), "codec="); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// DecodeInto decodes src with c and stores the result in dst. dst may be the
+// codec type or a pointer to it; NULL leaves dst at its zero value. handled
+// is false when c has no decoder.
+func DecodeInto(c *Codec, dst reflect.Value, src any) (handled bool, err error) {
+	if c == nil || c.Decode == nil {
+		return false, nil
+	}
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return true, nil
+	}
+	out, err := c.Decode(src)
+	if err != nil {
+		return true, err
+	}
+	target := dst
+	viaPointer := dst.Kind() == reflect.Pointer && dst.Type().Elem() == c.Type
+	if viaPointer {
+		target = reflect.New(c.Type).Elem()
+	}
+	rv := reflect.ValueOf(out)
+	switch {
+	case !rv.IsValid():
+		target.Set(reflect.Zero(target.Type()))
+	case rv.Type().AssignableTo(target.Type()):
+		target.Set(rv)
+	case rv.Type().ConvertibleTo(target.Type()):
+		target.Set(rv.Convert(target.Type()))
+	default:
+		return true, fmt.Errorf("codec decoded %s, expected %s", rv.Type(), target.Type())
+	}
+	if viaPointer {
+		dst.Set(target.Addr())
+	}
+	return true, nil
+}
+
+// Arg defers encoding of V to bind time. It implements driver.Valuer so
+// database/sql reports encode and lookup errors from Exec and Query.
+type Arg struct {
+	V     any
+	Codec *Codec
+	Err   error
+}
+
+// Value implements driver.Valuer.
+func (a Arg) Value() (driver.Value, error) {
+	if a.Err != nil {
+		return nil, a.Err
+	}
+	rv := reflect.ValueOf(a.V)
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if rv.Kind() == reflect.Pointer && rv.Type().Elem() == a.Codec.Type {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	return a.Codec.Encode(rv.Interface())
+}
+
+// MarshalJSON renders the unencoded value so plans stay readable.
+func (a Arg) MarshalJSON() ([]byte, error) { return json.Marshal(a.V) }
+
+// String renders the unencoded value.
+func (a Arg) String() string { return fmt.Sprint(a.V) }
+
+// Wrap returns v wrapped in an Arg when a codec with an encoder applies to it
+// (by name, or by the dynamic type of v); otherwise v is returned unchanged.
+// An unknown name yields an Arg that fails when bound.
+func Wrap(v any, name string, dialect any) any {
+	if _, ok := v.(Arg); ok || v == nil {
+		return v
+	}
+	c, err := Lookup(reflect.TypeOf(v), name, dialect)
+	if err != nil {
+		return Arg{V: v, Err: err}
+	}
+	if c == nil || c.Encode == nil {
+		return v
+	}
+	return Arg{V: v, Codec: c}
+}
+
+// WrapArgs applies Wrap by type to every argument, copying args only when a
+// codec applies.
+func WrapArgs(args []any, dialect any) []any {
+	mu.RLock()
+	empty := len(byType) == 0
+	mu.RUnlock()
+	if empty {
+		return args
+	}
+	var out []any
+	for i, arg := range args {
+		wrapped := Wrap(arg, "", dialect)
+		if _, ok := wrapped.(Arg); !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]any(nil), args...)
+		}
+		out[i] = wrapped
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
+
+// WrapTagged wraps the values in m that belong to struct fields of v tagged
+// with a codec name (db:"column,codec=name"). m is keyed by column name.
+func WrapTagged(m map[string]any, v any, dialect any) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("db")
+		name := TagName(tag)
+		if name == "" || sf.PkgPath != "" {
+			continue
+		}
+		col, _, _ := strings.Cut(tag, ",")
+		if col == "" {
+			col = stringutil.ToSnake(sf.Name)
+		}
+		if val, ok := m[col]; ok {
+			m[col] = Wrap(val, name, dialect)
+		}
+	}
+}
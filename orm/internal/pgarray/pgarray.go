@@ -0,0 +1,175 @@
+// Package pgarray converts between Go slices and one-dimensional PostgreSQL
+// array literals such as {a,"b c"} and {1,2,3}.
+package pgarray
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Supported reports whether t is a slice of string or int64 kinds.
+func Supported(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Slice {
+		return false
+	}
+	switch t.Elem().Kind() {
+	case reflect.String, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// Value returns the array literal for []string or []int64 values (including
+// named slice and element types). ok is false for any other value.
+func Value(v any) (string, bool) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !Supported(rv.Type()) || rv.IsNil() {
+		return "", false
+	}
+	if rv.Type().Elem().Kind() == reflect.String {
+		vals := make([]string, rv.Len())
+		for i := range vals {
+			vals[i] = rv.Index(i).String()
+		}
+		return FormatStrings(vals), true
+	}
+	vals := make([]int64, rv.Len())
+	for i := range vals {
+		vals[i] = rv.Index(i).Int()
+	}
+	return FormatInt64s(vals), true
+}
+
+// FormatStrings renders vals as a quoted text array literal.
+func FormatStrings(vals []string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, v := range vals {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('"')
+		for j := 0; j < len(v); j++ {
+			if v[j] == '"' || v[j] == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(v[j])
+		}
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// FormatInt64s renders vals as an integer array literal.
+func FormatInt64s(vals []int64) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, v := range vals {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatInt(v, 10))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Parse splits a one-dimensional array literal into its elements. NULL
+// elements are rejected because they cannot be represented in []string or
+// []int64.
+func Parse(src string) ([]string, error) {
+	s := strings.TrimSpace(src)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("pgarray: invalid array literal %q", src)
+	}
+	s = s[1 : len(s)-1]
+	out := []string{}
+	if s == "" {
+		return out, nil
+	}
+	for i := 0; i <= len(s); {
+		if i < len(s) && s[i] == '{' {
+			return nil, fmt.Errorf("pgarray: multi-dimensional arrays are not supported")
+		}
+		var elem strings.Builder
+		quoted := i < len(s) && s[i] == '"'
+		if quoted {
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				elem.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("pgarray: unterminated quoted element in %q", src)
+			}
+			i++
+		} else {
+			for ; i < len(s) && s[i] != ','; i++ {
+				elem.WriteByte(s[i])
+			}
+		}
+		value := elem.String()
+		if !quoted {
+			value = strings.TrimSpace(value)
+			if strings.EqualFold(value, "NULL") {
+				return nil, fmt.Errorf("pgarray: NULL elements are not supported")
+			}
+		}
+		out = append(out, value)
+		if i >= len(s) {
+			break
+		}
+		if s[i] != ',' {
+			return nil, fmt.Errorf("pgarray: invalid array literal %q", src)
+		}
+		i++
+	}
+	return out, nil
+}
+
+// Scan parses src into dst when dst is a supported slice and src is an array
+// literal. handled is false when dst or src is not an array shape.
+func Scan(dst reflect.Value, src any) (handled bool, err error) {
+	if !Supported(dst.Type()) {
+		return false, nil
+	}
+	var text string
+	switch v := src.(type) {
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case nil:
+		dst.Set(reflect.Zero(dst.Type()))
+		return true, nil
+	default:
+		return false, nil
+	}
+	if !strings.HasPrefix(strings.TrimSpace(text), "{") {
+		return false, nil
+	}
+	elems, err := Parse(text)
+	if err != nil {
+		return true, err
+	}
+	out := reflect.MakeSlice(dst.Type(), len(elems), len(elems))
+	for i, elem := range elems {
+		if dst.Type().Elem().Kind() == reflect.String {
+			out.Index(i).SetString(elem)
+			continue
+		}
+		n, err := strconv.ParseInt(elem, 10, 64)
+		if err != nil {
+			return true, fmt.Errorf("pgarray: invalid integer element %q", elem)
+		}
+		out.Index(i).SetInt(n)
+	}
+	dst.Set(out)
+	return true, nil
+}
@@ -0,0 +1,99 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// LogOption configures WithLogger.
+type LogOption func(*queryLogger)
+
+// LogSlowThreshold logs executions taking longer than d at warn level.
+func LogSlowThreshold(d time.Duration) LogOption {
+	return func(l *queryLogger) { l.slow = d }
+}
+
+// LogRawParams logs the parameters of raw SQL and migration statements,
+// which are redacted by default because their columns are unknown.
+func LogRawParams() LogOption {
+	return func(l *queryLogger) { l.rawParams = true }
+}
+
+// WithLogger logs every execution to logger (slog.Default() when nil) with
+// its operation, SQL, duration, rows, risk level, actor, warning codes and
+// parameters. Parameters that may be bound to TablePolicy.PIIColumns are
+// redacted; see query.RedactParams. Executions log at info level, slow ones
+// at warn and failed ones at error. The logger is added as an interceptor
+// after those already configured.
+func WithLogger(logger *slog.Logger, opts ...LogOption) Option {
+	return WithInterceptors(NewLogInterceptor(logger, opts...))
+}
+
+// NewLogInterceptor returns the interceptor installed by WithLogger, for use
+// with Query.WithInterceptors or to control its position in the chain.
+func NewLogInterceptor(logger *slog.Logger, opts ...LogOption) Interceptor {
+	l := &queryLogger{logger: logger}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l.intercept
+}
+
+type queryLogger struct {
+	logger    *slog.Logger
+	slow      time.Duration
+	rawParams bool
+}
+
+func (l *queryLogger) intercept(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+	start := time.Now()
+	res, err := next(ctx, plan)
+	elapsed := time.Since(start)
+
+	logger := l.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	level, msg := slog.LevelInfo, "goquent query"
+	switch {
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		level, msg = slog.LevelError, "goquent query failed"
+	case l.slow > 0 && elapsed > l.slow:
+		level, msg = slog.LevelWarn, "goquent slow query"
+	}
+	if !logger.Enabled(ctx, level) {
+		return res, err
+	}
+	attrs := []slog.Attr{
+		slog.String("operation", string(plan.Operation)),
+		slog.String("sql", plan.SQL),
+		slog.Duration("duration", elapsed),
+		slog.Int64("rows", res.Rows),
+		slog.String("risk", string(plan.RiskLevel)),
+	}
+	if actor, ok := query.PlanActor(plan); ok {
+		attrs = append(attrs, actorAttr(actor))
+	} else if actor, ok := query.ActorFromContext(ctx); ok {
+		attrs = append(attrs, actorAttr(actor))
+	}
+	if len(plan.Warnings) > 0 {
+		attrs = append(attrs, slog.Any("warnings", warningCodes(plan.Warnings)))
+	}
+	if params := query.RedactParams(plan, l.rawParams); len(params) > 0 {
+		attrs = append(attrs, slog.Any("params", params))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+	return res, err
+}
+
+func actorAttr(actor Actor) slog.Attr {
+	return slog.Group("actor", slog.String("id", actor.ID), slog.Any("roles", actor.Roles))
+}
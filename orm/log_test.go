@@ -0,0 +1,131 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func TestWithLoggerRedactsPIIParams(t *testing.T) {
+	query.ResetPolicyRegistry()
+	t.Cleanup(query.ResetPolicyRegistry)
+	if err := RegisterTablePolicy(TablePolicy{Table: "users", PIIColumns: []string{"email"}}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{})
+	db = NewDB(db.SQLDB(), db.drv.Dialect, WithLogger(logger))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `users` WHERE `email` = ? AND `status` = ? LIMIT 1")).
+		WithArgs("alice@example.com", "active").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM sessions WHERE token = ?")).
+		WithArgs("secret-token").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	var row map[string]any
+	if err := db.Table("users").Select("id").Where("email", "alice@example.com").Where("status", "active").Limit(1).FirstMap(&row); err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	if _, err := db.RequireRawApproval("session cleanup").ExecContext(context.Background(), "DELETE FROM sessions WHERE token = ?", "secret-token"); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "alice@example.com") || strings.Contains(buf.String(), "secret-token") {
+		t.Fatalf("log leaked params: %s", buf.String())
+	}
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %s", buf.String())
+	}
+	first := entries[0]
+	if first["level"] != "INFO" || first["operation"] != "select" || first["rows"] != float64(1) || first["risk"] != "low" {
+		t.Fatalf("unexpected select entry %#v", first)
+	}
+	if params, _ := first["params"].([]any); len(params) != 2 || params[0] != RedactedParam || params[1] != "active" {
+		t.Fatalf("unexpected select params %#v", first["params"])
+	}
+	second := entries[1]
+	if second["operation"] != "raw" || second["rows"] != float64(3) {
+		t.Fatalf("unexpected raw entry %#v", second)
+	}
+	if warnings, _ := second["warnings"].([]any); len(warnings) == 0 || warnings[0] != WarningRawSQLUsed {
+		t.Fatalf("unexpected raw warnings %#v", second["warnings"])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithLoggerWarnsAboveSlowThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{})
+	db = NewDB(db.SQLDB(), db.drv.Dialect, WithLogger(logger, LogSlowThreshold(time.Millisecond), LogRawParams()))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs SET state = ?")).
+		WithArgs("done").
+		WillDelayFor(5 * time.Millisecond).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if _, err := db.RequireRawApproval("batch close").ExecContext(context.Background(), "UPDATE jobs SET state = ?", "done"); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 1 || entries[0]["level"] != "WARN" || entries[0]["msg"] != "goquent slow query" {
+		t.Fatalf("unexpected entries %s", buf.String())
+	}
+	if params, _ := entries[0]["params"].([]any); len(params) != 1 || params[0] != "done" {
+		t.Fatalf("expected opted-in raw params, got %#v", entries[0]["params"])
+	}
+}
+
+func TestWithLoggerRecordsActor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{})
+	db = NewDB(db.SQLDB(), db.drv.Dialect, WithLogger(logger))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `users` LIMIT 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+
+	ctx := WithActor(context.Background(), Actor{ID: "agent-7", Roles: []string{"support"}})
+	var row map[string]any
+	if err := db.Table("users").WithContext(ctx).Select("id").Limit(1).FirstMap(&row); err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log entry, got %s", buf.String())
+	}
+	actor, _ := entries[0]["actor"].(map[string]any)
+	if actor["id"] != "agent-7" {
+		t.Fatalf("unexpected actor %#v", entries[0]["actor"])
+	}
+	if roles, _ := actor["roles"].([]any); len(roles) != 1 || roles[0] != "support" {
+		t.Fatalf("unexpected actor roles %#v", actor["roles"])
+	}
+}
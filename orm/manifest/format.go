@@ -98,12 +98,26 @@ func WritePretty(w io.Writer, m *Manifest) error {
 					return err
 				}
 			}
+			if column.Immutable {
+				if _, err := fmt.Fprint(w, " immutable"); err != nil {
+					return err
+				}
+			}
+			if column.InsertOnly {
+				if _, err := fmt.Fprint(w, " insert_only"); err != nil {
+					return err
+				}
+			}
 			if _, err := fmt.Fprintln(w); err != nil {
 				return err
 			}
 		}
 		for _, policy := range table.Policies {
-			if _, err := fmt.Fprintf(w, "  policy: %s column=%s mode=%s\n", policy.Type, policy.Column, policy.Mode); err != nil {
+			role := ""
+			if policy.Role != "" {
+				role = " role=" + policy.Role
+			}
+			if _, err := fmt.Fprintf(w, "  policy: %s column=%s mode=%s%s\n", policy.Type, policy.Column, policy.Mode, role); err != nil {
 				return err
 			}
 		}
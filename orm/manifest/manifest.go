@@ -69,6 +69,14 @@ type Column struct {
 	TenantScope    bool     `json:"tenant_scope,omitempty"`
 	SoftDelete     bool     `json:"soft_delete,omitempty"`
 	RequiredFilter bool     `json:"required_filter,omitempty"`
+	Immutable      bool     `json:"immutable,omitempty"`
+	InsertOnly     bool     `json:"insert_only,omitempty"`
+}
+
+// Writable reports whether updates may change the column: primary keys,
+// generated, immutable and insert-only columns are not writable.
+func (c Column) Writable() bool {
+	return !c.Primary && !c.Generated && !c.Immutable && !c.InsertOnly
 }
 
 // Index describes a database index.
@@ -93,6 +101,9 @@ type Policy struct {
 	Type   string           `json:"type"`
 	Column string           `json:"column,omitempty"`
 	Mode   query.PolicyMode `json:"mode,omitempty"`
+	// Role is the role an "allow" policy lets read Column, or every column
+	// when Column is empty.
+	Role string `json:"role,omitempty"`
 }
 
 // QueryExample gives tools a safe query-shape hint.
@@ -365,6 +376,25 @@ func applyPolicy(table *Table, policy query.TablePolicy) {
 		table.Policies = append(table.Policies, Policy{Type: "required_filter", Column: col, Mode: policy.RequiredFilterMode})
 		markColumn(table, col, func(c *Column) { c.RequiredFilter = true })
 	}
+	for _, col := range policy.ImmutableColumns {
+		table.Policies = append(table.Policies, Policy{Type: "immutable", Column: col, Mode: policy.WriteMode})
+		markColumn(table, col, func(c *Column) { c.Immutable = true })
+	}
+	for _, col := range policy.InsertOnlyColumns {
+		table.Policies = append(table.Policies, Policy{Type: "insert_only", Column: col, Mode: policy.WriteMode})
+		markColumn(table, col, func(c *Column) { c.InsertOnly = true })
+	}
+	for role, cols := range policy.AllowedColumns {
+		if len(cols) == 0 {
+			table.Policies = append(table.Policies, Policy{Type: "allow", Role: role, Mode: policy.AccessMode})
+		}
+		for _, col := range cols {
+			table.Policies = append(table.Policies, Policy{Type: "allow", Column: col, Role: role, Mode: policy.AccessMode})
+		}
+	}
+	if len(policy.AllowedColumns) > 0 && policy.AllowAnonymous {
+		table.Policies = append(table.Policies, Policy{Type: "allow_anonymous", Mode: policy.AccessMode})
+	}
 	table.QueryExamples = queryExamplesForPolicy(policy)
 	sortTable(table)
 }
@@ -462,6 +492,9 @@ func sortTable(table *Table) {
 	sort.Slice(table.Indexes, func(i, j int) bool { return table.Indexes[i].Name < table.Indexes[j].Name })
 	sort.Slice(table.Policies, func(i, j int) bool {
 		if table.Policies[i].Type == table.Policies[j].Type {
+			if table.Policies[i].Column == table.Policies[j].Column {
+				return table.Policies[i].Role < table.Policies[j].Role
+			}
 			return table.Policies[i].Column < table.Policies[j].Column
 		}
 		return table.Policies[i].Type < table.Policies[j].Type
@@ -490,10 +523,11 @@ func fingerprintPolicies(tables []Table) string {
 	for _, table := range tables {
 		tp := tablePolicies{Name: table.Name, Policies: table.Policies}
 		for _, column := range table.Columns {
-			if column.PII || column.TenantScope || column.SoftDelete || column.RequiredFilter {
+			if column.PII || column.TenantScope || column.SoftDelete || column.RequiredFilter || column.Immutable || column.InsertOnly {
 				tp.Columns = append(tp.Columns, ColumnFlags{
 					Name: column.Name, PII: column.PII, TenantScope: column.TenantScope,
 					SoftDelete: column.SoftDelete, RequiredFilter: column.RequiredFilter,
+					Immutable: column.Immutable, InsertOnly: column.InsertOnly,
 				})
 			}
 		}
@@ -509,6 +543,8 @@ type ColumnFlags struct {
 	TenantScope    bool   `json:"tenant_scope,omitempty"`
 	SoftDelete     bool   `json:"soft_delete,omitempty"`
 	RequiredFilter bool   `json:"required_filter,omitempty"`
+	Immutable      bool   `json:"immutable,omitempty"`
+	InsertOnly     bool   `json:"insert_only,omitempty"`
 }
 
 func fingerprintMigrationSchema(schema migration.Schema) string {
@@ -165,6 +165,42 @@ func TestJSONSchemaAndValidateVersion(t *testing.T) {
 	}
 }
 
+func TestIndexesAndRiskEngine(t *testing.T) {
+	m := &Manifest{Tables: []Table{{
+		Name:    "users",
+		Columns: []Column{{Name: "id", Primary: true}, {Name: "email"}, {Name: "name"}},
+		Indexes: []Index{{Name: "users_email_idx", Columns: []string{"email"}, Unique: true}},
+	}}}
+	indexes := m.Indexes()["users"]
+	if len(indexes) != 2 || indexes[0].Name != "PRIMARY" || !indexes[0].Unique || indexes[1].Name != "users_email_idx" {
+		t.Fatalf("unexpected indexes: %#v", indexes)
+	}
+
+	engine := NewRiskEngine(m, query.RiskConfig{})
+	update := &query.QueryPlan{
+		Operation:  query.OperationUpdate,
+		SQL:        "UPDATE users SET name = ? WHERE email = ?",
+		Tables:     []query.TableRef{{Name: "users"}},
+		Predicates: []query.PredicateRef{{Column: "email", Operator: "=", ValueCount: 1}},
+	}
+	codes := map[string]bool{}
+	for _, w := range engine.CheckQuery(update).Warnings {
+		codes[w.Code] = true
+	}
+	if codes[query.WarningBulkUpdateDetected] || codes[query.WarningPredicateNotIndexed] {
+		t.Fatalf("unique email update should be primary-key-like: %#v", codes)
+	}
+
+	update.Predicates = []query.PredicateRef{{Column: "name", Operator: "=", ValueCount: 1}}
+	codes = map[string]bool{}
+	for _, w := range engine.CheckQuery(update).Warnings {
+		codes[w.Code] = true
+	}
+	if !codes[query.WarningBulkUpdateDetected] || !codes[query.WarningPredicateNotIndexed] {
+		t.Fatalf("expected unindexed bulk update warnings: %#v", codes)
+	}
+}
+
 func hasManifestColumnFlag(table Table, name string, ok func(Column) bool) bool {
 	for _, column := range table.Columns {
 		if column.Name == name && ok(column) {
@@ -191,3 +227,59 @@ func hasCheckStatus(v Verification, name, status string) bool {
 	}
 	return false
 }
+
+func TestGenerateWritePolicy(t *testing.T) {
+	m, err := Generate(Options{
+		GeneratedAt: time.Date(2026, 4, 25, 0, 0, 0, 0, time.UTC),
+		Models:      []any{manifestUser{}},
+		Policies: []query.TablePolicy{{
+			Table:             "users",
+			ImmutableColumns:  []string{"tenant_id"},
+			InsertOnlyColumns: []string{"created_at"},
+			WriteMode:         query.PolicyModeBlock,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := m.Tables[0]
+	if !hasManifestColumnFlag(table, "tenant_id", func(c Column) bool { return c.Immutable && !c.Writable() }) {
+		t.Fatalf("expected immutable tenant_id, got %#v", table.Columns)
+	}
+	if !hasManifestColumnFlag(table, "created_at", func(c Column) bool { return c.InsertOnly && !c.Writable() }) {
+		t.Fatalf("expected insert-only created_at, got %#v", table.Columns)
+	}
+	if !hasManifestColumnFlag(table, "email", Column.Writable) || hasManifestColumnFlag(table, "id", Column.Writable) {
+		t.Fatalf("unexpected writable columns: %#v", table.Columns)
+	}
+	if !hasPolicy(table, "immutable", "tenant_id") || !hasPolicy(table, "insert_only", "created_at") {
+		t.Fatalf("expected write policies, got %#v", table.Policies)
+	}
+}
+
+func TestGenerateAccessPolicy(t *testing.T) {
+	m, err := Generate(Options{
+		GeneratedAt: time.Date(2026, 4, 25, 0, 0, 0, 0, time.UTC),
+		Models:      []any{manifestUser{}},
+		Policies: []query.TablePolicy{{
+			Table:          "users",
+			AllowedColumns: map[string][]string{"support": {"id", "email"}, "admin": nil},
+			AccessMode:     query.PolicyModeBlock,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(m); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, policy := range m.Tables[0].Policies {
+		if policy.Type == "allow" {
+			got = append(got, policy.Role+":"+policy.Column)
+		}
+	}
+	if len(got) != 3 || got[0] != "admin:" || got[1] != "support:email" || got[2] != "support:id" {
+		t.Fatalf("allow policies=%v", got)
+	}
+}
@@ -0,0 +1,63 @@
+package manifest
+
+import "github.com/faciam-dev/goquent/orm/query"
+
+// Indexes returns the indexes of each table keyed by table name, in the form
+// used by query.RiskConfig.Indexes. Primary key columns are reported as a
+// unique PRIMARY index when the table does not already list one.
+func (m *Manifest) Indexes() map[string][]query.IndexInfo {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string][]query.IndexInfo, len(m.Tables))
+	for _, table := range m.Tables {
+		var indexes []query.IndexInfo
+		var primary []string
+		for _, column := range table.Columns {
+			if column.Primary {
+				primary = append(primary, column.Name)
+			}
+		}
+		hasPrimary := false
+		for _, index := range table.Indexes {
+			if index.Unique && sameColumns(index.Columns, primary) {
+				hasPrimary = true
+			}
+			indexes = append(indexes, query.IndexInfo{Name: index.Name, Columns: append([]string(nil), index.Columns...), Unique: index.Unique})
+		}
+		if len(primary) > 0 && !hasPrimary {
+			indexes = append([]query.IndexInfo{{Name: "PRIMARY", Columns: primary, Unique: true}}, indexes...)
+		}
+		if len(indexes) > 0 {
+			out[table.Name] = indexes
+		}
+	}
+	return out
+}
+
+// NewRiskEngine returns a risk engine that also reports PREDICATE_NOT_INDEXED
+// and ORDER_BY_NOT_INDEXED for tables in m, and treats equality on a unique
+// index as primary-key-like. Indexes already in config take precedence.
+func NewRiskEngine(m *Manifest, config query.RiskConfig, rules ...query.RiskRule) query.RiskEngine {
+	indexes := m.Indexes()
+	if indexes == nil {
+		indexes = make(map[string][]query.IndexInfo, len(config.Indexes))
+	}
+	for table, idx := range config.Indexes {
+		indexes[table] = idx
+	}
+	config.Indexes = indexes
+	return query.NewRiskEngine(config, rules...)
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if normalizeName(a[i]) != normalizeName(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -67,6 +67,8 @@ func columnArraySchema() map[string]any {
 				"tenant_scope":    map[string]any{"type": "boolean"},
 				"soft_delete":     map[string]any{"type": "boolean"},
 				"required_filter": map[string]any{"type": "boolean"},
+				"immutable":       map[string]any{"type": "boolean"},
+				"insert_only":     map[string]any{"type": "boolean"},
 			},
 		},
 	}
@@ -99,6 +101,7 @@ func policyArraySchema() map[string]any {
 				"type":   map[string]any{"type": "string"},
 				"column": map[string]any{"type": "string"},
 				"mode":   map[string]any{"enum": []string{"", "warn", "enforce", "block"}},
+				"role":   map[string]any{"type": "string"},
 			},
 		},
 	}
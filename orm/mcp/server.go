@@ -24,6 +24,13 @@ type Options struct {
 	Resources []string
 	Tools     []string
 	Prompts   []string
+	// Actor, when set, compiles OperationSpecs as this actor, so fields its
+	// roles may not read are removed from selects.
+	Actor *query.Actor
+	// RiskRules are the extra rules the application passes to
+	// query.NewRiskEngine. They are listed in goquent://review-rules and
+	// evaluated when reviewing SQL, after the registered rules.
+	RiskRules []query.RiskRule
 }
 
 // Server exposes Goquent schema, review, and planning helpers through MCP.
@@ -32,6 +39,8 @@ type Server struct {
 	allowedResources map[string]struct{}
 	allowedTools     map[string]struct{}
 	allowedPrompts   map[string]struct{}
+	actor            *query.Actor
+	riskRules        []query.RiskRule
 }
 
 // NewServer creates a read-only MCP server.
@@ -41,6 +50,8 @@ func NewServer(opts Options) *Server {
 		allowedResources: allowSet(opts.Resources),
 		allowedTools:     allowSet(opts.Tools),
 		allowedPrompts:   allowSet(opts.Prompts),
+		actor:            opts.Actor,
+		riskRules:        append([]query.RiskRule(nil), opts.RiskRules...),
 	}
 }
 
@@ -96,12 +107,12 @@ func (s *Server) Resources() []Resource {
 	resources := []Resource{
 		{URI: "goquent://schema", Name: "schema", Description: "Manifest table and column metadata", MimeType: "application/json"},
 		{URI: "goquent://manifest", Name: "manifest", Description: "Full Goquent manifest including freshness status", MimeType: "application/json"},
-		{URI: "goquent://models", Name: "models", Description: "Model-to-table metadata", MimeType: "application/json"},
+		{URI: "goquent://models", Name: "models", Description: "Model-to-table metadata including columns updates may change", MimeType: "application/json"},
 		{URI: "goquent://relations", Name: "relations", Description: "Relation metadata from the manifest", MimeType: "application/json"},
 		{URI: "goquent://policies", Name: "policies", Description: "Policy metadata from the manifest", MimeType: "application/json"},
 		{URI: "goquent://migrations", Name: "migrations", Description: "Migration review capabilities; apply is not exposed", MimeType: "text/plain"},
 		{URI: "goquent://query-examples", Name: "query-examples", Description: "Safe query-shape examples from the manifest", MimeType: "application/json"},
-		{URI: "goquent://review-rules", Name: "review-rules", Description: "Built-in review warning codes", MimeType: "application/json"},
+		{URI: "goquent://review-rules", Name: "review-rules", Description: "Built-in and registered custom review warning codes", MimeType: "application/json"},
 		{URI: "goquent://manifest-status", Name: "manifest-status", Description: "Manifest freshness status", MimeType: "application/json"},
 	}
 	out := resources[:0]
@@ -136,7 +147,7 @@ func (s *Server) ReadResource(uri string) (string, string, error) {
 	case "goquent://query-examples":
 		return s.jsonText(queryExamplesPayload{Examples: s.queryExamples()}), "application/json", nil
 	case "goquent://review-rules":
-		return s.jsonText(reviewRules()), "application/json", nil
+		return s.jsonText(reviewRules(s.riskRules)), "application/json", nil
 	default:
 		return "", "", fmt.Errorf("unknown resource %q", uri)
 	}
@@ -185,8 +196,7 @@ func (s *Server) CallTool(ctx context.Context, name string, args map[string]any)
 		if err != nil {
 			return ToolResult{}, err
 		}
-		plan := query.NewRawPlan(sqlText)
-		b, err := plan.ToJSON()
+		b, err := s.rawPlan(sqlText).ToJSON()
 		if err != nil {
 			return ToolResult{}, err
 		}
@@ -207,8 +217,7 @@ func (s *Server) CallTool(ctx context.Context, name string, args map[string]any)
 		return s.textTool(string(b)), nil
 	case "generate_query_plan":
 		if sqlText, ok := optionalString(args, "sql"); ok {
-			plan := query.NewRawPlan(sqlText)
-			b, err := plan.ToJSON()
+			b, err := s.rawPlan(sqlText).ToJSON()
 			if err != nil {
 				return ToolResult{}, err
 			}
@@ -331,6 +340,7 @@ func (s *Server) compileOperationSpec(args map[string]any) (ToolResult, error) {
 		Manifest:             s.manifest,
 		Values:               values,
 		RequireFreshManifest: requireFresh,
+		Actor:                s.actor,
 	})
 	if err != nil {
 		return ToolResult{}, err
@@ -386,7 +396,8 @@ func (s *Server) tables() []manifest.Table {
 func (s *Server) models() []map[string]any {
 	var out []map[string]any
 	for _, table := range s.tables() {
-		out = append(out, map[string]any{"model": table.Model, "table": table.Name, "columns": table.Columns})
+		writable, _ := operation.WritableFields(s.manifest, table.Name)
+		out = append(out, map[string]any{"model": table.Model, "table": table.Name, "columns": table.Columns, "writable_columns": writable})
 	}
 	return out
 }
@@ -460,20 +471,52 @@ type reviewRule struct {
 	Description string `json:"description"`
 }
 
-func reviewRules() []reviewRule {
-	return []reviewRule{
+// rawPlan plans sqlText, evaluating the server's extra risk rules.
+func (s *Server) rawPlan(sqlText string) *query.QueryPlan {
+	plan := query.NewRawPlan(sqlText)
+	if len(s.riskRules) > 0 {
+		query.EvaluatePlan(plan, query.NewRiskEngine(query.RiskConfig{}, s.riskRules...))
+	}
+	return plan
+}
+
+// reviewRules lists the built-in rules, the registered custom rules and
+// extra.
+func reviewRules(extra []query.RiskRule) []reviewRule {
+	rules := []reviewRule{
 		{Code: query.WarningUpdateWithoutWhere, Description: "UPDATE without WHERE is blocked"},
 		{Code: query.WarningDeleteWithoutWhere, Description: "DELETE without WHERE is blocked"},
 		{Code: query.WarningLimitMissing, Description: "SELECT list query has no LIMIT"},
 		{Code: query.WarningSelectStarUsed, Description: "SELECT * is harder to review"},
 		{Code: query.WarningRawSQLUsed, Description: "Raw SQL cannot be fully inspected"},
 		{Code: query.WarningDestructiveSQL, Description: "SQL contains destructive DDL"},
+		{Code: query.WarningLockOutsideTransaction, Description: "Locking SELECT runs outside a transaction"},
+		{Code: query.WarningPredicateNotIndexed, Description: "Predicate cannot use any manifest index"},
+		{Code: query.WarningOrderByNotIndexed, Description: "ORDER BY cannot be served by a manifest index"},
+		{Code: query.WarningDeepOffsetPagination, Description: "OFFSET pagination skips many rows"},
+		{Code: query.WarningLeadingWildcardLike, Description: "LIKE pattern starts with a wildcard"},
+		{Code: query.WarningNonSargablePredicate, Description: "Date function on a filtered column prevents index use"},
+		{Code: query.WarningLargeInList, Description: "IN list has very many values"},
+		{Code: query.WarningOrderByRandom, Description: "ORDER BY RAND() sorts every matching row"},
+		{Code: query.WarningDistinctPII, Description: "SELECT DISTINCT over PII columns"},
+		{Code: query.WarningImmutableColumnUpdated, Description: "Update or upsert changes an immutable or insert-only column"},
+		{Code: query.WarningColumnAccessDenied, Description: "Actor selects a column its roles may not read"},
+		{Code: query.WarningTableAccessDenied, Description: "Actor has no role allowed to read the table"},
 		{Code: migration.WarningMigrationDropTable, Description: "Migration drops a table"},
 		{Code: migration.WarningMigrationDropColumn, Description: "Migration drops a column"},
 		{Code: manifest.WarningStale, Description: "Manifest is stale"},
+		{Code: query.WarningApprovalStale, Description: "Approvals file entry matches no reviewed query"},
 		{Code: operation.WarningOperationPIISelected, Description: "OperationSpec selects PII"},
 		{Code: reviewStaticPartialCode(), Description: "Static review could only partially reconstruct a query"},
 	}
+	for _, rule := range append(query.RegisteredRiskRules(), extra...) {
+		description := rule.Description
+		if description == "" {
+			description = "Custom risk rule"
+		}
+		rules = append(rules, reviewRule{Code: rule.Code, Description: description})
+	}
+	return rules
 }
 
 func reviewStaticPartialCode() string {
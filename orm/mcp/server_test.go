@@ -231,3 +231,78 @@ func stringInt(n int) string {
 	b, _ := json.Marshal(n)
 	return string(b)
 }
+
+func TestReviewQueryIncludesFingerprint(t *testing.T) {
+	server := NewServer(Options{Manifest: mcpTestManifest(false)})
+
+	result, err := server.CallTool(context.Background(), "review_query", map[string]any{"sql": "SELECT id FROM users WHERE id IN (1, 2, 3)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query.NewRawPlan("select id from users where id in (?)").Fingerprint
+	if !strings.Contains(result.Content[0].Text, `"fingerprint": "`+want+`"`) {
+		t.Fatalf("expected fingerprint %s, got %s", want, result.Content[0].Text)
+	}
+}
+
+func TestReviewRulesIncludeRegisteredRiskRules(t *testing.T) {
+	if err := query.RegisterRiskRule(query.RiskRule{
+		Code:        "ORDER_BY_RAND",
+		Description: "ORDER BY RAND() scans and sorts the whole table",
+		Check: func(plan *query.QueryPlan) []query.Warning {
+			if strings.Contains(strings.ToUpper(plan.SQL), "RAND()") {
+				return []query.Warning{{Message: "ORDER BY RAND() is slow"}}
+			}
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(Options{Manifest: mcpTestManifest(false)})
+
+	rules, _, err := server.ReadResource("goquent://review-rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rules, `"ORDER_BY_RAND"`) || !strings.Contains(rules, "scans and sorts") {
+		t.Fatalf("expected custom rule in review-rules, got %s", rules)
+	}
+	result, err := server.CallTool(context.Background(), "review_query", map[string]any{"sql": "SELECT id FROM users ORDER BY RAND() LIMIT 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Content[0].Text, "ORDER_BY_RAND") {
+		t.Fatalf("expected custom rule finding, got %s", result.Content[0].Text)
+	}
+}
+
+func TestReviewRulesIncludeEngineRiskRules(t *testing.T) {
+	server := NewServer(Options{Manifest: mcpTestManifest(false), RiskRules: []query.RiskRule{{
+		Code:        "ORDERS_STATUS_FILTER_MISSING",
+		Description: "orders queries must filter status",
+		Check: func(plan *query.QueryPlan) []query.Warning {
+			if strings.Contains(plan.SQL, "orders") && !strings.Contains(plan.SQL, "status") {
+				return []query.Warning{{Message: "orders query has no status filter"}}
+			}
+			return nil
+		},
+	}}})
+
+	rules, _, err := server.ReadResource("goquent://review-rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rules, `"ORDERS_STATUS_FILTER_MISSING"`) || !strings.Contains(rules, "must filter status") {
+		t.Fatalf("expected engine rule in review-rules, got %s", rules)
+	}
+	result, err := server.CallTool(context.Background(), "review_query", map[string]any{"sql": "SELECT id FROM orders WHERE id = 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Content[0].Text, "ORDERS_STATUS_FILTER_MISSING") {
+		t.Fatalf("expected engine rule finding, got %s", result.Content[0].Text)
+	}
+	if rules, _, _ := NewServer(Options{Manifest: mcpTestManifest(false)}).ReadResource("goquent://review-rules"); strings.Contains(rules, "ORDERS_STATUS_FILTER_MISSING") {
+		t.Fatalf("engine rules must not leak into other servers: %s", rules)
+	}
+}
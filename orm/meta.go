@@ -20,6 +20,7 @@ type fieldMeta struct {
 	OmitEmpty  bool
 	BoolPolicy *BoolScanPolicy
 	Decoder    decoderFn
+	Codec      string
 }
 
 func newFieldMeta(col string, index []int) *fieldMeta {
@@ -92,6 +93,10 @@ func getTypeMeta(t reflect.Type) (*typeMeta, error) {
 			case "boollenient":
 				p := BoolLenient
 				fm.BoolPolicy = &p
+			default:
+				if name, ok := strings.CutPrefix(o, "codec="); ok {
+					fm.Codec = name
+				}
 			}
 		}
 		// assign decoder based on field type
@@ -103,6 +108,8 @@ func getTypeMeta(t reflect.Type) (*typeMeta, error) {
 		default:
 			if sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Bool {
 				fm.Decoder = decodePtrBool
+			} else if isArrayField(sf.Type) {
+				fm.Decoder = decodeArray
 			}
 		}
 		m.FieldsByName[col] = fm
@@ -2,7 +2,10 @@ package migration
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -72,6 +75,7 @@ type MigrationStep struct {
 // MigrationPlan explains a schema migration before it is applied.
 type MigrationPlan struct {
 	SQL               string                  `json:"sql"`
+	Checksum          string                  `json:"checksum,omitempty"`
 	Statements        []MigrationStatement    `json:"statements,omitempty"`
 	Steps             []MigrationStep         `json:"steps,omitempty"`
 	RiskLevel         query.RiskLevel         `json:"risk_level"`
@@ -126,8 +130,9 @@ func (p *MigrationPlan) String() string {
 
 // Migrator builds and optionally applies a migration plan.
 type Migrator struct {
-	sql      string
-	approval *query.Approval
+	sql         string
+	approval    *query.Approval
+	trustedKeys []ed25519.PublicKey
 }
 
 // New creates a migration planner for SQL text.
@@ -145,6 +150,20 @@ func (m *Migrator) RequireApproval(reason string) *Migrator {
 	return m
 }
 
+// WithApproval records a complete approval, such as one signed for the
+// migration checksum with query.SignApproval.
+func (m *Migrator) WithApproval(approval query.Approval) *Migrator {
+	m.approval = &approval
+	return m
+}
+
+// WithTrustedKeys makes Apply require an approval signed by one of keys for
+// risky migrations.
+func (m *Migrator) WithTrustedKeys(keys ...ed25519.PublicKey) *Migrator {
+	m.trustedKeys = append([]ed25519.PublicKey(nil), keys...)
+	return m
+}
+
 // Plan builds a migration plan without executing it.
 func (m *Migrator) Plan(ctx context.Context) (*MigrationPlan, error) {
 	_ = ctx
@@ -173,6 +192,20 @@ type Executor interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
+// StatementExecutor is implemented by executors that run a migration
+// statement together with its plan, such as *orm.DB. Apply prefers it over
+// ExecContext.
+type StatementExecutor interface {
+	ExecMigrationStatement(ctx context.Context, plan *MigrationPlan, statement MigrationStatement) (sql.Result, error)
+}
+
+// ApplyWrapper is implemented by executors that wrap a whole migration, such
+// as *orm.DB to trace it. Apply runs the statements inside WrapMigrationApply
+// with the context it passes.
+type ApplyWrapper interface {
+	WrapMigrationApply(ctx context.Context, plan *MigrationPlan, apply func(context.Context) error) error
+}
+
 // Apply validates and executes migration statements sequentially.
 func (m *Migrator) Apply(ctx context.Context, exec Executor) (*MigrationPlan, error) {
 	if exec == nil {
@@ -182,18 +215,32 @@ func (m *Migrator) Apply(ctx context.Context, exec Executor) (*MigrationPlan, er
 	if err != nil {
 		return nil, err
 	}
-	if err := EnsureExecutable(plan); err != nil {
+	if err := EnsureExecutable(plan, m.trustedKeys...); err != nil {
 		return plan, err
 	}
-	for _, statement := range plan.Statements {
-		if strings.TrimSpace(statement.SQL) == "" {
-			continue
-		}
-		if _, err := exec.ExecContext(ctx, statement.SQL); err != nil {
-			return plan, err
+	apply := func(ctx context.Context) error {
+		for _, statement := range plan.Statements {
+			if strings.TrimSpace(statement.SQL) == "" {
+				continue
+			}
+			var err error
+			if se, ok := exec.(StatementExecutor); ok {
+				_, err = se.ExecMigrationStatement(ctx, plan, statement)
+			} else {
+				_, err = exec.ExecContext(ctx, statement.SQL)
+			}
+			if err != nil {
+				return err
+			}
 		}
+		return nil
 	}
-	return plan, nil
+	if w, ok := exec.(ApplyWrapper); ok {
+		err = w.WrapMigrationApply(ctx, plan, apply)
+	} else {
+		err = apply(ctx)
+	}
+	return plan, err
 }
 
 // PlanSQL builds a migration plan from raw migration SQL.
@@ -201,6 +248,7 @@ func PlanSQL(sqlText string) (*MigrationPlan, error) {
 	statements := splitSQLStatements(sqlText)
 	plan := &MigrationPlan{
 		SQL:               sqlText,
+		Checksum:          Checksum(sqlText),
 		RiskLevel:         query.RiskLow,
 		AnalysisPrecision: query.AnalysisPrecise,
 		Metadata:          map[string]any{"source": "sql"},
@@ -218,10 +266,26 @@ func PlanSQL(sqlText string) (*MigrationPlan, error) {
 	return plan, nil
 }
 
+// Checksum returns the checksum of migration SQL that signed approvals are
+// bound to.
+func Checksum(sqlText string) string {
+	sum := sha256.Sum256([]byte(sqlText))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
 // EnsureExecutable enforces migration approval requirements before execution.
-func EnsureExecutable(plan *MigrationPlan) error {
+// With trusted keys, a risky migration also needs an approval signed by one
+// of them for the plan checksum.
+func EnsureExecutable(plan *MigrationPlan, trusted ...ed25519.PublicKey) error {
 	if plan == nil {
-		return nil
+		return fmt.Errorf("goquent: migration plan is required")
+	}
+	checksum := Checksum(plan.SQL)
+	if plan.Checksum != checksum {
+		return fmt.Errorf("goquent: migration checksum %q does not match its SQL", plan.Checksum)
+	}
+	if !statementsMatchSQL(plan) {
+		return fmt.Errorf("goquent: migration statements do not match its SQL")
 	}
 	if plan.Blocked {
 		return fmt.Errorf("%w: %s", query.ErrBlockedOperation, warningCodes(plan.Warnings))
@@ -235,9 +299,41 @@ func EnsureExecutable(plan *MigrationPlan) error {
 	if plan.Approval.ExpiresAt != nil && !plan.Approval.ExpiresAt.After(time.Now().UTC()) {
 		return fmt.Errorf("%w: approval expired", query.ErrApprovalRequired)
 	}
+	if len(trusted) > 0 {
+		if err := query.VerifyApproval(plan.Approval, checksum, trusted); err != nil {
+			return fmt.Errorf("%w: %w", query.ErrApprovalRequired, err)
+		}
+	}
 	return nil
 }
 
+// HasStatement reports whether statement is one of plan's statements, with
+// the same SQL at the same line.
+func HasStatement(plan *MigrationPlan, statement MigrationStatement) bool {
+	if plan == nil {
+		return false
+	}
+	for _, s := range plan.Statements {
+		if s == statement {
+			return true
+		}
+	}
+	return false
+}
+
+func statementsMatchSQL(plan *MigrationPlan) bool {
+	statements := splitSQLStatements(plan.SQL)
+	if len(statements) != len(plan.Statements) {
+		return false
+	}
+	for i, statement := range statements {
+		if plan.Statements[i] != (MigrationStatement{SQL: statement.SQL, Line: statement.Line}) {
+			return false
+		}
+	}
+	return true
+}
+
 func finalizePlan(plan *MigrationPlan) {
 	if plan == nil {
 		return
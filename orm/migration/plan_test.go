@@ -2,6 +2,8 @@ package migration
 
 import (
 	"context"
+	"crypto/ed25519"
+	"database/sql"
 	"errors"
 	"strings"
 	"testing"
@@ -68,6 +70,74 @@ ALTER TABLE accounts DROP COLUMN legacy_id;
 	}
 }
 
+type countingExecutor struct{ calls int }
+
+func (e *countingExecutor) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	e.calls++
+	return nil, nil
+}
+
+func TestApplyVerifiesSignedApproval(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const sqlText = "DROP TABLE legacy_audit;"
+
+	exec := &countingExecutor{}
+	_, err = New(sqlText).RequireApproval("cleanup").WithTrustedKeys(pub).Apply(context.Background(), exec)
+	if !errors.Is(err, query.ErrApprovalUnsigned) || exec.calls != 0 {
+		t.Fatalf("expected unsigned approval to stop Apply, err=%v calls=%d", err, exec.calls)
+	}
+
+	approval := query.Approval{Reason: "cleanup", CreatedBy: "dba"}
+	if err := query.SignApproval(&approval, Checksum(sqlText), key); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := New(sqlText).WithApproval(approval).WithTrustedKeys(pub).Apply(context.Background(), exec)
+	if err != nil || exec.calls != 1 {
+		t.Fatalf("signed approval: err=%v calls=%d", err, exec.calls)
+	}
+	if plan.Checksum != approval.Fingerprint {
+		t.Fatalf("checksum=%s approval=%s", plan.Checksum, approval.Fingerprint)
+	}
+}
+
+func TestEnsureExecutableRejectsEditedPlans(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const sqlText = "DROP TABLE legacy_audit;"
+	approval := query.Approval{Reason: "cleanup", CreatedBy: "dba"}
+	if err := query.SignApproval(&approval, Checksum(sqlText), key); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := New(sqlText).WithApproval(approval).Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureExecutable(plan, pub); err != nil {
+		t.Fatalf("expected signed plan to be executable, got %v", err)
+	}
+
+	editedSQL := *plan
+	editedSQL.SQL = "DROP TABLE users;"
+	if err := EnsureExecutable(&editedSQL, pub); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("expected edited SQL to fail the checksum, got %v", err)
+	}
+	editedStatements := *plan
+	editedStatements.Statements = []MigrationStatement{{SQL: "DROP TABLE users", Line: 1}}
+	if err := EnsureExecutable(&editedStatements, pub); err == nil || !strings.Contains(err.Error(), "statements") {
+		t.Fatalf("expected edited statements to be rejected, got %v", err)
+	}
+	unchecked := *plan
+	unchecked.Checksum = ""
+	if err := EnsureExecutable(&unchecked, pub); err == nil {
+		t.Fatalf("expected a missing checksum to be rejected")
+	}
+}
+
 func TestPlanSQLClassifiesIndexAndUnsupportedDDL(t *testing.T) {
 	plan, err := PlanSQL(`
 CREATE INDEX users_email_idx ON users (email);
@@ -0,0 +1,66 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// NamedQuery runs a raw SQL query with :name or @name parameters taken from
+// params, a map[string]any or a struct. Parameters are rewritten to the
+// dialect's placeholders and slice values expand for IN (:ids). The parameter
+// names are recorded in the plan metadata under "named_params".
+func (db *DB) NamedQuery(ctx context.Context, q string, params any) (*sql.Rows, error) {
+	plan, err := db.ensureNamedExecutable(ctx, q, params)
+	if err != nil {
+		return nil, err
+	}
+	return db.queryPlan(ctx, plan)
+}
+
+// NamedExec executes a raw SQL statement with named parameters. See NamedQuery.
+func (db *DB) NamedExec(ctx context.Context, q string, params any) (sql.Result, error) {
+	plan, err := db.ensureNamedExecutable(ctx, q, params)
+	if err != nil {
+		return nil, err
+	}
+	return db.execPlan(ctx, plan)
+}
+
+// NamedPlan creates a plan for SQL with named parameters without executing it.
+func (db *DB) NamedPlan(ctx context.Context, q string, params any) (*QueryPlan, error) {
+	return db.namedPlan(ctx, q, params)
+}
+
+func (db *DB) namedPlan(ctx context.Context, q string, params any) (*query.QueryPlan, error) {
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if db.rawErr != nil {
+		return nil, db.rawErr
+	}
+	plan, err := query.NewNamedRawPlan(db.drv.Dialect, q, params)
+	if err != nil {
+		return nil, err
+	}
+	db.evaluatePlan(plan)
+	if db.rawApproval != nil {
+		copied := *db.rawApproval
+		plan.Approval = &copied
+	}
+	return plan, nil
+}
+
+func (db *DB) ensureNamedExecutable(ctx context.Context, q string, params any) (*query.QueryPlan, error) {
+	plan, err := db.namedPlan(ctx, q, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ensureExecutable(plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
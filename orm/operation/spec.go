@@ -19,6 +19,7 @@ const (
 	WarningOperationRequiredFilter = "OPERATION_SPEC_REQUIRED_FILTER_MISSING"
 	WarningOperationMissingLimit   = query.WarningLimitMissing
 	WarningOperationStaleManifest  = manifest.WarningStale
+	WarningOperationFieldsFiltered = "OPERATION_SPEC_FIELDS_FILTERED"
 )
 
 var (
@@ -34,6 +35,7 @@ var (
 	ErrRequiredFilterMissing   = errors.New("goquent operation: required filter missing")
 	ErrPIIAccessReasonRequired = errors.New("goquent operation: PII access reason required")
 	ErrStaleManifest           = errors.New("goquent operation: stale manifest")
+	ErrAccessDenied            = errors.New("goquent operation: access denied")
 )
 
 // OperationSpec is the read-only structured interface for AI-generated DB intent.
@@ -97,11 +99,15 @@ type Options struct {
 	Values               map[string]any
 	RequireFreshManifest bool
 	AccessReason         string
+	// Actor, when set, is recorded on compiled plans, and select fields its
+	// roles may not read under the table's "allow" policies are removed.
+	Actor *query.Actor
 }
 
 type validationResult struct {
 	table    manifest.Table
 	columns  map[string]manifest.Column
+	selected []string
 	warnings []query.Warning
 }
 
@@ -129,7 +135,10 @@ func Compile(ctx context.Context, spec OperationSpec, opts Options) (*query.Quer
 	if reason := accessReason(spec, opts); reason != "" {
 		q.AccessReason(reason)
 	}
-	q.Select(spec.Select...)
+	if opts.Actor != nil {
+		q.As(*opts.Actor)
+	}
+	q.Select(result.selected...)
 
 	if softDeleteColumn := tableSoftDeleteColumn(result.table); softDeleteColumn != "" && !specHasFilter(spec, softDeleteColumn) {
 		q.WhereNull(softDeleteColumn)
@@ -157,6 +166,30 @@ func Compile(ctx context.Context, spec OperationSpec, opts Options) (*query.Quer
 	return plan, nil
 }
 
+// WritableFields returns the fields of model that updates may change, in
+// manifest order. Forbidden fields and fields that are not Writable (see
+// manifest.Column.Writable) are left out.
+func WritableFields(m *manifest.Manifest, model string) ([]string, error) {
+	if m == nil {
+		return nil, ErrManifestRequired
+	}
+	table, ok := findTable(m, model)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownModel, model)
+	}
+	return writableColumns(table), nil
+}
+
+func writableColumns(table manifest.Table) []string {
+	var out []string
+	for _, column := range table.Columns {
+		if column.Writable() && !column.Forbidden {
+			out = append(out, column.Name)
+		}
+	}
+	return out
+}
+
 func validate(spec OperationSpec, opts Options) (validationResult, error) {
 	if opts.Manifest == nil {
 		return validationResult{}, ErrManifestRequired
@@ -192,11 +225,18 @@ func validate(spec OperationSpec, opts Options) (validationResult, error) {
 		))
 	}
 
+	readable, readAll := actorReadableColumns(table, opts.Actor)
+	var denied []string
 	for _, field := range spec.Select {
 		column, err := validateField(result.columns, field)
 		if err != nil {
 			return validationResult{}, err
 		}
+		if !readAll && !readable[normalizeName(column.Name)] {
+			denied = append(denied, field)
+			continue
+		}
+		result.selected = append(result.selected, field)
 		if column.Forbidden {
 			return validationResult{}, fmt.Errorf("%w: %s", ErrForbiddenField, field)
 		}
@@ -212,6 +252,17 @@ func validate(spec OperationSpec, opts Options) (validationResult, error) {
 			))
 		}
 	}
+	if len(denied) > 0 {
+		if len(result.selected) == 0 {
+			return validationResult{}, fmt.Errorf("%w: actor may not read %s of %s", ErrAccessDenied, strings.Join(denied, ", "), table.Name)
+		}
+		result.warnings = append(result.warnings, warning(
+			WarningOperationFieldsFiltered,
+			query.RiskLow,
+			fmt.Sprintf("fields the actor may not read were removed from select: %s", strings.Join(denied, ", ")),
+			"select only fields allowed for the actor's roles",
+		))
+	}
 	for _, filter := range spec.Filters {
 		if strings.TrimSpace(filter.Field) == "" {
 			return validationResult{}, fmt.Errorf("%w: filter field is required", ErrInvalidFilter)
@@ -322,6 +373,39 @@ func validateField(columns map[string]manifest.Column, field string) (manifest.C
 	return column, nil
 }
 
+// actorReadableColumns returns the columns actor may read under the
+// table's "allow" policies, or true when every column is readable: without
+// an actor or without "allow" policies.
+func actorReadableColumns(table manifest.Table, actor *query.Actor) (map[string]bool, bool) {
+	if actor == nil {
+		return nil, true
+	}
+	policy := query.TablePolicy{Table: table.Name}
+	for _, p := range table.Policies {
+		if p.Type != "allow" {
+			continue
+		}
+		if policy.AllowedColumns == nil {
+			policy.AllowedColumns = make(map[string][]string)
+		}
+		role := strings.ToLower(strings.TrimSpace(p.Role))
+		if p.Column == "" {
+			policy.AllowedColumns[role] = nil
+			continue
+		}
+		policy.AllowedColumns[role] = append(policy.AllowedColumns[role], p.Column)
+	}
+	columns, all := policy.AllowedColumnsFor(actor.Roles)
+	if all {
+		return nil, true
+	}
+	readable := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		readable[normalizeName(column)] = true
+	}
+	return readable, false
+}
+
 type requiredFilter struct {
 	column string
 	mode   query.PolicyMode
@@ -212,3 +212,68 @@ func hasWarning(warnings []query.Warning, code string) bool {
 	}
 	return false
 }
+
+func TestWritableFields(t *testing.T) {
+	m := testManifest(false)
+	m.Tables[0].Columns[0].InsertOnly = true
+	m.Tables[0].Columns[1].Immutable = true
+
+	fields, err := WritableFields(m, "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(fields, ",") != "deleted_at,email,total" {
+		t.Fatalf("writable fields=%v", fields)
+	}
+	if _, err := WritableFields(m, "Missing"); !errors.Is(err, ErrUnknownModel) {
+		t.Fatalf("expected unknown model, got %v", err)
+	}
+}
+
+func TestCompileFiltersFieldsForActor(t *testing.T) {
+	m := testManifest(false)
+	m.Tables[0].Policies = append(m.Tables[0].Policies,
+		manifest.Policy{Type: "allow", Role: "support", Column: "id", Mode: query.PolicyModeBlock},
+		manifest.Policy{Type: "allow", Role: "support", Column: "total", Mode: query.PolicyModeBlock},
+		manifest.Policy{Type: "allow", Role: "admin", Mode: query.PolicyModeBlock},
+	)
+	limit := int64(10)
+	spec := OperationSpec{
+		Operation: OperationSelect,
+		Model:     "Order",
+		Select:    []string{"id", "email", "total"},
+		Filters:   []FilterSpec{{Field: "tenant_id", Op: "=", ValueRef: "tenant"}},
+		Limit:     &limit,
+	}
+	opts := Options{Manifest: m, Values: map[string]any{"tenant": "t1"}, Actor: &query.Actor{ID: "u1", Roles: []string{"Support"}}}
+
+	plan, err := Compile(context.Background(), spec, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plan.SQL, "email") {
+		t.Fatalf("expected email to be filtered out, got %s", plan.SQL)
+	}
+	if !hasWarning(plan.Warnings, WarningOperationFieldsFiltered) || hasWarning(plan.Warnings, WarningOperationPIISelected) {
+		t.Fatalf("unexpected warnings %#v", plan.Warnings)
+	}
+	if actor, ok := query.PlanActor(plan); !ok || actor.ID != "u1" {
+		t.Fatalf("plan actor=%#v metadata=%#v", actor, plan.Metadata)
+	}
+
+	spec.Select = []string{"email"}
+	if _, err := Compile(context.Background(), spec, opts); !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected access denied, got %v", err)
+	}
+
+	opts.Actor = &query.Actor{Roles: []string{"admin"}}
+	spec.Select = []string{"id", "email"}
+	spec.AccessReason = "audit export"
+	plan, err = Compile(context.Background(), spec, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(plan.SQL, "email") || hasWarning(plan.Warnings, WarningOperationFieldsFiltered) {
+		t.Fatalf("admin should read every field: %s %#v", plan.SQL, plan.Warnings)
+	}
+}
@@ -2,8 +2,10 @@ package orm
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	sqldriver "database/sql/driver"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -31,11 +33,24 @@ type executor interface {
 
 // DB provides main ORM interface.
 type DB struct {
-	drv         *driver.Driver
-	exec        executor
-	scanOpts    ScanOptions
-	rawApproval *query.Approval
-	rawErr      error
+	drv          *driver.Driver
+	exec         executor
+	scanOpts     ScanOptions
+	rawApproval  *query.Approval
+	rawErr       error
+	arrayIn      bool
+	stmts        *stmtCache
+	interceptors []query.Interceptor
+	tracer       Tracer
+	commenter    query.SQLCommenter
+	approvals    *query.ApprovalRegistry
+	trustedKeys  []ed25519.PublicKey
+	riskEngine   query.RiskEngine
+	suppressions []query.Suppression
+	config       *Config
+	// txCtx carries the transaction span of a transaction-scoped DB. Queries
+	// run without a context use it, so their spans become its children.
+	txCtx context.Context
 }
 
 // Option configures DB at creation.
@@ -75,6 +90,15 @@ func newDB(d *driver.Driver, exec executor, opts ...Option) *DB {
 	return db
 }
 
+// registerPolicies registers the table policies of a WithConfig
+// configuration.
+func (db *DB) registerPolicies() error {
+	if db.config == nil {
+		return nil
+	}
+	return db.config.RegisterPolicies()
+}
+
 // RequireRawApproval returns a shallow DB copy that can execute risky raw SQL
 // with an explicit approval reason.
 func (db *DB) RequireRawApproval(reason string) *DB {
@@ -90,10 +114,24 @@ func (db *DB) RequireRawApproval(reason string) *DB {
 	return &next
 }
 
-// NewDB wraps an existing sql.DB with a dialect into DB.
+// NewDB wraps an existing sql.DB with a dialect into DB. If the table
+// policies of a WithConfig configuration cannot be registered, every
+// execution through the DB fails with the registration error instead of
+// running without them; OpenWithDriverOptions returns the error directly.
 func NewDB(sqlDB *sql.DB, dialect driver.Dialect, opts ...Option) *DB {
 	d := &driver.Driver{DB: sqlDB, Dialect: dialect}
-	return newDB(d, sqlDB, opts...)
+	db := newDB(d, sqlDB, opts...)
+	if err := db.registerPolicies(); err != nil {
+		db.interceptors = append([]Interceptor{failExecutions(err)}, db.interceptors...)
+	}
+	return db
+}
+
+// failExecutions returns an interceptor that fails every execution with err.
+func failExecutions(err error) Interceptor {
+	return func(context.Context, *QueryPlan, Handler) (ExecResult, error) {
+		return ExecResult{}, err
+	}
 }
 
 // Open opens a MySQL database with default pooling. Deprecated: use
@@ -130,74 +168,168 @@ func OpenWithDriverOptions(driverName, dsn string, opts ...Option) (*DB, error)
 			dialect = defaultDialect(driverName)
 		}
 		d := &driver.Driver{DB: sqlDB, Dialect: dialect}
-		return newDB(d, sqlDB, opts...), nil
+		db := newDB(d, sqlDB, opts...)
+		if err := db.registerPolicies(); err != nil {
+			sqlDB.Close()
+			return nil, err
+		}
+		return db, nil
 	}
 
 	drv, err := driver.Open(driverName, dsn, 10, 10, time.Hour)
 	if err != nil {
 		return nil, err
 	}
-	return newDB(drv, drv.DB, opts...), nil
+	db := newDB(drv, drv.DB, opts...)
+	if err := db.registerPolicies(); err != nil {
+		drv.Close()
+		return nil, err
+	}
+	return db, nil
 }
 
 // Close closes underlying DB.
-func (db *DB) Close() error { return db.drv.Close() }
+func (db *DB) Close() error {
+	if db.stmts != nil {
+		db.stmts.close()
+	}
+	return db.drv.Close()
+}
 
 // newTransactionDB wraps a sql.Tx in a DB instance bound to the same driver.
-func (db *DB) newTransactionDB(tx *sql.Tx) *DB {
-	return &DB{drv: db.drv, exec: tx, scanOpts: db.scanOpts, rawApproval: db.rawApproval, rawErr: db.rawErr}
+// With WithTracer, ctx holds the transaction span used as the parent of
+// queries that run without their own context.
+func (db *DB) newTransactionDB(ctx context.Context, tx *sql.Tx) *DB {
+	next := *db
+	next.exec = tx
+	if db.tracer != nil {
+		next.txCtx = ctx
+	}
+	if db.stmts != nil {
+		next.exec = db.stmts.tx(tx)
+	}
+	return &next
 }
 
 // Tx represents a transaction-scoped DB wrapper.
 type Tx struct {
 	*DB
 	driver.Tx
+	endSpan func(error)
+}
+
+// Context returns the context holding the transaction span when WithTracer
+// is set, or context.Background. Pass it to queries that take an explicit
+// context so their spans become children of the transaction span.
+func (t Tx) Context() context.Context {
+	if t.DB != nil && t.DB.txCtx != nil {
+		return t.DB.txCtx
+	}
+	return context.Background()
+}
+
+// Commit commits the transaction and ends its span when WithTracer is set.
+func (t Tx) Commit() error {
+	err := t.Tx.Commit()
+	if t.endSpan != nil {
+		t.endSpan(err)
+	}
+	return err
+}
+
+// Rollback aborts the transaction and ends its span when WithTracer is set.
+func (t Tx) Rollback() error {
+	err := t.Tx.Rollback()
+	if t.endSpan != nil {
+		if errors.Is(err, sql.ErrTxDone) {
+			t.endSpan(nil)
+		} else {
+			t.endSpan(err)
+		}
+	}
+	return err
 }
 
 // Transaction executes fn in a transaction.
 func (db *DB) Transaction(fn func(tx Tx) error) error {
-	return db.drv.Transaction(func(t driver.Tx) error {
-		txDB := db.newTransactionDB(t.Tx)
+	ctx, end := db.startSpan(nil, "goquent.transaction")
+	err := db.drv.Transaction(func(t driver.Tx) error {
+		txDB := db.newTransactionDB(ctx, t.Tx)
 		return fn(Tx{DB: txDB, Tx: t})
 	})
+	end(err)
+	return err
 }
 
 // TransactionContext executes fn in a transaction using ctx.
 func (db *DB) TransactionContext(ctx context.Context, fn func(tx Tx) error) error {
-	return db.drv.TransactionContext(ctx, func(t driver.Tx) error {
-		txDB := db.newTransactionDB(t.Tx)
+	ctx, end := db.startSpan(ctx, "goquent.transaction")
+	err := db.drv.TransactionContext(ctx, func(t driver.Tx) error {
+		txDB := db.newTransactionDB(ctx, t.Tx)
 		return fn(Tx{DB: txDB, Tx: t})
 	})
+	end(err)
+	return err
 }
 
 // Begin starts a transaction for manual control.
 func (db *DB) Begin() (Tx, error) {
+	ctx, end := db.startSpan(nil, "goquent.transaction")
 	t, err := db.drv.Begin()
 	if err != nil {
+		end(err)
 		return Tx{}, err
 	}
-	txDB := db.newTransactionDB(t.Tx)
-	return Tx{DB: txDB, Tx: t}, nil
+	txDB := db.newTransactionDB(ctx, t.Tx)
+	return Tx{DB: txDB, Tx: t, endSpan: end}, nil
 }
 
 // BeginTx starts a transaction using ctx and returns the Tx.
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	ctx, end := db.startSpan(ctx, "goquent.transaction")
 	t, err := db.drv.BeginTx(ctx, opts)
 	if err != nil {
+		end(err)
 		return Tx{}, err
 	}
-	txDB := db.newTransactionDB(t.Tx)
-	return Tx{DB: txDB, Tx: t}, nil
+	txDB := db.newTransactionDB(ctx, t.Tx)
+	return Tx{DB: txDB, Tx: t, endSpan: end}, nil
 }
 
 // Model creates a query for the struct table.
 func (db *DB) Model(v any) *query.Query {
-	return query.New(db.exec, model.TableName(v), db.drv.Dialect)
+	return db.newQuery(model.TableName(v))
 }
 
 // Table creates a query for table name.
 func (db *DB) Table(name string) *query.Query {
-	return query.New(db.exec, name, db.drv.Dialect)
+	return db.newQuery(name)
+}
+
+func (db *DB) newQuery(table string) *query.Query {
+	q := query.New(db.exec, table, db.drv.Dialect)
+	if db.arrayIn {
+		q.PostgresArrayIn()
+	}
+	if len(db.interceptors) > 0 {
+		q = q.WithInterceptors(db.interceptors...)
+	}
+	if db.commenter != nil {
+		q = q.WithSQLCommenter(db.commenter)
+	}
+	if db.approvals != nil {
+		q = q.WithApprovalRegistry(db.approvals)
+	}
+	if len(db.trustedKeys) > 0 {
+		q = q.WithTrustedKeys(db.trustedKeys...)
+	}
+	if db.riskEngine != nil {
+		q = q.WithRiskEngine(db.riskEngine).WithSuppressions(db.suppressions...)
+	}
+	if db.txCtx != nil {
+		q = q.WithContext(db.txCtx)
+	}
+	return q
 }
 
 func (db *DB) rawPlan(ctx context.Context, q string, args ...any) (*query.QueryPlan, error) {
@@ -210,6 +342,7 @@ func (db *DB) rawPlan(ctx context.Context, q string, args ...any) (*query.QueryP
 		return nil, db.rawErr
 	}
 	plan := query.NewRawPlan(q, args...)
+	db.evaluatePlan(plan)
 	if db.rawApproval != nil {
 		copied := *db.rawApproval
 		plan.Approval = &copied
@@ -222,7 +355,7 @@ func (db *DB) ensureRawExecutable(ctx context.Context, q string, args ...any) (*
 	if err != nil {
 		return nil, err
 	}
-	if err := query.EnsurePlanExecutable(plan); err != nil {
+	if err := db.ensureExecutable(plan); err != nil {
 		return plan, err
 	}
 	return plan, nil
@@ -255,18 +388,16 @@ func (db *DB) rejectedQueryRow(ctx context.Context) *sql.Row {
 
 // Query runs a raw SQL query returning multiple rows.
 func (db *DB) Query(q string, args ...any) (*sql.Rows, error) {
-	if _, err := db.ensureRawExecutable(nil, q, args...); err != nil {
-		return nil, err
-	}
-	return db.queryContextTrusted(nil, q, args...)
+	return db.QueryContext(nil, q, args...)
 }
 
 // QueryContext runs Query with a context.
 func (db *DB) QueryContext(ctx context.Context, q string, args ...any) (*sql.Rows, error) {
-	if _, err := db.ensureRawExecutable(ctx, q, args...); err != nil {
+	plan, err := db.ensureRawExecutable(ctx, q, args...)
+	if err != nil {
 		return nil, err
 	}
-	return db.queryContextTrusted(ctx, q, args...)
+	return db.queryPlan(ctx, plan)
 }
 
 // RawPlan creates a plan for caller-supplied SQL without executing it.
@@ -276,18 +407,16 @@ func (db *DB) RawPlan(ctx context.Context, q string, args ...any) (*QueryPlan, e
 
 // Exec executes a raw SQL statement.
 func (db *DB) Exec(q string, args ...any) (sql.Result, error) {
-	if _, err := db.ensureRawExecutable(nil, q, args...); err != nil {
-		return nil, err
-	}
-	return db.execContextTrusted(nil, q, args...)
+	return db.ExecContext(nil, q, args...)
 }
 
 // ExecContext executes a raw SQL statement with a context.
 func (db *DB) ExecContext(ctx context.Context, q string, args ...any) (sql.Result, error) {
-	if _, err := db.ensureRawExecutable(ctx, q, args...); err != nil {
+	plan, err := db.ensureRawExecutable(ctx, q, args...)
+	if err != nil {
 		return nil, err
 	}
-	return db.execContextTrusted(ctx, q, args...)
+	return db.execPlan(ctx, plan)
 }
 
 // QueryRow executes a query that is expected to return at most one row.
@@ -297,10 +426,7 @@ func (db *DB) ExecContext(ctx context.Context, q string, args ...any) (sql.Resul
 // has no public error constructor. When raw SQL approval checks fail, QueryRow
 // does not execute the caller-supplied SQL.
 func (db *DB) QueryRow(q string, args ...any) *sql.Row {
-	if _, err := db.ensureRawExecutable(nil, q, args...); err != nil {
-		return db.rejectedQueryRow(nil)
-	}
-	return db.exec.QueryRow(q, args...)
+	return db.QueryRowContext(nil, q, args...)
 }
 
 // QueryRowContext executes a query with context returning at most one row.
@@ -310,19 +436,18 @@ func (db *DB) QueryRow(q string, args ...any) *sql.Row {
 // *sql.Row has no public error constructor. When raw SQL approval checks fail,
 // QueryRowContext does not execute the caller-supplied SQL.
 func (db *DB) QueryRowContext(ctx context.Context, q string, args ...any) *sql.Row {
-	if _, err := db.ensureRawExecutable(ctx, q, args...); err != nil {
+	row, err := db.QueryRowE(ctx, q, args...)
+	if err != nil {
 		return db.rejectedQueryRow(ctx)
 	}
-	return db.exec.QueryRowContext(ctx, q, args...)
+	return row
 }
 
 // QueryRowE validates raw SQL policy and executes a context-aware single-row query.
 func (db *DB) QueryRowE(ctx context.Context, q string, args ...any) (*sql.Row, error) {
-	if _, err := db.ensureRawExecutable(ctx, q, args...); err != nil {
+	plan, err := db.ensureRawExecutable(ctx, q, args...)
+	if err != nil {
 		return nil, err
 	}
-	if ctx == nil {
-		return db.exec.QueryRow(q, args...), nil
-	}
-	return db.exec.QueryRowContext(ctx, q, args...), nil
+	return db.queryRowPlan(ctx, plan)
 }
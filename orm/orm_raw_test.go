@@ -74,3 +74,42 @@ func TestDeprecatedQueryRowExecutesApprovedRawSQL(t *testing.T) {
 		t.Fatalf("expected no rejected sentinel query, got %#v", exec.queryRowsContext)
 	}
 }
+
+func TestNamedExecRewritesParametersForDialect(t *testing.T) {
+	db, exec := newCaptureWriteDB(driver.PostgresDialect{})
+	db = db.RequireRawApproval("operator reviewed named update")
+
+	params := struct {
+		Status string  `db:"status"`
+		IDs    []int64 `db:"ids"`
+	}{Status: "active", IDs: []int64{1, 2}}
+	if _, err := db.NamedExec(context.Background(), "UPDATE users SET status = :status, note = ':skip' WHERE id IN (:ids) AND created_at::date = @status", params); err != nil {
+		t.Fatalf("named exec: %v", err)
+	}
+	want := "UPDATE users SET status = $1, note = ':skip' WHERE id IN ($2, $3) AND created_at::date = $4"
+	if exec.query != want {
+		t.Fatalf("unexpected SQL:\n got %s\nwant %s", exec.query, want)
+	}
+	if len(exec.args) != 4 || exec.args[0] != "active" || exec.args[1] != int64(1) || exec.args[2] != int64(2) || exec.args[3] != "active" {
+		t.Fatalf("unexpected args: %#v", exec.args)
+	}
+
+	plan, err := db.NamedPlan(context.Background(), "SELECT id FROM users WHERE id IN (:ids)", map[string]any{"ids": []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("named plan: %v", err)
+	}
+	names, _ := plan.Metadata["named_params"].([]string)
+	if len(names) != 2 || names[0] != "ids[0]" || names[1] != "ids[1]" {
+		t.Fatalf("unexpected named params metadata: %#v", plan.Metadata)
+	}
+}
+
+func TestNamedExecRequiresRawApproval(t *testing.T) {
+	db, exec := newCaptureWriteDB(driver.MySQLDialect{})
+	if _, err := db.NamedExec(context.Background(), "DELETE FROM users WHERE id = :id", map[string]any{"id": 1}); err == nil {
+		t.Fatalf("expected unapproved named SQL to be rejected")
+	}
+	if exec.query != "" {
+		t.Fatalf("expected no execution, got %q", exec.query)
+	}
+}
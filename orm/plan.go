@@ -20,20 +20,35 @@ type RiskEngine = query.RiskEngine
 type RiskResult = query.RiskResult
 type RiskConfig = query.RiskConfig
 type RiskRuleConfig = query.RiskRuleConfig
+type IndexInfo = query.IndexInfo
+type RiskRule = query.RiskRule
 type PolicyMode = query.PolicyMode
 type TablePolicy = query.TablePolicy
 type TableRef = query.TableRef
 type ColumnRef = query.ColumnRef
 type JoinRef = query.JoinRef
 type PredicateRef = query.PredicateRef
+type OrderRef = query.OrderRef
+type LockRef = query.LockRef
+type LockOption = query.LockOption
 type QueryPlan = query.QueryPlan
+type Expr = query.Expr
+type Interceptor = query.Interceptor
+type Handler = query.Handler
+type ExecResult = query.ExecResult
+type SQLCommenter = query.SQLCommenter
+type ApprovalRegistry = query.ApprovalRegistry
+type ApprovalRecord = query.ApprovalRecord
+type Allowlist = query.Allowlist
+type AllowlistEntry = query.AllowlistEntry
 
 const (
-	OperationSelect = query.OperationSelect
-	OperationInsert = query.OperationInsert
-	OperationUpdate = query.OperationUpdate
-	OperationDelete = query.OperationDelete
-	OperationRaw    = query.OperationRaw
+	OperationSelect    = query.OperationSelect
+	OperationInsert    = query.OperationInsert
+	OperationUpdate    = query.OperationUpdate
+	OperationDelete    = query.OperationDelete
+	OperationRaw       = query.OperationRaw
+	OperationMigration = query.OperationMigration
 
 	RiskLow         = query.RiskLow
 	RiskMedium      = query.RiskMedium
@@ -62,6 +77,17 @@ const (
 	WarningSoftDeleteFilterMissing = query.WarningSoftDeleteFilterMissing
 	WarningPIIColumnSelected       = query.WarningPIIColumnSelected
 	WarningRequiredFilterMissing   = query.WarningRequiredFilterMissing
+	WarningImmutableColumnUpdated  = query.WarningImmutableColumnUpdated
+	WarningLockOutsideTransaction  = query.WarningLockOutsideTransaction
+	WarningApprovalStale           = query.WarningApprovalStale
+	WarningPredicateNotIndexed     = query.WarningPredicateNotIndexed
+	WarningOrderByNotIndexed       = query.WarningOrderByNotIndexed
+	WarningDeepOffsetPagination    = query.WarningDeepOffsetPagination
+	WarningLeadingWildcardLike     = query.WarningLeadingWildcardLike
+	WarningNonSargablePredicate    = query.WarningNonSargablePredicate
+	WarningLargeInList             = query.WarningLargeInList
+	WarningOrderByRandom           = query.WarningOrderByRandom
+	WarningDistinctPII             = query.WarningDistinctPII
 
 	SuppressionScopeQuery  = query.SuppressionScopeQuery
 	SuppressionScopeInline = query.SuppressionScopeInline
@@ -70,16 +96,33 @@ const (
 	PolicyModeWarn    = query.PolicyModeWarn
 	PolicyModeEnforce = query.PolicyModeEnforce
 	PolicyModeBlock   = query.PolicyModeBlock
+
+	RedactedParam = query.RedactedParam
+
+	DefaultApprovalsFile = query.DefaultApprovalsFile
+	DefaultAllowlistFile = query.DefaultAllowlistFile
+
+	DefaultDeepOffset  = query.DefaultDeepOffset
+	DefaultLargeInList = query.DefaultLargeInList
 )
 
 var (
-	ErrApprovalRequired       = query.ErrApprovalRequired
-	ErrApprovalReasonRequired = query.ErrApprovalReasonRequired
-	ErrAccessReasonRequired   = query.ErrAccessReasonRequired
-	ErrBlockedOperation       = query.ErrBlockedOperation
-	DefaultRiskEngine         = query.DefaultRiskEngine
+	ErrApprovalRequired         = query.ErrApprovalRequired
+	ErrApprovalReasonRequired   = query.ErrApprovalReasonRequired
+	ErrAccessReasonRequired     = query.ErrAccessReasonRequired
+	ErrBlockedOperation         = query.ErrBlockedOperation
+	ErrNotExecuted              = query.ErrNotExecuted
+	ErrApprovalUnsigned         = query.ErrApprovalUnsigned
+	ErrApprovalExpired          = query.ErrApprovalExpired
+	ErrApprovalSignatureInvalid = query.ErrApprovalSignatureInvalid
+	ErrPlanNotAllowed           = query.ErrPlanNotAllowed
+	DefaultRiskEngine           = query.DefaultRiskEngine
 )
 
+func NewExpr(sql string, args ...any) Expr {
+	return query.NewExpr(sql, args...)
+}
+
 func NewSuppression(code, reason string, opts ...SuppressionOption) (Suppression, error) {
 	return query.NewSuppression(code, reason, opts...)
 }
@@ -96,6 +139,14 @@ func ParseInlineSuppression(comment string) (Suppression, bool, error) {
 	return query.ParseInlineSuppression(comment)
 }
 
-func NewRiskEngine(config RiskConfig) RiskEngine {
-	return query.NewRiskEngine(config)
+func NewRiskEngine(config RiskConfig, rules ...RiskRule) RiskEngine {
+	return query.NewRiskEngine(config, rules...)
+}
+
+func RegisterRiskRule(rule RiskRule) error {
+	return query.RegisterRiskRule(rule)
+}
+
+func RegisteredRiskRules() []RiskRule {
+	return query.RegisteredRiskRules()
 }
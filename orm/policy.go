@@ -61,12 +61,51 @@ func (b *ModelPolicyBuilder) RequiredFilter(columns ...string) *ModelPolicyBuild
 	return b
 }
 
+// Immutable forbids updates and upserts from changing columns, such as
+// tenant_id or owner_id.
+func (b *ModelPolicyBuilder) Immutable(columns ...string) *ModelPolicyBuilder {
+	b.policy.ImmutableColumns = append(b.policy.ImmutableColumns, columns...)
+	b.register()
+	return b
+}
+
+// InsertOnly marks columns, such as created_at, that are written on insert
+// and never updated.
+func (b *ModelPolicyBuilder) InsertOnly(columns ...string) *ModelPolicyBuilder {
+	b.policy.InsertOnlyColumns = append(b.policy.InsertOnlyColumns, columns...)
+	b.register()
+	return b
+}
+
+// Allow lets role read columns of the table, or every column when none are
+// given. Once a table has Allow rules, selects run as an Actor may read only
+// the columns allowed for its roles; other selects, and selects without an
+// actor, are blocked by default.
+func (b *ModelPolicyBuilder) Allow(role string, columns ...string) *ModelPolicyBuilder {
+	if b.policy.AllowedColumns == nil {
+		b.policy.AllowedColumns = make(map[string][]string)
+	}
+	b.policy.AllowedColumns[role] = append(b.policy.AllowedColumns[role], columns...)
+	b.register()
+	return b
+}
+
+// AllowAnonymous lets selects without an actor read the table even though it
+// has Allow rules.
+func (b *ModelPolicyBuilder) AllowAnonymous() *ModelPolicyBuilder {
+	b.policy.AllowAnonymous = true
+	b.register()
+	return b
+}
+
 // PolicyMode sets all policy modes for this model declaration.
 func (b *ModelPolicyBuilder) PolicyMode(mode PolicyMode) *ModelPolicyBuilder {
 	b.policy.TenantMode = mode
 	b.policy.SoftDeleteMode = mode
 	b.policy.PIIMode = mode
 	b.policy.RequiredFilterMode = mode
+	b.policy.WriteMode = mode
+	b.policy.AccessMode = mode
 	b.register()
 	return b
 }
@@ -1,8 +1,13 @@
 package orm
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/faciam-dev/goquent/orm/driver"
+
 	"github.com/faciam-dev/goquent/orm/query"
 )
 
@@ -41,3 +46,108 @@ func TestModelPolicyBuilderRegistersPolicy(t *testing.T) {
 		t.Fatalf("required filters=%#v", policy.RequiredFilterColumns)
 	}
 }
+
+func TestModelPolicyBuilderWriteProtection(t *testing.T) {
+	ResetModelPolicies()
+	t.Cleanup(ResetModelPolicies)
+
+	if err := Model(policyUser{}).Immutable("tenant_id").InsertOnly("created_at").Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	policy, ok := query.PolicyForTable("users")
+	if !ok {
+		t.Fatal("policy not registered")
+	}
+	if len(policy.ImmutableColumns) != 1 || policy.ImmutableColumns[0] != "tenant_id" {
+		t.Fatalf("immutable=%#v", policy.ImmutableColumns)
+	}
+	if len(policy.InsertOnlyColumns) != 1 || policy.InsertOnlyColumns[0] != "created_at" {
+		t.Fatalf("insert only=%#v", policy.InsertOnlyColumns)
+	}
+	if policy.WriteMode != PolicyModeEnforce {
+		t.Fatalf("write mode=%q", policy.WriteMode)
+	}
+}
+
+func TestGenericWritesRespectWritePolicy(t *testing.T) {
+	ResetModelPolicies()
+	t.Cleanup(ResetModelPolicies)
+	if err := Model(genericWriteUser{}).Immutable("age").Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	ctx := context.Background()
+	db, exec := newCaptureWriteDB(driver.MySQLDialect{})
+
+	_, err := Update(ctx, db, genericWriteUser{ID: 3, Name: "alice", Age: 30}, Columns("name", "age"), WherePK())
+	if !errors.Is(err, ErrApprovalRequired) {
+		t.Fatalf("Update error=%v", err)
+	}
+	_, err = Upsert(ctx, db, genericWriteUser{ID: 3, Name: "alice", Age: 30}, WherePK(), UpdateColumns("name", "age"))
+	if !errors.Is(err, ErrApprovalRequired) {
+		t.Fatalf("Upsert error=%v", err)
+	}
+	_, err = Upsert(ctx, db, genericWriteUser{ID: 3, Name: "alice", Age: 30}, WherePK(), UpdateColumns(Assign("age", NewExpr("`age` + ?", 1))))
+	if !errors.Is(err, ErrApprovalRequired) {
+		t.Fatalf("Upsert expression error=%v", err)
+	}
+	_, err = Update(ctx, db, map[string]any{"id": 3, "name": "alice", "age": 9}, Table("users"), PK("id"), WherePK())
+	if !errors.Is(err, ErrApprovalRequired) || !strings.Contains(err.Error(), query.WarningImmutableColumnUpdated) {
+		t.Fatalf("map Update error=%v", err)
+	}
+	if exec.query != "" {
+		t.Fatalf("refused write executed: %s", exec.query)
+	}
+
+	if _, err := Update(ctx, db, genericWriteUser{ID: 3, Name: "alice", Age: 30}, WherePK()); err != nil {
+		t.Fatalf("Update with default columns: %v", err)
+	}
+	if strings.Contains(exec.query, "`age`") {
+		t.Fatalf("default SET must leave out the immutable column: %s", exec.query)
+	}
+	if _, err := Upsert(ctx, db, genericWriteUser{ID: 3, Name: "alice", Age: 30}, WherePK()); err != nil {
+		t.Fatalf("Upsert with default update columns: %v", err)
+	}
+	if !strings.Contains(exec.query, "`age`") || strings.Contains(exec.query, "`age` = VALUES(`age`)") {
+		t.Fatalf("default upsert must insert but not update the immutable column: %s", exec.query)
+	}
+	if _, err := Upsert(ctx, db, genericWriteUser{ID: 3, Name: "alice", Age: 30}, WherePK(), UpdateColumns("name")); err != nil {
+		t.Fatalf("Upsert writable column: %v", err)
+	}
+
+	Model(genericWriteUser{}).Immutable("age").PolicyMode(PolicyModeBlock)
+	_, err = Update(ctx, db, genericWriteUser{ID: 3, Age: 31}, Columns("age"), WherePK())
+	if !errors.Is(err, ErrBlockedOperation) {
+		t.Fatalf("blocked Update error=%v", err)
+	}
+}
+
+func TestModelPolicyBuilderAllow(t *testing.T) {
+	ResetModelPolicies()
+	t.Cleanup(ResetModelPolicies)
+
+	if err := Model(policyUser{}).Allow("support", "id", "email").Allow("admin").Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	policy, ok := query.PolicyForTable("users")
+	if !ok {
+		t.Fatal("policy not registered")
+	}
+	if cols := policy.AllowedColumns["support"]; len(cols) != 2 || cols[0] != "id" || cols[1] != "email" {
+		t.Fatalf("support columns=%#v", policy.AllowedColumns)
+	}
+	if _, all := policy.AllowedColumnsFor([]string{"admin"}); !all {
+		t.Fatalf("admin should read every column: %#v", policy.AllowedColumns)
+	}
+	if policy.AccessMode != PolicyModeBlock {
+		t.Fatalf("access mode=%q", policy.AccessMode)
+	}
+
+	db, _ := newCaptureWriteDB(driver.MySQLDialect{})
+	plan, err := db.Table("users").As(Actor{ID: "u1", Roles: []string{"support"}}).Select("id", "phone").Limit(1).Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if !plan.Blocked || plan.Metadata["actor"] != "u1" {
+		t.Fatalf("blocked=%v metadata=%#v warnings=%#v", plan.Blocked, plan.Metadata, plan.Warnings)
+	}
+}
@@ -0,0 +1,292 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	WarningColumnAccessDenied = "COLUMN_ACCESS_DENIED"
+	WarningTableAccessDenied  = "TABLE_ACCESS_DENIED"
+)
+
+// Actor identifies who a query runs for. Its roles are matched against
+// TablePolicy.AllowedColumns.
+type Actor struct {
+	ID    string   `json:"id,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a context whose queries run as actor, unless Query.As
+// names another actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, actorKey{}, cloneActor(actor))
+}
+
+// ActorFromContext returns the actor installed by WithActor.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	if ctx == nil {
+		return Actor{}, false
+	}
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	if !ok {
+		return Actor{}, false
+	}
+	return cloneActor(actor), true
+}
+
+// As runs the query as actor. The actor is recorded in plan metadata and
+// selects are checked against the column access rules of the table policy.
+func (q *Query) As(actor Actor) *Query {
+	q = q.derive()
+	actor = cloneActor(actor)
+	q.actor = &actor
+	return q
+}
+
+// actorFor returns the actor set with As, or the actor of ctx or of the
+// query's context.
+func (q *Query) actorFor(ctx context.Context) (Actor, bool) {
+	if q.actor != nil {
+		return cloneActor(*q.actor), true
+	}
+	if actor, ok := ActorFromContext(ctx); ok {
+		return actor, true
+	}
+	return ActorFromContext(q.ctx)
+}
+
+// PlanActor returns the actor recorded in plan metadata as "actor" and
+// "actor_roles".
+func PlanActor(plan *QueryPlan) (Actor, bool) {
+	if plan == nil || plan.Metadata == nil {
+		return Actor{}, false
+	}
+	id, hasID := plan.Metadata["actor"].(string)
+	var roles []string
+	switch v := plan.Metadata["actor_roles"].(type) {
+	case []string:
+		roles = append(roles, v...)
+	case []any:
+		// Plans decoded from JSON.
+		for _, role := range v {
+			if s, ok := role.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	default:
+		if !hasID {
+			return Actor{}, false
+		}
+	}
+	return Actor{ID: id, Roles: roles}, true
+}
+
+func setPlanActor(plan *QueryPlan, actor Actor) {
+	if plan.Metadata == nil {
+		plan.Metadata = make(map[string]any)
+	}
+	if actor.ID != "" {
+		plan.Metadata["actor"] = actor.ID
+	}
+	plan.Metadata["actor_roles"] = append([]string{}, actor.Roles...)
+}
+
+func cloneActor(actor Actor) Actor {
+	actor.Roles = append([]string(nil), actor.Roles...)
+	return actor
+}
+
+// AllowedColumnsFor returns the columns the roles may read and whether they
+// may read every column. A policy without access rules allows every column;
+// otherwise roles without a rule read nothing.
+func (p TablePolicy) AllowedColumnsFor(roles []string) ([]string, bool) {
+	if len(p.AllowedColumns) == 0 {
+		return nil, true
+	}
+	var allowed []string
+	for _, role := range roles {
+		columns, ok := p.AllowedColumns[normalizeRole(role)]
+		if !ok {
+			continue
+		}
+		if len(columns) == 0 {
+			return nil, true
+		}
+		allowed = append(allowed, columns...)
+	}
+	return normalizeColumns(allowed), false
+}
+
+// accessWarnings reports the columns a select reads that the plan's actor may
+// not, under the policy of every table the plan reads: main is the policy of
+// the query's own table, and joined tables use their registered policies.
+// Without an actor, tables with access rules are denied unless their policy
+// allows anonymous reads.
+func accessWarnings(plan *QueryPlan, main *TablePolicy) []Warning {
+	if plan.Operation != OperationSelect {
+		return nil
+	}
+	actor, hasActor := PlanActor(plan)
+	var warnings []Warning
+	checked := make(map[string]bool)
+	for i, ref := range plan.Tables {
+		name := normalizeTableName(ref.Name)
+		if name == "" || checked[name] {
+			continue
+		}
+		checked[name] = true
+		policy, ok := main, main != nil && normalizeTableName(main.Table) == name
+		if !ok {
+			registered, found := PolicyForTable(name)
+			policy, ok = &registered, found
+		}
+		switch {
+		case !ok || len(policy.AllowedColumns) == 0:
+		case hasActor:
+			warnings = append(warnings, tableAccessWarnings(plan, actor, policy, ref, i == 0)...)
+		case !policy.AllowAnonymous:
+			warnings = append(warnings, policyWarning(
+				WarningTableAccessDenied,
+				policyModeLevel(policy.AccessMode, RiskMedium),
+				fmt.Sprintf("query without an actor may not read %s", policy.Table),
+				"run the query as an actor with WithActor or As, or set AllowAnonymous on the policy",
+				false,
+			))
+		}
+	}
+	return warnings
+}
+
+// tableAccessWarnings checks the columns plan reads from the table ref
+// against policy. main is set for the query's own table.
+func tableAccessWarnings(plan *QueryPlan, actor Actor, policy *TablePolicy, ref TableRef, main bool) []Warning {
+	level := policyModeLevel(policy.AccessMode, RiskMedium)
+	allowed, all := policy.AllowedColumnsFor(actor.Roles)
+	if all {
+		return nil
+	}
+	if len(allowed) == 0 {
+		w := policyWarning(
+			WarningTableAccessDenied,
+			level,
+			fmt.Sprintf("actor %s may not read %s", actorLabel(actor), policy.Table),
+			"run the query as an actor whose role is allowed on this table",
+			false,
+		)
+		w.Evidence = actorEvidence(actor)
+		return []Warning{w}
+	}
+	var warnings []Warning
+	for _, col := range deniedColumns(plan, policy, ref, main, allowed) {
+		w := policyWarning(
+			WarningColumnAccessDenied,
+			level,
+			fmt.Sprintf("actor %s may not read %s.%s", actorLabel(actor), policy.Table, col),
+			"select, filter and sort only on the columns allowed for the actor's roles",
+			false,
+		)
+		w.Evidence = append(actorEvidence(actor), Evidence{Key: "column", Value: col})
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// deniedColumns returns the columns of the table ref outside allowed that
+// plan selects, filters or sorts on. SELECT * and raw select expressions
+// cannot be narrowed, so they are denied; counts are not, since they read no
+// column values. Unqualified columns belong to the main table only, except *
+// which reads every joined table too. The tenant, soft-delete and required
+// filter columns of policy may always be filtered on.
+func deniedColumns(plan *QueryPlan, policy *TablePolicy, ref TableRef, main bool, allowed []string) []string {
+	allow := make(map[string]bool, len(allowed))
+	for _, col := range allowed {
+		allow[normalizeColumnName(col)] = true
+	}
+	filterable := make(map[string]bool)
+	for _, col := range append([]string{policy.TenantColumn, policy.SoftDeleteColumn}, policy.RequiredFilterColumns...) {
+		if col != "" {
+			filterable[normalizeColumnName(col)] = true
+		}
+	}
+
+	var out []string
+	seen := make(map[string]bool)
+	deny := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	// column resolves name to a column of ref, or reports false.
+	column := func(name string) (string, bool) {
+		if !main && !strings.Contains(name, ".") && name != "*" {
+			return "", false
+		}
+		return tableColumn(name, ref)
+	}
+	for _, c := range plan.Columns {
+		switch {
+		case c.Count:
+		case c.Raw:
+			deny(c.Expression)
+		default:
+			if col, ok := column(selectedColumnName(c.Name)); ok && (col == "*" || !allow[col]) {
+				deny(col)
+			}
+		}
+	}
+	for _, p := range plan.Predicates {
+		for _, name := range []string{p.Column, p.ValueColumn} {
+			if name == "" {
+				continue
+			}
+			if col, ok := column(name); ok && !allow[col] && !filterable[col] {
+				deny(col)
+			}
+		}
+	}
+	for _, o := range plan.OrderBy {
+		if o.Column == "" {
+			continue
+		}
+		if col, ok := column(o.Column); ok && !allow[col] {
+			deny(col)
+		}
+	}
+	return out
+}
+
+// selectedColumnName drops an "AS alias" from a selected column.
+func selectedColumnName(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func normalizeRole(role string) string {
+	return strings.ToLower(strings.TrimSpace(role))
+}
+
+func actorLabel(actor Actor) string {
+	if actor.ID != "" {
+		return fmt.Sprintf("%q", actor.ID)
+	}
+	return "with roles [" + strings.Join(actor.Roles, ", ") + "]"
+}
+
+func actorEvidence(actor Actor) []Evidence {
+	evidence := []Evidence{{Key: "actor_roles", Value: append([]string(nil), actor.Roles...)}}
+	if actor.ID != "" {
+		evidence = append([]Evidence{{Key: "actor", Value: actor.ID}}, evidence...)
+	}
+	return evidence
+}
@@ -0,0 +1,133 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultAllowlistFile is the conventional name of the plan allowlist written
+// by goquent plans export.
+const DefaultAllowlistFile = "goquent.allowlist.json"
+
+// ErrPlanNotAllowed is returned for executions whose plan fingerprint is not
+// in the allowlist.
+var ErrPlanNotAllowed = errors.New("goquent: plan fingerprint is not in the allowlist")
+
+// AllowlistEntry describes one allowed plan shape. Only Fingerprint is used
+// for matching; the other fields help reviewers read the file.
+type AllowlistEntry struct {
+	Fingerprint string        `json:"fingerprint"`
+	Operation   OperationType `json:"operation,omitempty"`
+	Tables      []string      `json:"tables,omitempty"`
+	SQL         string        `json:"sql,omitempty"`
+	Source      string        `json:"source,omitempty"`
+}
+
+// NewAllowlistEntry describes plan, recording its normalized SQL.
+func NewAllowlistEntry(plan *QueryPlan, source string) AllowlistEntry {
+	entry := AllowlistEntry{
+		Fingerprint: plan.Fingerprint,
+		Operation:   plan.Operation,
+		SQL:         NormalizeSQL(plan.SQL),
+		Source:      source,
+	}
+	if entry.Fingerprint == "" {
+		entry.Fingerprint = PlanFingerprint(plan)
+	}
+	for _, t := range plan.Tables {
+		entry.Tables = append(entry.Tables, t.Name)
+	}
+	return entry
+}
+
+// Allowlist is the set of plan fingerprints a locked-down DB may execute.
+type Allowlist struct {
+	entries map[string]AllowlistEntry
+}
+
+type allowlistFile struct {
+	Plans []AllowlistEntry `json:"plans"`
+}
+
+// NewAllowlist returns an allowlist of entries. Entries with the same
+// fingerprint are merged, keeping the first.
+func NewAllowlist(entries ...AllowlistEntry) *Allowlist {
+	a := &Allowlist{entries: make(map[string]AllowlistEntry, len(entries))}
+	a.Add(entries...)
+	return a
+}
+
+// Add adds entries that are not yet allowed.
+func (a *Allowlist) Add(entries ...AllowlistEntry) {
+	for _, entry := range entries {
+		entry.Fingerprint = strings.TrimSpace(entry.Fingerprint)
+		if entry.Fingerprint == "" {
+			continue
+		}
+		if _, ok := a.entries[entry.Fingerprint]; !ok {
+			a.entries[entry.Fingerprint] = entry
+		}
+	}
+}
+
+// ParseAllowlist parses an allowlist file.
+func ParseAllowlist(b []byte) (*Allowlist, error) {
+	var file allowlistFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("goquent: parse allowlist: %w", err)
+	}
+	for i, entry := range file.Plans {
+		if strings.TrimSpace(entry.Fingerprint) == "" {
+			return nil, fmt.Errorf("goquent: allowlist plan %d: fingerprint is required", i)
+		}
+	}
+	return NewAllowlist(file.Plans...), nil
+}
+
+// LoadAllowlist reads and parses the allowlist file at path.
+func LoadAllowlist(path string) (*Allowlist, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	a, err := ParseAllowlist(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return a, nil
+}
+
+// Allows reports whether fingerprint is in the allowlist.
+func (a *Allowlist) Allows(fingerprint string) bool {
+	if a == nil || fingerprint == "" {
+		return false
+	}
+	_, ok := a.entries[fingerprint]
+	return ok
+}
+
+// Entries returns the entries sorted by fingerprint.
+func (a *Allowlist) Entries() []AllowlistEntry {
+	if a == nil {
+		return nil
+	}
+	entries := make([]AllowlistEntry, 0, len(a.entries))
+	for _, entry := range a.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Fingerprint < entries[j].Fingerprint })
+	return entries
+}
+
+// ToJSON returns stable, indented JSON for the allowlist file.
+func (a *Allowlist) ToJSON() ([]byte, error) {
+	entries := a.Entries()
+	if entries == nil {
+		entries = []AllowlistEntry{}
+	}
+	return json.MarshalIndent(allowlistFile{Plans: entries}, "", "  ")
+}
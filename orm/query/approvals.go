@@ -0,0 +1,169 @@
+package query
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultApprovalsFile is the conventional name of the approvals file.
+const DefaultApprovalsFile = "goquent.approvals.json"
+
+// ApprovalRecord approves every plan with Fingerprint for execution.
+type ApprovalRecord struct {
+	Fingerprint string     `json:"fingerprint"`
+	Reason      string     `json:"reason"`
+	Approver    string     `json:"approver,omitempty"`
+	Scope       string     `json:"scope,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	KeyID       string     `json:"key_id,omitempty"`
+	Signature   string     `json:"signature,omitempty"`
+}
+
+// Expired reports whether the record has expired at now.
+func (r ApprovalRecord) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !r.ExpiresAt.After(now)
+}
+
+// Approval returns the record as a plan approval bound to its fingerprint.
+func (r ApprovalRecord) Approval() *Approval {
+	a := &Approval{
+		Reason:      r.Reason,
+		Scope:       r.Scope,
+		CreatedBy:   r.Approver,
+		CreatedAt:   r.CreatedAt,
+		Fingerprint: r.Fingerprint,
+		KeyID:       r.KeyID,
+		Signature:   r.Signature,
+	}
+	if r.ExpiresAt != nil {
+		expiresAt := *r.ExpiresAt
+		a.ExpiresAt = &expiresAt
+	}
+	return a
+}
+
+// ApprovalRegistry holds approvals keyed by plan fingerprint, usually loaded
+// from a reviewed goquent.approvals.json file:
+//
+//	{
+//	  "allow_inline": false,
+//	  "approvals": [
+//	    {"fingerprint": "sha256:...", "reason": "nightly cleanup", "approver": "dba", "expires_at": "2026-12-31T00:00:00Z"}
+//	  ]
+//	}
+//
+// With a registry, risky plans execute only when their fingerprint is
+// approved; RequireApproval reasons from code count only when AllowInline is
+// set.
+type ApprovalRegistry struct {
+	AllowInline bool
+	records     []ApprovalRecord
+	byPrint     map[string]ApprovalRecord
+}
+
+type approvalsFile struct {
+	AllowInline bool             `json:"allow_inline"`
+	Approvals   []ApprovalRecord `json:"approvals"`
+}
+
+// NewApprovalRegistry returns a registry of records. Records need a
+// fingerprint and a reason, and a fingerprint may appear only once.
+func NewApprovalRegistry(records ...ApprovalRecord) (*ApprovalRegistry, error) {
+	r := &ApprovalRegistry{byPrint: make(map[string]ApprovalRecord, len(records))}
+	for i, record := range records {
+		record.Fingerprint = strings.TrimSpace(record.Fingerprint)
+		record.Reason = strings.TrimSpace(record.Reason)
+		if record.Fingerprint == "" {
+			return nil, fmt.Errorf("goquent: approval %d: fingerprint is required", i)
+		}
+		if record.Reason == "" {
+			return nil, fmt.Errorf("goquent: approval %s: %w", record.Fingerprint, ErrApprovalReasonRequired)
+		}
+		if _, ok := r.byPrint[record.Fingerprint]; ok {
+			return nil, fmt.Errorf("goquent: approval %s: duplicate fingerprint", record.Fingerprint)
+		}
+		r.byPrint[record.Fingerprint] = record
+		r.records = append(r.records, record)
+	}
+	return r, nil
+}
+
+// ParseApprovals parses an approvals file.
+func ParseApprovals(b []byte) (*ApprovalRegistry, error) {
+	var file approvalsFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("goquent: parse approvals: %w", err)
+	}
+	r, err := NewApprovalRegistry(file.Approvals...)
+	if err != nil {
+		return nil, err
+	}
+	r.AllowInline = file.AllowInline
+	return r, nil
+}
+
+// LoadApprovals reads and parses the approvals file at path.
+func LoadApprovals(path string) (*ApprovalRegistry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := ParseApprovals(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Lookup returns the record approving fingerprint.
+func (r *ApprovalRegistry) Lookup(fingerprint string) (ApprovalRecord, bool) {
+	if r == nil || fingerprint == "" {
+		return ApprovalRecord{}, false
+	}
+	record, ok := r.byPrint[fingerprint]
+	return record, ok
+}
+
+// Records returns the registry's records in file order.
+func (r *ApprovalRegistry) Records() []ApprovalRecord {
+	if r == nil {
+		return nil
+	}
+	return append([]ApprovalRecord(nil), r.records...)
+}
+
+// WithApprovalRegistry makes executions of the query check risky plans
+// against r instead of accepting any RequireApproval reason.
+func (q *Query) WithApprovalRegistry(r *ApprovalRegistry) *Query {
+	q = q.derive()
+	q.approvals = r
+	return q
+}
+
+// EnsurePlanExecutable enforces approval and block rules for a finalized plan
+// against the registry. A risky plan whose fingerprint has an unexpired
+// record is executable and gets the record as plan.Approval; other risky
+// plans are refused unless AllowInline is set and they carry an inline
+// approval. With trusted keys, the approval must also be signed by one of
+// them. A nil registry behaves like the package EnsurePlanExecutable.
+func (r *ApprovalRegistry) EnsurePlanExecutable(plan *QueryPlan, trusted ...ed25519.PublicKey) error {
+	if r == nil || plan == nil || plan.Blocked || !plan.RequiredApproval {
+		return ensurePlanExecutable(plan, trusted)
+	}
+	if record, ok := r.Lookup(plan.Fingerprint); ok {
+		if record.Expired(time.Now().UTC()) {
+			return fmt.Errorf("%w: approval for %s expired", ErrApprovalRequired, plan.Fingerprint)
+		}
+		plan.Approval = record.Approval()
+		return ensureSignedApproval(plan.Approval, plan.Fingerprint, trusted)
+	}
+	if !r.AllowInline {
+		return fmt.Errorf("%w: %s: fingerprint %s is not in the approval registry", ErrApprovalRequired, warningCodes(plan.Warnings), plan.Fingerprint)
+	}
+	return ensurePlanExecutable(plan, trusted)
+}
@@ -0,0 +1,116 @@
+package query
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	ormdriver "github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/internal/pgarray"
+)
+
+// PostgresArrayIn makes WhereIn and WhereNotIn with []string or []int64
+// values compile to "= ANY($1)" / "<> ALL($1)" with a single array parameter
+// on PostgreSQL. It has no effect on other dialects.
+func (q *Query) PostgresArrayIn() *Query {
+	q = q.derive()
+	q.arrayIn = true
+	return q
+}
+
+// WhereArrayContains matches rows whose array column contains every value in
+// vals (col @> vals). PostgreSQL only.
+func (q *Query) WhereArrayContains(col string, vals any) *Query {
+	q = q.derive()
+	return q.whereArrayOperator(col, "@>", "array_contains", vals)
+}
+
+// WhereArrayOverlaps matches rows whose array column shares at least one
+// value with vals (col && vals). PostgreSQL only.
+func (q *Query) WhereArrayOverlaps(col string, vals any) *Query {
+	q = q.derive()
+	return q.whereArrayOperator(col, "&&", "array_overlaps", vals)
+}
+
+func (q *Query) whereArrayOperator(col, op, function string, vals any) *Query {
+	if q.err != nil {
+		return q
+	}
+	if _, ok := q.dialect.(ormdriver.PostgresDialect); !ok {
+		q.err = fmt.Errorf("goquent: %s requires PostgreSQL", function)
+		return q
+	}
+	if err := validateSelectColumn(col); err != nil {
+		q.err = err
+		return q
+	}
+	literal, ok := pgarray.Value(vals)
+	if !ok {
+		q.err = fmt.Errorf("goquent: %s supports []string and []int64 values, got %T", function, vals)
+		return q
+	}
+	name := fmt.Sprintf("__goquent_array_%d", len(q.generatedRefs))
+	raw := quoteIdentifierPath(q.dialect, col) + " " + op + " :" + name
+	q.builder.WhereRaw(raw, map[string]any{name: literal})
+	q.recordGeneratedRef(raw, generatedRef{column: col, operator: op, function: function, valueCount: 1})
+	return q
+}
+
+// whereArrayIn renders an IN list as a single array parameter when
+// PostgresArrayIn is enabled. It reports whether it handled the predicate.
+func (q *Query) whereArrayIn(col string, vals any, negate, or bool) bool {
+	if !q.arrayIn {
+		return false
+	}
+	if q.err != nil {
+		return true
+	}
+	if _, ok := q.dialect.(ormdriver.PostgresDialect); !ok {
+		return false
+	}
+	literal, ok := pgarray.Value(vals)
+	if !ok {
+		return false
+	}
+	if err := validateSelectColumn(col); err != nil {
+		q.err = err
+		return true
+	}
+	name := fmt.Sprintf("__goquent_array_%d", len(q.generatedRefs))
+	operator, raw := "IN", quoteIdentifierPath(q.dialect, col)+" = ANY(:"+name+")"
+	if negate {
+		operator, raw = "NOT IN", quoteIdentifierPath(q.dialect, col)+" <> ALL(:"+name+")"
+	}
+	if or {
+		q.builder.OrWhereRaw(raw, map[string]any{name: literal})
+	} else {
+		q.builder.WhereRaw(raw, map[string]any{name: literal})
+	}
+	q.recordGeneratedRef(raw, generatedRef{column: col, operator: operator, function: "any", valueCount: 1})
+	return true
+}
+
+// BindArrayArgs converts []string and []int64 arguments to PostgreSQL array
+// literals so drivers without native slice support can bind them.
+func BindArrayArgs(d ormdriver.Dialect, args []any) []any {
+	if _, ok := d.(ormdriver.PostgresDialect); !ok {
+		return args
+	}
+	var out []any
+	for i, arg := range args {
+		if _, ok := arg.(driver.Valuer); ok {
+			continue
+		}
+		literal, ok := pgarray.Value(arg)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]any(nil), args...)
+		}
+		out[i] = literal
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
@@ -0,0 +1,240 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	WarningNPlusOneSuspected   = "N_PLUS_ONE_SUSPECTED"
+	WarningQueryBudgetExceeded = "QUERY_BUDGET_EXCEEDED"
+	budgetLimitQueries         = "queries"
+	budgetLimitRows            = "rows"
+	budgetLimitDuplicateShapes = "duplicate_shapes"
+)
+
+// ErrQueryBudgetExceeded is returned for executions refused by a Budget with
+// FailWhenExceeded.
+var ErrQueryBudgetExceeded = errors.New("goquent: query budget exceeded")
+
+// Budget limits the executions run with a context returned by
+// WithQueryBudget. A zero limit is unlimited.
+type Budget struct {
+	// MaxQueries is the number of executions allowed.
+	MaxQueries int
+	// MaxRows is the number of rows allowed, counting rows scanned by reads
+	// and affected by writes. Rows returned unread as *sql.Rows are not
+	// counted.
+	MaxRows int64
+	// MaxDuplicateShapes is the number of times one plan fingerprint may run
+	// before N_PLUS_ONE_SUSPECTED is reported.
+	MaxDuplicateShapes int
+	// FailWhenExceeded refuses executions with ErrQueryBudgetExceeded before
+	// any SQL is sent: those that would pass MaxQueries or
+	// MaxDuplicateShapes, and every execution after MaxRows was passed.
+	// Otherwise limits are only reported.
+	FailWhenExceeded bool
+	// OnEvent, when set, is called once per fingerprint for
+	// N_PLUS_ONE_SUSPECTED and once per limit for QUERY_BUDGET_EXCEEDED.
+	OnEvent func(ctx context.Context, event BudgetEvent)
+}
+
+// BudgetEvent reports a limit of a Budget being passed.
+type BudgetEvent struct {
+	Code string `json:"code"`
+	// Limit is "queries", "rows" or "duplicate_shapes".
+	Limit       string `json:"limit"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	SQL         string `json:"sql,omitempty"`
+	Count       int64  `json:"count"`
+	Max         int64  `json:"max"`
+}
+
+// DuplicateShape is a plan fingerprint executed more than once.
+type DuplicateShape struct {
+	Fingerprint string `json:"fingerprint"`
+	SQL         string `json:"sql"`
+	Count       int    `json:"count"`
+	// Suspected is set when Count is above Budget.MaxDuplicateShapes.
+	Suspected bool `json:"n_plus_one_suspected,omitempty"`
+}
+
+// BudgetReport summarizes the executions counted by a QueryBudget.
+type BudgetReport struct {
+	Queries    int              `json:"queries"`
+	Rows       int64            `json:"rows"`
+	Duplicates []DuplicateShape `json:"duplicates,omitempty"`
+	Events     []BudgetEvent    `json:"events,omitempty"`
+	// Refused counts executions refused by FailWhenExceeded.
+	Refused  int  `json:"refused,omitempty"`
+	Exceeded bool `json:"exceeded"`
+}
+
+// QueryBudget counts the executions run with its context. It is safe for
+// concurrent use.
+type QueryBudget struct {
+	budget Budget
+
+	mu      sync.Mutex
+	queries int
+	rows    int64
+	refused int
+	shapes  map[string]*DuplicateShape
+	order   []string
+	events  []BudgetEvent
+	// passed records reported events by budgetEventKey.
+	passed map[string]bool
+}
+
+type queryBudgetKey struct{}
+
+// WithQueryBudget returns a context that counts every execution run with it
+// against b, by plan fingerprint, and the budget to read the report from.
+// Middleware typically installs one per request and logs Report when the
+// request ends.
+//
+//	ctx, budget := query.WithQueryBudget(r.Context(), query.Budget{MaxQueries: 50, MaxDuplicateShapes: 5})
+//	next.ServeHTTP(w, r.WithContext(ctx))
+//	if report := budget.Report(); report.Exceeded {
+//		logger.Warn("query budget exceeded", "report", report)
+//	}
+func WithQueryBudget(ctx context.Context, b Budget) (context.Context, *QueryBudget) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	budget := &QueryBudget{budget: b, shapes: make(map[string]*DuplicateShape), passed: make(map[string]bool)}
+	return context.WithValue(ctx, queryBudgetKey{}, budget), budget
+}
+
+// QueryBudgetFromContext returns the budget installed by WithQueryBudget, or
+// nil.
+func QueryBudgetFromContext(ctx context.Context) *QueryBudget {
+	if ctx == nil {
+		return nil
+	}
+	budget, _ := ctx.Value(queryBudgetKey{}).(*QueryBudget)
+	return budget
+}
+
+// Report returns the executions counted so far.
+func (b *QueryBudget) Report() BudgetReport {
+	if b == nil {
+		return BudgetReport{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	report := BudgetReport{
+		Queries:  b.queries,
+		Rows:     b.rows,
+		Events:   append([]BudgetEvent(nil), b.events...),
+		Refused:  b.refused,
+		Exceeded: len(b.events) > 0 || b.refused > 0,
+	}
+	for _, fingerprint := range b.order {
+		if shape := b.shapes[fingerprint]; shape.Count > 1 {
+			report.Duplicates = append(report.Duplicates, *shape)
+		}
+	}
+	sort.SliceStable(report.Duplicates, func(i, j int) bool {
+		return report.Duplicates[i].Count > report.Duplicates[j].Count
+	})
+	return report
+}
+
+// begin counts plan as executed, or refuses it when FailWhenExceeded is set
+// and running it would pass a limit, or the row limit was already passed.
+func (b *QueryBudget) begin(ctx context.Context, plan *QueryPlan) error {
+	fingerprint := plan.Fingerprint
+	if fingerprint == "" {
+		fingerprint = PlanFingerprint(plan)
+	}
+	b.mu.Lock()
+	shape := b.shapes[fingerprint]
+	if b.budget.FailWhenExceeded {
+		var event BudgetEvent
+		switch {
+		case b.budget.MaxQueries > 0 && b.queries >= b.budget.MaxQueries:
+			event = BudgetEvent{Code: WarningQueryBudgetExceeded, Limit: budgetLimitQueries, Count: int64(b.queries), Max: int64(b.budget.MaxQueries)}
+		case b.budget.MaxRows > 0 && b.rows > b.budget.MaxRows:
+			event = BudgetEvent{Code: WarningQueryBudgetExceeded, Limit: budgetLimitRows, Count: b.rows, Max: b.budget.MaxRows}
+		case b.budget.MaxDuplicateShapes > 0 && shape != nil && shape.Count >= b.budget.MaxDuplicateShapes:
+			event = BudgetEvent{Code: WarningNPlusOneSuspected, Limit: budgetLimitDuplicateShapes, Count: int64(shape.Count), Max: int64(b.budget.MaxDuplicateShapes)}
+			shape.Suspected = true
+		}
+		if event.Code != "" {
+			b.refused++
+			var events []BudgetEvent
+			if key := budgetEventKey(event, fingerprint); !b.passed[key] {
+				b.passed[key] = true
+				event.Fingerprint, event.SQL = fingerprint, plan.SQL
+				events = append(events, event)
+				b.events = append(b.events, event)
+			}
+			b.mu.Unlock()
+			b.emit(ctx, events)
+			return fmt.Errorf("%w: %s limit reached by %s %s", ErrQueryBudgetExceeded, event.Limit, plan.Operation, fingerprint)
+		}
+	}
+	if shape == nil {
+		shape = &DuplicateShape{Fingerprint: fingerprint, SQL: plan.SQL}
+		b.shapes[fingerprint] = shape
+		b.order = append(b.order, fingerprint)
+	}
+	shape.Count++
+	b.queries++
+
+	var events []BudgetEvent
+	if max := b.budget.MaxDuplicateShapes; max > 0 && shape.Count > max {
+		shape.Suspected = true
+		if !b.passed[fingerprint] {
+			b.passed[fingerprint] = true
+			events = append(events, BudgetEvent{Code: WarningNPlusOneSuspected, Limit: budgetLimitDuplicateShapes, Fingerprint: fingerprint, SQL: plan.SQL, Count: int64(shape.Count), Max: int64(max)})
+		}
+	}
+	if max := b.budget.MaxQueries; max > 0 && b.queries > max && !b.passed[budgetLimitQueries] {
+		b.passed[budgetLimitQueries] = true
+		events = append(events, BudgetEvent{Code: WarningQueryBudgetExceeded, Limit: budgetLimitQueries, Fingerprint: fingerprint, SQL: plan.SQL, Count: int64(b.queries), Max: int64(max)})
+	}
+	b.events = append(b.events, events...)
+	b.mu.Unlock()
+	b.emit(ctx, events)
+	return nil
+}
+
+// finish adds the rows of an execution, when known.
+func (b *QueryBudget) finish(ctx context.Context, plan *QueryPlan, rows int64) {
+	if rows <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.rows += rows
+	var events []BudgetEvent
+	if max := b.budget.MaxRows; max > 0 && b.rows > max && !b.passed[budgetLimitRows] {
+		b.passed[budgetLimitRows] = true
+		events = append(events, BudgetEvent{Code: WarningQueryBudgetExceeded, Limit: budgetLimitRows, Fingerprint: plan.Fingerprint, SQL: plan.SQL, Count: b.rows, Max: max})
+	}
+	b.events = append(b.events, events...)
+	b.mu.Unlock()
+	b.emit(ctx, events)
+}
+
+// budgetEventKey identifies an event that is reported once: per
+// fingerprint for duplicate shapes and per limit otherwise.
+func budgetEventKey(event BudgetEvent, fingerprint string) string {
+	if event.Limit == budgetLimitDuplicateShapes {
+		return fingerprint
+	}
+	return event.Limit
+}
+
+func (b *QueryBudget) emit(ctx context.Context, events []BudgetEvent) {
+	if b.budget.OnEvent == nil {
+		return
+	}
+	for _, event := range events {
+		b.budget.OnEvent(ctx, event)
+	}
+}
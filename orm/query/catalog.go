@@ -0,0 +1,231 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faciam-dev/goquent/orm/driver"
+)
+
+// CatalogQueryMetadataKey is the QueryPlan.Metadata key holding the name of
+// the catalog query a plan was built from.
+const CatalogQueryMetadataKey = "catalog_query"
+
+// Catalog query result kinds, taken from the `-- name: X :kind` annotation.
+const (
+	CatalogOne  = "one"
+	CatalogMany = "many"
+	CatalogExec = "exec"
+)
+
+// CatalogEntry is one named query parsed from a SQL file:
+//
+//	-- name: GetActiveUsers :many
+//	-- goquent:approve reason="monthly report reviewed"
+//	-- goquent:suppress LIMIT_MISSING reason="bounded by the date range"
+//	SELECT id, name FROM users WHERE active = :active;
+//
+// Suppressions and approvals written in the entry's comments apply to the
+// whole entry and to no other entry.
+type CatalogEntry struct {
+	Name         string
+	Kind         string
+	SQL          string
+	Location     SourceLocation
+	Suppressions []Suppression
+	Approval     *Approval
+}
+
+// IsCatalog reports whether src is laid out as a catalog: it has at least one
+// `-- name:` annotation, every annotation is well-formed, and no SQL appears
+// before the first one. Migrations or ad-hoc scripts that merely contain a
+// `-- name:` comment are not catalogs.
+func IsCatalog(src string) bool {
+	found := false
+	for _, line := range strings.Split(src, "\n") {
+		_, _, ok, err := parseCatalogName(line)
+		if err != nil {
+			return false
+		}
+		if ok {
+			found = true
+			continue
+		}
+		if trimmed := strings.TrimSpace(line); !found && trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+			return false
+		}
+	}
+	return found
+}
+
+// ParseCatalog parses the named queries in a SQL file. Text before the first
+// `-- name:` annotation is ignored. file is only used for locations.
+func ParseCatalog(file, src string) ([]CatalogEntry, error) {
+	var (
+		entries []CatalogEntry
+		cur     *CatalogEntry
+		body    []string
+	)
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		cur.SQL = catalogSQL(body)
+		entries = append(entries, *cur)
+		cur, body = nil, nil
+	}
+	for i, line := range strings.Split(src, "\n") {
+		lineNo := i + 1
+		name, kind, ok, err := parseCatalogName(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, lineNo, err)
+		}
+		if ok {
+			flush()
+			for _, e := range entries {
+				if e.Name == name {
+					return nil, fmt.Errorf("%s:%d: goquent: duplicate catalog query %q", file, lineNo, name)
+				}
+			}
+			cur = &CatalogEntry{Name: name, Kind: kind, Location: SourceLocation{File: file, Line: lineNo}}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		body = append(body, line)
+		suppression, ok, err := ParseInlineSuppression(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, lineNo, err)
+		}
+		if ok {
+			suppression.Scope = SuppressionScopeQuery
+			suppression.Location = &SourceLocation{File: file, Line: lineNo}
+			cur.Suppressions = append(cur.Suppressions, suppression)
+		}
+		approval, ok, err := ParseInlineApproval(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, lineNo, err)
+		}
+		if ok {
+			cur.Approval = &approval
+		}
+	}
+	flush()
+	for _, e := range entries {
+		if e.SQL == "" {
+			return nil, fmt.Errorf("%s:%d: goquent: catalog query %q has no SQL", file, e.Location.Line, e.Name)
+		}
+	}
+	return entries, nil
+}
+
+// ParseInlineApproval parses comments like:
+// goquent:approve reason="monthly report reviewed" by="dba" expires="2026-07-01"
+func ParseInlineApproval(comment string) (Approval, bool, error) {
+	const marker = "goquent:approve"
+	idx := strings.Index(comment, marker)
+	if idx < 0 {
+		return Approval{}, false, nil
+	}
+	tokens, err := splitSuppressionTokens(comment[idx+len(marker):])
+	if err != nil {
+		return Approval{}, true, err
+	}
+	a := Approval{Scope: string(SuppressionScopeInline)}
+	for _, token := range tokens {
+		key, value, ok := strings.Cut(token, "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(strings.TrimSpace(value)); err == nil {
+			value = unquoted
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "reason":
+			a.Reason = value
+		case "by":
+			a.CreatedBy = value
+		case "expires":
+			expiresAt, err := parseSuppressionTime(value)
+			if err != nil {
+				return Approval{}, true, err
+			}
+			a.ExpiresAt = &expiresAt
+		}
+	}
+	if a.Reason == "" {
+		return Approval{}, true, ErrApprovalReasonRequired
+	}
+	return a, true, nil
+}
+
+// Plan builds the entry's raw plan, binding named parameters from params
+// (see BindNamed) and applying the entry's suppressions and approval. The
+// plan records the entry name under CatalogQueryMetadataKey.
+func (e CatalogEntry) Plan(d driver.Dialect, params any) (*QueryPlan, error) {
+	sqlStr, args, names, err := BindNamed(d, e.SQL, params)
+	if err != nil {
+		return nil, fmt.Errorf("goquent: catalog query %q: %w", e.Name, err)
+	}
+	plan := newQueryPlan(OperationRaw, sqlStr, args)
+	plan.Metadata = map[string]any{CatalogQueryMetadataKey: e.Name}
+	if len(names) > 0 {
+		plan.Metadata[NamedParamsMetadataKey] = names
+	}
+	var approval *Approval
+	if e.Approval != nil {
+		copied := *e.Approval
+		if copied.CreatedAt.IsZero() {
+			copied.CreatedAt = time.Now().UTC()
+		}
+		approval = &copied
+	}
+	finalizePlan(plan, approval, e.Suppressions)
+	return plan, nil
+}
+
+func parseCatalogName(line string) (string, string, bool, error) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "--") {
+		return "", "", false, nil
+	}
+	rest, ok := strings.CutPrefix(strings.TrimSpace(trimmed[2:]), "name:")
+	if !ok {
+		return "", "", false, nil
+	}
+	fields := strings.Fields(rest)
+	if len(fields) != 2 || !strings.HasPrefix(fields[1], ":") {
+		return "", "", true, fmt.Errorf("goquent: catalog annotation must be `-- name: Name :one|:many|:exec`")
+	}
+	name, kind := fields[0], strings.TrimPrefix(fields[1], ":")
+	for i := 0; i < len(name); i++ {
+		if !isNamedParamByte(name[i], i == 0) {
+			return "", "", true, fmt.Errorf("goquent: invalid catalog query name %q", name)
+		}
+	}
+	switch kind {
+	case CatalogOne, CatalogMany, CatalogExec:
+		return name, kind, true, nil
+	default:
+		return "", "", true, fmt.Errorf("goquent: unknown catalog query kind %q for %s", fields[1], name)
+	}
+}
+
+// catalogSQL drops the entry's leading comment lines and a trailing
+// semicolon from its body.
+func catalogSQL(lines []string) string {
+	start := 0
+	for start < len(lines) {
+		trimmed := strings.TrimSpace(lines[start])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+			break
+		}
+		start++
+	}
+	sqlStr := strings.TrimSpace(strings.Join(lines[start:], "\n"))
+	return strings.TrimSpace(strings.TrimSuffix(sqlStr, ";"))
+}
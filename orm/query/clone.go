@@ -0,0 +1,192 @@
+package query
+
+import (
+	"maps"
+	"reflect"
+	"unsafe"
+
+	qbapi "github.com/faciam-dev/goquent-query-builder/api"
+)
+
+// Clone returns an independent copy of q. The builder state (selects, joins,
+// where groups, unions, ordering, grouping, limits and locks), approval,
+// suppressions, policy flags, actor and context are copied, so changing the clone
+// never affects q and vice versa. The executor and dialect are shared.
+func (q *Query) Clone() *Query {
+	c := *q
+	c.builder = cloneSelectBuilder(q.builder)
+	if q.approval != nil {
+		approval := *q.approval
+		c.approval = &approval
+	}
+	c.suppressions = append([]Suppression(nil), q.suppressions...)
+	if q.policy != nil {
+		policy := *q.policy
+		policy.PIIColumns = append([]string(nil), q.policy.PIIColumns...)
+		policy.RequiredFilterColumns = append([]string(nil), q.policy.RequiredFilterColumns...)
+		policy.ImmutableColumns = append([]string(nil), q.policy.ImmutableColumns...)
+		policy.InsertOnlyColumns = append([]string(nil), q.policy.InsertOnlyColumns...)
+		policy.AllowedColumns = cloneAllowedColumns(q.policy.AllowedColumns)
+		c.policy = &policy
+	}
+	if q.actor != nil {
+		actor := cloneActor(*q.actor)
+		c.actor = &actor
+	}
+	if q.lock != nil {
+		lock := *q.lock
+		lock.of = append([]string(nil), q.lock.of...)
+		c.lock = &lock
+	}
+	c.generatedRefs = maps.Clone(q.generatedRefs)
+	return &c
+}
+
+// Immutable switches q to immutable mode: every subsequent builder call
+// returns a new *Query and leaves its receiver unchanged, so a base query can
+// be branched safely.
+//
+//	base := db.Table("users").Where("tenant_id", tenantID).Immutable()
+//	total, err := base.Count()
+//	page := base.OrderBy("id", "asc").Limit(20)
+func (q *Query) Immutable() *Query {
+	q.immutable = true
+	return q
+}
+
+// derive returns the query a builder call should modify: q itself, or a
+// clone when q is immutable.
+func (q *Query) derive() *Query {
+	if !q.immutable {
+		return q
+	}
+	return q.Clone()
+}
+
+// cloneSelectBuilder deep-copies a select builder, including its SQL
+// strategy state. goquent-query-builder does not expose a cloning API, so the
+// object graph is copied with reflection; pointers shared inside the graph
+// (for example the where builder's parent link) stay shared in the copy.
+func cloneSelectBuilder(b *qbapi.SelectQueryBuilder) *qbapi.SelectQueryBuilder {
+	if b == nil {
+		return nil
+	}
+	c := graphCopier{seen: make(map[graphKey]reflect.Value)}
+	out := reflect.New(reflect.TypeOf(b)).Elem()
+	c.copy(out, reflect.ValueOf(b))
+	return out.Interface().(*qbapi.SelectQueryBuilder)
+}
+
+// countSelectBuilder returns a deep copy of b without its projection, limit,
+// offset and unions, keeping the table, where, join, order, group and lock
+// clauses a COUNT aggregate is evaluated against.
+func countSelectBuilder(b *qbapi.SelectQueryBuilder) *qbapi.SelectQueryBuilder {
+	c := cloneSelectBuilder(b)
+	sel := accessible(reflect.ValueOf(c).Elem().FieldByName("builder")).Elem().FieldByName("selectQuery")
+	sel = accessible(sel).Elem()
+	for _, name := range []string{"Columns", "Union"} {
+		f := sel.FieldByName(name)
+		f.Set(reflect.New(f.Type().Elem()))
+	}
+	for _, name := range []string{"Limit", "Offset"} {
+		f := sel.FieldByName(name)
+		f.Set(reflect.Zero(f.Type()))
+	}
+	return c
+}
+
+type graphKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type graphCopier struct {
+	seen map[graphKey]reflect.Value
+}
+
+// copy stores a deep copy of src into dst. dst must be settable.
+func (c graphCopier) copy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		key := graphKey{ptr: src.Pointer(), typ: src.Type()}
+		if v, ok := c.seen[key]; ok {
+			dst.Set(v)
+			return
+		}
+		n := reflect.New(src.Type().Elem())
+		c.seen[key] = n
+		c.copy(n.Elem(), accessible(src.Elem()))
+		dst.Set(n)
+	case reflect.Struct:
+		src = addressable(src)
+		for i := 0; i < src.NumField(); i++ {
+			c.copy(accessible(dst.Field(i)), accessible(src.Field(i)))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		n := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			c.copy(n.Index(i), accessible(src.Index(i)))
+		}
+		dst.Set(n)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(accessible(dst.Index(i)), accessible(addressable(src).Index(i)))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		key := graphKey{ptr: src.Pointer(), typ: src.Type()}
+		if v, ok := c.seen[key]; ok {
+			dst.Set(v)
+			return
+		}
+		n := reflect.MakeMapWithSize(src.Type(), src.Len())
+		c.seen[key] = n
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(src.Type().Key()).Elem()
+			c.copy(k, iter.Key())
+			v := reflect.New(src.Type().Elem()).Elem()
+			c.copy(v, iter.Value())
+			n.SetMapIndex(k, v)
+		}
+		dst.Set(n)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := src.Elem()
+		v := reflect.New(elem.Type()).Elem()
+		c.copy(v, accessible(elem))
+		dst.Set(v)
+	default:
+		// Scalars, strings, funcs and channels are copied by value.
+		dst.Set(src)
+	}
+}
+
+// accessible lifts the read-only flag reflect sets on values reached through
+// unexported fields so they can be read and written.
+func accessible(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+	}
+	return v
+}
+
+// addressable returns an addressable copy of v when v is not addressable.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	tmp := reflect.New(v.Type()).Elem()
+	tmp.Set(v)
+	return tmp
+}
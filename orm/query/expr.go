@@ -0,0 +1,220 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/faciam-dev/goquent/orm/driver"
+)
+
+// Expr is a SQL expression used as a write value instead of a bound literal.
+//
+// SQL uses ? for bound values regardless of dialect; placeholders are
+// renumbered for the target dialect when the statement is built. Expressions
+// are validated like other raw fragments and may not contain statement
+// separators, comments, or DDL/DML keywords.
+type Expr struct {
+	SQL  string
+	Args []any
+
+	function string
+}
+
+// NewExpr returns an expression value for Update maps and upsert assignments.
+func NewExpr(sqlStr string, args ...any) Expr {
+	return Expr{SQL: sqlStr, Args: args}
+}
+
+// Validate reports whether the expression is a safe single SQL fragment.
+// Placeholders are counted with standard SQL string quoting; Render counts
+// them again for its dialect.
+func (e Expr) Validate() error {
+	return e.validate(false)
+}
+
+func (e Expr) validate(backslash bool) error {
+	if err := validateRawSQLFragment(e.SQL); err != nil {
+		return err
+	}
+	if got := countPositionalPlaceholders(e.SQL, backslash); got != len(e.Args) {
+		return fmt.Errorf("goquent: expression placeholder count does not match args: %d != %d", got, len(e.Args))
+	}
+	for _, arg := range e.Args {
+		if _, ok := arg.(Expr); ok {
+			return fmt.Errorf("goquent: expressions cannot be nested")
+		}
+	}
+	return nil
+}
+
+// Render returns the expression SQL with placeholders numbered from start for d.
+func (e Expr) Render(d driver.Dialect, start int) (string, []any, error) {
+	backslash := backslashEscapes(d)
+	if err := e.validate(backslash); err != nil {
+		return "", nil, err
+	}
+	n := start
+	out, err := rewriteSQL(strings.TrimSpace(e.SQL), backslash, func(s string, i int) (string, int, bool, error) {
+		if s[i] != '?' {
+			return "", 0, false, nil
+		}
+		ph := d.Placeholder(n)
+		n++
+		return ph, i + 1, true, nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return out, append([]any(nil), e.Args...), nil
+}
+
+// Increment atomically adds n to col and applies extra column values.
+func (q *Query) Increment(col string, n any, extra map[string]any) (sql.Result, error) {
+	data, err := q.adjustData(col, n, extra, "+", "increment")
+	if err != nil {
+		return nil, err
+	}
+	return q.Update(data)
+}
+
+// Decrement atomically subtracts n from col and applies extra column values.
+func (q *Query) Decrement(col string, n any, extra map[string]any) (sql.Result, error) {
+	data, err := q.adjustData(col, n, extra, "-", "decrement")
+	if err != nil {
+		return nil, err
+	}
+	return q.Update(data)
+}
+
+func (q *Query) adjustData(col string, n any, extra map[string]any, op, function string) (map[string]any, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if err := validateSelectColumn(col); err != nil {
+		return nil, err
+	}
+	if !isNumericValue(n) {
+		return nil, fmt.Errorf("goquent: %s amount must be numeric, got %T", function, n)
+	}
+	if _, ok := extra[col]; ok {
+		return nil, fmt.Errorf("goquent: %s column %q cannot also be set in extra values", function, col)
+	}
+	data := make(map[string]any, len(extra)+1)
+	for k, v := range extra {
+		data[k] = v
+	}
+	data[col] = Expr{
+		SQL:      quoteIdentifierPath(q.dialect, col) + " " + op + " ?",
+		Args:     []any{n},
+		function: function,
+	}
+	return data, nil
+}
+
+func isNumericValue(v any) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.String:
+		_, err := strconv.ParseFloat(rv.String(), 64)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// expandExprArgs replaces bound Expr values in a built statement with their
+// SQL and renumbers all placeholders for the dialect.
+func expandExprArgs(d driver.Dialect, sqlStr string, args []any) (string, []any, error) {
+	hasExpr := false
+	for _, arg := range args {
+		if _, ok := arg.(Expr); ok {
+			hasExpr = true
+			break
+		}
+	}
+	if !hasExpr {
+		return sqlStr, args, nil
+	}
+
+	_, numbered := d.(driver.PostgresDialect)
+	out := make([]any, 0, len(args))
+	next := 0
+	expanded, err := rewriteSQL(sqlStr, backslashEscapes(d), func(s string, i int) (string, int, bool, error) {
+		var arg any
+		end := i + 1
+		switch {
+		case !numbered && s[i] == '?':
+			if next >= len(args) {
+				return "", 0, false, fmt.Errorf("goquent: placeholder count exceeds args")
+			}
+			arg = args[next]
+			next++
+		case numbered && s[i] == '$' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+				end++
+			}
+			idx, err := strconv.Atoi(s[i+1 : end])
+			if err != nil || idx < 1 || idx > len(args) {
+				return "", 0, false, fmt.Errorf("goquent: placeholder %s has no matching arg", s[i:end])
+			}
+			arg = args[idx-1]
+		default:
+			return "", 0, false, nil
+		}
+		if e, ok := arg.(Expr); ok {
+			rendered, exprArgs, err := e.Render(d, len(out)+1)
+			if err != nil {
+				return "", 0, false, err
+			}
+			out = append(out, exprArgs...)
+			return rendered, end, true, nil
+		}
+		out = append(out, arg)
+		return d.Placeholder(len(out)), end, true, nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return expanded, out, nil
+}
+
+func exprColumnRefs(m map[string]any) []ColumnRef {
+	keys := sortedMapKeys(m)
+	refs := make([]ColumnRef, 0, len(keys))
+	for _, k := range keys {
+		ref := ColumnRef{Name: k}
+		if e, ok := m[k].(Expr); ok {
+			ref.Expression = strings.TrimSpace(e.SQL)
+			ref.Function = e.function
+			ref.Raw = e.function == ""
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// PlanIncrement builds an increment UPDATE plan without executing it.
+func (q *Query) PlanIncrement(ctx context.Context, col string, n any, extra map[string]any) (*QueryPlan, error) {
+	data, err := q.adjustData(col, n, extra, "+", "increment")
+	if err != nil {
+		return nil, err
+	}
+	return q.PlanUpdate(ctx, data)
+}
+
+// PlanDecrement builds a decrement UPDATE plan without executing it.
+func (q *Query) PlanDecrement(ctx context.Context, col string, n any, extra map[string]any) (*QueryPlan, error) {
+	data, err := q.adjustData(col, n, extra, "-", "decrement")
+	if err != nil {
+		return nil, err
+	}
+	return q.PlanUpdate(ctx, data)
+}
@@ -0,0 +1,144 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/faciam-dev/goquent/orm/internal/stringutil"
+)
+
+// When calls fn with the query when cond is true. fn modifies the query in
+// place, so it works the same way in immutable mode.
+//
+//	q.When(req.Status != "", func(q *query.Query) { q.Where("status", req.Status) })
+func (q *Query) When(cond bool, fn func(*Query)) *Query {
+	if !cond || fn == nil {
+		return q
+	}
+	return q.Tap(fn)
+}
+
+// Unless calls fn with the query when cond is false.
+func (q *Query) Unless(cond bool, fn func(*Query)) *Query {
+	return q.When(!cond, fn)
+}
+
+// Tap calls fn with the query and returns it, keeping a chain intact while
+// applying conditional or helper logic.
+func (q *Query) Tap(fn func(*Query)) *Query {
+	q = q.derive()
+	if fn == nil {
+		return q
+	}
+	immutable := q.immutable
+	q.immutable = false
+	fn(q)
+	q.immutable = immutable
+	return q
+}
+
+// Filter adds a WHERE predicate for every set field of the filter struct v.
+// Only fields with a `filter` tag are used:
+//
+//	type UserFilter struct {
+//	    Status *string  `filter:"status"`
+//	    MinAge *int     `filter:"age,op=>="`
+//	    Name   *string  `filter:"name,op=LIKE"`
+//	    Roles  []string `filter:"role,op=in"`
+//	}
+//
+// Nil pointers, empty slices and other zero values are skipped. The column
+// defaults to the snake_case field name and the operator to "="; operators
+// are validated like Where, plus "in" and "not in" for slice fields.
+func (q *Query) Filter(v any) *Query {
+	return q.Tap(func(q *Query) { q.applyFilter(v) })
+}
+
+func (q *Query) applyFilter(v any) {
+	if q.err != nil {
+		return
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		q.err = fmt.Errorf("goquent: Filter expects a struct, got %T", v)
+		return
+	}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("filter")
+		if !ok || tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+		col, op, err := parseFilterTag(tag, sf.Name)
+		if err != nil {
+			q.err = err
+			return
+		}
+		fv := rv.Field(i)
+		if fv.IsZero() {
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Slice && fv.Len() == 0 {
+			continue
+		}
+		switch strings.ToUpper(op) {
+		case "IN", "NOT IN":
+			if fv.Kind() != reflect.Slice {
+				q.err = fmt.Errorf("goquent: filter field %s uses %s but is not a slice", sf.Name, op)
+				return
+			}
+			if strings.EqualFold(op, "IN") {
+				q.WhereIn(col, fv.Interface())
+			} else {
+				q.WhereNotIn(col, fv.Interface())
+			}
+		default:
+			if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+				q.err = fmt.Errorf("goquent: filter field %s is a slice; use op=in", sf.Name)
+				return
+			}
+			q.Where(col, op, fv.Interface())
+		}
+		if q.err != nil {
+			return
+		}
+	}
+}
+
+// parseFilterTag parses `filter:"column,op=>="` into a validated column and
+// operator.
+func parseFilterTag(tag, field string) (string, string, error) {
+	parts := strings.Split(tag, ",")
+	col := strings.TrimSpace(parts[0])
+	if col == "" {
+		col = stringutil.ToSnake(field)
+	}
+	if err := validateSelectColumn(col); err != nil || strings.Contains(col, "*") {
+		return "", "", fmt.Errorf("goquent: invalid filter column %q on field %s", col, field)
+	}
+	op := "="
+	for _, part := range parts[1:] {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || name != "op" {
+			return "", "", fmt.Errorf("goquent: unknown filter option %q on field %s", part, field)
+		}
+		op = strings.TrimSpace(value)
+	}
+	switch strings.ToUpper(op) {
+	case "IN", "NOT IN":
+		return col, op, nil
+	}
+	op, err := validateConditionOperator(op)
+	if err != nil {
+		return "", "", fmt.Errorf("goquent: filter field %s: %w", field, err)
+	}
+	return col, op, nil
+}
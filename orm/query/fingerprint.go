@@ -0,0 +1,183 @@
+package query
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// PlanFingerprint identifies the shape of plan: its operation, tables and
+// normalized SQL (see NormalizeSQL). Plans that differ only in literal
+// values, placeholder style, IN list or VALUES row counts, whitespace,
+// identifier quoting or keyword case share a fingerprint.
+func PlanFingerprint(plan *QueryPlan) string {
+	if plan == nil {
+		return ""
+	}
+	tables := make([]string, 0, len(plan.Tables))
+	for _, t := range plan.Tables {
+		if name := normalizeTableName(t.Name); name != "" {
+			tables = append(tables, strings.ToLower(strings.Trim(name, "`\"")))
+		}
+	}
+	sort.Strings(tables)
+	sum := sha256.Sum256([]byte(string(plan.Operation) + "\n" + strings.Join(tables, ",") + "\n" + NormalizeSQL(plan.SQL)))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// NormalizeSQL reduces SQL to its shape: comments are dropped, string and
+// number literals and placeholders (?, $1, :name, @name) become ?, quoted
+// identifiers are unquoted, words are lowercased, whitespace is collapsed,
+// and lists of only placeholders such as IN (?, ?, ?) or repeated VALUES
+// rows collapse to a single (?+).
+func NormalizeSQL(sqlStr string) string {
+	return strings.Join(collapsePlaceholderLists(sqlShapeTokens(sqlStr)), " ")
+}
+
+func sqlShapeTokens(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && i+1 < len(s) && s[i+1] == '-':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += end + 4
+			}
+		case c == '\'':
+			i = skipQuoted(s, i, '\'')
+			tokens = append(tokens, "?")
+		case c == '`' || c == '"':
+			end := skipQuoted(s, i, c)
+			tokens = append(tokens, strings.ToLower(strings.Trim(s[i:end], string(c))))
+			i = end
+		case c == '?':
+			i++
+			tokens = append(tokens, "?")
+		case c == '$' && i+1 < len(s) && isDigit(s[i+1]):
+			i++
+			for i < len(s) && isDigit(s[i]) {
+				i++
+			}
+			tokens = append(tokens, "?")
+		case (c == ':' || c == '@') && i+1 < len(s) && isNamedParamByte(s[i+1], true) && (i == 0 || (s[i-1] != ':' && s[i-1] != '@')):
+			i++
+			for i < len(s) && isNamedParamByte(s[i], false) {
+				i++
+			}
+			tokens = append(tokens, "?")
+		case isDigit(c) || (c == '.' && i+1 < len(s) && isDigit(s[i+1])) ||
+			(c == '-' && i+1 < len(s) && (isDigit(s[i+1]) || s[i+1] == '.') && signContext(tokens)):
+			// A leading minus is part of the literal: -1 and 1 share a shape.
+			if c == '-' {
+				i++
+			}
+			for i < len(s) && (isDigit(s[i]) || s[i] == '.' || s[i] == 'e' || s[i] == 'E' || s[i] == 'x' || s[i] == 'X' ||
+				(s[i] >= 'a' && s[i] <= 'f') || (s[i] >= 'A' && s[i] <= 'F')) {
+				i++
+			}
+			tokens = append(tokens, "?")
+		case isNamedParamByte(c, true):
+			start := i
+			for i < len(s) && (isNamedParamByte(s[i], false) || s[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, strings.ToLower(s[start:i]))
+		default:
+			// Multi-character operators such as <=, <>, ::, || stay together.
+			start := i
+			i++
+			for i < len(s) && strings.IndexByte("<>=!:|&", s[i]) >= 0 && strings.IndexByte("<>=!:|&", c) >= 0 {
+				i++
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens
+}
+
+// skipQuoted returns the index after the quoted token starting at i. A
+// doubled quote or a backslash escapes the quote character.
+func skipQuoted(s string, i int, quote byte) int {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case quote:
+			if j+1 < len(s) && s[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// signContext reports whether a '-' following tokens is a sign rather than
+// binary subtraction, i.e. whether no operand precedes it.
+func signContext(tokens []string) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	switch prev := tokens[len(tokens)-1]; prev {
+	case "?", ")", "]":
+		return false
+	case "select", "where", "and", "or", "not", "on", "when", "then", "else",
+		"by", "limit", "offset", "values", "in", "between", "like", "is", "set", "return", "having":
+		return true
+	default:
+		return !isNamedParamByte(prev[0], true) && !isDigit(prev[0])
+	}
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// collapsePlaceholderLists rewrites "( ? , ? )" to "(?+)" and drops repeated
+// ", (?+)" groups that follow it.
+func collapsePlaceholderLists(tokens []string) []string {
+	out := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i] == "(" {
+			if end, ok := placeholderListEnd(tokens, i); ok {
+				if n := len(out); n >= 2 && out[n-1] == "," && out[n-2] == "(?+)" {
+					out = out[:n-1]
+				} else {
+					out = append(out, "(?+)")
+				}
+				i = end
+				continue
+			}
+		}
+		out = append(out, tokens[i])
+	}
+	return out
+}
+
+// placeholderListEnd reports whether tokens[start] opens a list containing
+// only placeholders and commas, returning the index of its closing paren.
+func placeholderListEnd(tokens []string, start int) (int, bool) {
+	expectPlaceholder := true
+	for j := start + 1; j < len(tokens); j++ {
+		switch {
+		case tokens[j] == ")" && !expectPlaceholder:
+			return j, true
+		case tokens[j] == "?" && expectPlaceholder:
+			expectPlaceholder = false
+		case tokens[j] == "," && !expectPlaceholder:
+			expectPlaceholder = true
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,211 @@
+package query
+
+import (
+	"sort"
+	"strings"
+)
+
+// IndexInfo describes a table index known to the risk engine, usually taken
+// from the manifest. A primary key is a unique index.
+type IndexInfo struct {
+	Name    string   `json:"name,omitempty"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
+}
+
+// tableIndexes returns the indexes configured for the plan's first table and
+// whether any are known. Tables without index metadata are not checked.
+func (d defaultRiskEngine) tableIndexes(plan *QueryPlan) (TableRef, []IndexInfo, bool) {
+	if len(d.config.Indexes) == 0 || len(plan.Tables) == 0 {
+		return TableRef{}, nil, false
+	}
+	table := plan.Tables[0]
+	for name, indexes := range d.config.Indexes {
+		if normalizeTableName(name) == normalizeTableName(table.Name) {
+			return table, indexes, len(indexes) > 0
+		}
+	}
+	return table, nil, false
+}
+
+// indexWarnings reports predicates and sorts of builder selects, updates and
+// deletes that cannot use a known index.
+func (d defaultRiskEngine) indexWarnings(plan *QueryPlan) []Warning {
+	switch plan.Operation {
+	case OperationSelect, OperationUpdate, OperationDelete:
+	default:
+		return nil
+	}
+	table, indexes, ok := d.tableIndexes(plan)
+	if !ok {
+		return nil
+	}
+	filtered, equal := predicateColumns(plan, table)
+
+	var warnings []Warning
+	if len(filtered) > 0 && !anyIndexLeads(indexes, filtered) {
+		warnings = append(warnings, newWarning(WarningPredicateNotIndexed, RiskMedium,
+			"no predicate column is the leading column of a known index; the query scans "+table.Name,
+			"filter on an indexed column or add an index for "+strings.Join(sortedKeys(filtered), ", "),
+			true,
+			false,
+		))
+	}
+	if plan.Operation == OperationSelect && len(plan.OrderBy) > 0 && !uniqueIndexPinned(indexes, equal) {
+		if order, ok := orderColumns(plan, table); ok && !anyIndexSorts(indexes, equal, order) {
+			warnings = append(warnings, newWarning(WarningOrderByNotIndexed, RiskMedium,
+				"ORDER BY cannot be served by a known index; every matching row is sorted",
+				"add an index on the filter columns followed by "+strings.Join(order, ", "),
+				true,
+				false,
+			))
+		}
+	}
+	return warnings
+}
+
+// hasUniqueIndexPredicate reports whether equality predicates cover every
+// column of a known unique index, so at most one row matches.
+func (d defaultRiskEngine) hasUniqueIndexPredicate(plan *QueryPlan) bool {
+	table, indexes, ok := d.tableIndexes(plan)
+	if !ok {
+		return false
+	}
+	_, equal := predicateColumns(plan, table)
+	return uniqueIndexPinned(indexes, equal)
+}
+
+// predicateColumns returns the columns of table filtered by plan, and the
+// subset compared with = in AND-only plans. Raw predicates are ignored.
+func predicateColumns(plan *QueryPlan, table TableRef) (filtered, equal map[string]bool) {
+	filtered = make(map[string]bool)
+	equal = make(map[string]bool)
+	hasOr := false
+	for _, p := range plan.Predicates {
+		if strings.EqualFold(p.Connector, "or") {
+			hasOr = true
+		}
+	}
+	for _, p := range plan.Predicates {
+		if p.Raw != "" || p.Column == "" || p.Negated || p.Function != "" || p.JSONPath != "" {
+			continue
+		}
+		column, ok := tableColumn(p.Column, table)
+		if !ok {
+			continue
+		}
+		filtered[column] = true
+		if !hasOr && (p.Operator == "=" || (strings.EqualFold(p.Operator, "in") && p.ValueCount == 1)) {
+			equal[column] = true
+		}
+	}
+	return filtered, equal
+}
+
+func orderColumns(plan *QueryPlan, table TableRef) ([]string, bool) {
+	columns := make([]string, 0, len(plan.OrderBy))
+	for _, o := range plan.OrderBy {
+		if o.Raw != "" {
+			return nil, false
+		}
+		column, ok := tableColumn(o.Column, table)
+		if !ok {
+			return nil, false
+		}
+		columns = append(columns, column)
+	}
+	return columns, true
+}
+
+// tableColumn strips a qualifier naming table from column. Columns
+// qualified with another table are reported as not belonging to it.
+func tableColumn(column string, table TableRef) (string, bool) {
+	column = strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(column), "`", ""), `"`, ""))
+	if i := strings.LastIndexByte(column, '.'); i >= 0 {
+		qualifier := column[:i]
+		if qualifier != normalizeTableName(table.Name) && qualifier != tableAlias(table) {
+			return "", false
+		}
+		column = column[i+1:]
+	}
+	return column, column != ""
+}
+
+// tableAlias returns the alias of table. Builder tables keep "orders as o"
+// in Name and leave Alias empty.
+func tableAlias(table TableRef) string {
+	if table.Alias != "" {
+		return strings.ToLower(strings.Trim(table.Alias, "`\""))
+	}
+	if fields := strings.Fields(table.Name); len(fields) > 1 {
+		return strings.ToLower(strings.Trim(fields[len(fields)-1], "`\""))
+	}
+	return ""
+}
+
+func anyIndexLeads(indexes []IndexInfo, columns map[string]bool) bool {
+	for _, index := range indexes {
+		if len(index.Columns) > 0 && columns[strings.ToLower(index.Columns[0])] {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueIndexPinned(indexes []IndexInfo, equal map[string]bool) bool {
+	for _, index := range indexes {
+		if !index.Unique || len(index.Columns) == 0 {
+			continue
+		}
+		pinned := true
+		for _, column := range index.Columns {
+			pinned = pinned && equal[strings.ToLower(column)]
+		}
+		if pinned {
+			return true
+		}
+	}
+	return false
+}
+
+// anyIndexSorts reports whether an index yields rows in order: after a
+// prefix of equality-filtered columns, the index continues with the ORDER
+// BY columns.
+func anyIndexSorts(indexes []IndexInfo, equal map[string]bool, order []string) bool {
+	for _, index := range indexes {
+		i := 0
+		for i < len(index.Columns) && equal[strings.ToLower(index.Columns[i])] && !containsString(order, strings.ToLower(index.Columns[i])) {
+			i++
+		}
+		rest := index.Columns[i:]
+		if len(rest) < len(order) {
+			continue
+		}
+		matches := true
+		for j, column := range order {
+			matches = matches && strings.EqualFold(rest[j], column)
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrNotExecuted is returned when an interceptor returns without an error
+// and without calling next.
+var ErrNotExecuted = errors.New("goquent: interceptor returned without executing the query")
+
+// ExecResult describes an executed plan to interceptors.
+type ExecResult struct {
+	// Rows is the number of rows scanned by a read or affected by a write,
+	// or -1 when it is not known (for example when *sql.Rows is returned to
+	// the caller unread).
+	Rows int64
+}
+
+// Handler executes a plan.
+type Handler func(ctx context.Context, plan *QueryPlan) (ExecResult, error)
+
+// Interceptor wraps plan execution. It runs after the plan passed approval
+// and block checks and must call next to execute it; returning an error
+// without calling next vetoes execution. Interceptors may add plan metadata
+// and observe the duration, rows and error of next.
+//
+//	func audit(ctx context.Context, plan *query.QueryPlan, next query.Handler) (query.ExecResult, error) {
+//	    start := time.Now()
+//	    res, err := next(ctx, plan)
+//	    log.Printf("%s %s rows=%d in %s", plan.Operation, plan.SQL, res.Rows, time.Since(start))
+//	    return res, err
+//	}
+type Interceptor func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error)
+
+// Intercept runs final through interceptors; the first interceptor is the
+// outermost. A nil ctx is replaced with context.Background(). If the chain
+// returns no error but final never ran, Intercept returns ErrNotExecuted.
+// Executions reaching final are counted against the QueryBudget of ctx.
+func Intercept(ctx context.Context, plan *QueryPlan, interceptors []Interceptor, final Handler) (ExecResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	budget := QueryBudgetFromContext(ctx)
+	executed := false
+	h := func(ctx context.Context, plan *QueryPlan) (ExecResult, error) {
+		if budget == nil {
+			executed = true
+			return final(ctx, plan)
+		}
+		if err := budget.begin(ctx, plan); err != nil {
+			return ExecResult{Rows: -1}, err
+		}
+		executed = true
+		res, err := final(ctx, plan)
+		budget.finish(ctx, plan, res.Rows)
+		return res, err
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], h
+		if interceptor == nil {
+			continue
+		}
+		h = func(ctx context.Context, plan *QueryPlan) (ExecResult, error) {
+			return interceptor(ctx, plan, next)
+		}
+	}
+	res, err := h(ctx, plan)
+	if err == nil && !executed {
+		return ExecResult{Rows: -1}, ErrNotExecuted
+	}
+	return res, err
+}
+
+// ResultRows returns the rows affected by res, or -1 when unknown.
+func ResultRows(res sql.Result) int64 {
+	if res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// WithInterceptors appends interceptors that wrap every execution of the
+// query.
+func (q *Query) WithInterceptors(interceptors ...Interceptor) *Query {
+	q = q.derive()
+	q.interceptors = append(q.interceptors[:len(q.interceptors):len(q.interceptors)], interceptors...)
+	return q
+}
+
+// execPlan checks plan and executes it through the query's interceptors.
+func (q *Query) execPlan(plan *QueryPlan) (sql.Result, error) {
+	if err := q.approvals.EnsurePlanExecutable(plan, q.trustedKeys...); err != nil {
+		return nil, err
+	}
+	var res sql.Result
+	_, err := Intercept(q.ctx, plan, q.interceptors, func(ctx context.Context, plan *QueryPlan) (ExecResult, error) {
+		var err error
+		res, err = q.exec.ExecContext(ctx, q.executedSQL(ctx, plan), q.bindArgs(plan.Params)...)
+		if err != nil {
+			return ExecResult{Rows: -1}, err
+		}
+		return ExecResult{Rows: ResultRows(res)}, nil
+	})
+	return res, err
+}
+
+// queryPlan checks plan, runs it through the query's interceptors and passes
+// the rows to scan, which returns the number of rows it read.
+func (q *Query) queryPlan(plan *QueryPlan, scan func(*sql.Rows) (int64, error)) error {
+	if err := q.approvals.EnsurePlanExecutable(plan, q.trustedKeys...); err != nil {
+		return err
+	}
+	_, err := Intercept(q.ctx, plan, q.interceptors, func(ctx context.Context, plan *QueryPlan) (ExecResult, error) {
+		rows, err := q.exec.QueryContext(ctx, q.executedSQL(ctx, plan), q.bindArgs(plan.Params)...)
+		if err != nil {
+			return ExecResult{Rows: -1}, err
+		}
+		defer rows.Close()
+		n, err := scan(rows)
+		if err != nil {
+			return ExecResult{Rows: -1}, err
+		}
+		return ExecResult{Rows: n}, nil
+	})
+	return err
+}
+
+// scanSingleRow scans the first row into dest like (*sql.Row).Scan.
+func scanSingleRow(dest ...any) func(*sql.Rows) (int64, error) {
+	return func(rows *sql.Rows) (int64, error) {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return 0, err
+			}
+			return 0, sql.ErrNoRows
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return 0, err
+		}
+		return 1, rows.Close()
+	}
+}
@@ -0,0 +1,288 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/faciam-dev/goquent/orm/driver"
+)
+
+// jsonPathSegment is one key or array index in a validated JSON path.
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath validates paths such as "profile.address.city", "$.tags[0]",
+// or "items[2].sku". Keys must be identifiers; indexes must be non-negative.
+func parseJSONPath(path string) ([]jsonPathSegment, string, error) {
+	trimmed := strings.TrimSpace(path)
+	trimmed = strings.TrimPrefix(trimmed, "$")
+	trimmed = strings.TrimPrefix(trimmed, ".")
+	if trimmed == "" {
+		return nil, "$", nil
+	}
+	var segments []jsonPathSegment
+	for _, part := range strings.Split(trimmed, ".") {
+		key := part
+		var indexes []int
+		if open := strings.IndexByte(part, '['); open >= 0 {
+			key = part[:open]
+			rest := part[open:]
+			for rest != "" {
+				end := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || end < 0 {
+					return nil, "", fmt.Errorf("goquent: invalid JSON path %q", path)
+				}
+				n, err := strconv.Atoi(rest[1:end])
+				if err != nil || n < 0 {
+					return nil, "", fmt.Errorf("goquent: invalid JSON path index in %q", path)
+				}
+				indexes = append(indexes, n)
+				rest = rest[end+1:]
+			}
+		}
+		if key != "" {
+			if !isJSONPathKey(key) {
+				return nil, "", fmt.Errorf("goquent: invalid JSON path key %q in %q", key, path)
+			}
+			segments = append(segments, jsonPathSegment{key: key})
+		} else if len(indexes) == 0 {
+			return nil, "", fmt.Errorf("goquent: invalid JSON path %q", path)
+		}
+		for _, n := range indexes {
+			segments = append(segments, jsonPathSegment{index: n, isIndex: true})
+		}
+	}
+	return segments, formatJSONPath(segments), nil
+}
+
+func isJSONPathKey(key string) bool {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case i > 0 && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return key != ""
+}
+
+// formatJSONPath returns the normalized MySQL-style path used in metadata.
+func formatJSONPath(segments []jsonPathSegment) string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, seg := range segments {
+		if seg.isIndex {
+			fmt.Fprintf(&b, "[%d]", seg.index)
+			continue
+		}
+		b.WriteByte('.')
+		b.WriteString(seg.key)
+	}
+	return b.String()
+}
+
+// jsonExtractSQL renders access to a JSON path. text selects the unquoted
+// text value (->> on Postgres, JSON_UNQUOTE on MySQL).
+func jsonExtractSQL(d driver.Dialect, col string, segments []jsonPathSegment, text bool) string {
+	quoted := quoteIdentifierPath(d, col)
+	if _, ok := d.(driver.PostgresDialect); ok {
+		if len(segments) == 0 {
+			if text {
+				return quoted + "::text"
+			}
+			return quoted
+		}
+		var b strings.Builder
+		b.WriteString(quoted)
+		for i, seg := range segments {
+			if text && i == len(segments)-1 {
+				b.WriteString("->>")
+			} else {
+				b.WriteString("->")
+			}
+			if seg.isIndex {
+				b.WriteString(strconv.Itoa(seg.index))
+			} else {
+				b.WriteString("'" + seg.key + "'")
+			}
+		}
+		return b.String()
+	}
+	expr := "JSON_EXTRACT(" + quoted + ", '" + formatJSONPath(segments) + "')"
+	if text {
+		return "JSON_UNQUOTE(" + expr + ")"
+	}
+	return expr
+}
+
+// WhereJSON compares the value at a JSON path with a bound value.
+func (q *Query) WhereJSON(col, path, op string, value any) *Query {
+	q = q.derive()
+	if q.err != nil {
+		return q
+	}
+	segments, normalized, err := q.validateJSONTarget(col, path)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	if len(segments) == 0 {
+		q.err = fmt.Errorf("goquent: WhereJSON requires a JSON path")
+		return q
+	}
+	op, err = validateConditionOperator(op)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	name := q.nextJSONParam()
+	placeholder := ":" + name
+	var target string
+	_, postgres := q.dialect.(driver.PostgresDialect)
+	switch rv.Kind() {
+	case reflect.String:
+		target = jsonExtractSQL(q.dialect, col, segments, true)
+		value = rv.String()
+	case reflect.Bool:
+		if postgres {
+			target = "(" + jsonExtractSQL(q.dialect, col, segments, true) + ")::boolean"
+			value = rv.Bool()
+		} else {
+			target = jsonExtractSQL(q.dialect, col, segments, false)
+			placeholder = "CAST(" + placeholder + " AS JSON)"
+			value = strconv.FormatBool(rv.Bool())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if postgres {
+			target = "(" + jsonExtractSQL(q.dialect, col, segments, true) + ")::numeric"
+		} else {
+			target = jsonExtractSQL(q.dialect, col, segments, false)
+		}
+		value = rv.Interface()
+	default:
+		q.err = fmt.Errorf("goquent: WhereJSON supports string, bool, and numeric values, got %T; use WhereJSONContains for documents", value)
+		return q
+	}
+	raw := target + " " + op + " " + placeholder
+	q.addJSONPredicate(raw, map[string]any{name: value}, generatedRef{column: col, path: normalized, operator: op, function: "json_extract"})
+	return q
+}
+
+// WhereJSONContains matches rows whose JSON document (or the value at path)
+// contains value. value is encoded with encoding/json and bound as a parameter.
+func (q *Query) WhereJSONContains(col, path string, value any) *Query {
+	q = q.derive()
+	if q.err != nil {
+		return q
+	}
+	segments, normalized, err := q.validateJSONTarget(col, path)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		q.err = fmt.Errorf("goquent: encode JSON contains value: %w", err)
+		return q
+	}
+	name := q.nextJSONParam()
+	var raw string
+	if _, ok := q.dialect.(driver.PostgresDialect); ok {
+		raw = "(" + jsonExtractSQL(q.dialect, col, segments, false) + ")::jsonb @> CAST(:" + name + " AS jsonb)"
+	} else if len(segments) == 0 {
+		raw = "JSON_CONTAINS(" + quoteIdentifierPath(q.dialect, col) + ", :" + name + ")"
+	} else {
+		raw = "JSON_CONTAINS(" + quoteIdentifierPath(q.dialect, col) + ", :" + name + ", '" + normalized + "')"
+	}
+	q.addJSONPredicate(raw, map[string]any{name: string(encoded)}, generatedRef{column: col, path: normalized, operator: "@>", function: "json_contains"})
+	return q
+}
+
+// WhereJSONLength compares the length of the JSON array (or object on MySQL)
+// at path with n.
+func (q *Query) WhereJSONLength(col, path, op string, n int) *Query {
+	q = q.derive()
+	if q.err != nil {
+		return q
+	}
+	segments, normalized, err := q.validateJSONTarget(col, path)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	op, err = validateConditionOperator(op)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	if strings.Contains(strings.ToUpper(op), "LIKE") {
+		q.err = fmt.Errorf("goquent: WhereJSONLength does not support %s", op)
+		return q
+	}
+	name := q.nextJSONParam()
+	var raw string
+	if _, ok := q.dialect.(driver.PostgresDialect); ok {
+		raw = "jsonb_array_length((" + jsonExtractSQL(q.dialect, col, segments, false) + ")::jsonb) " + op + " :" + name
+	} else if len(segments) == 0 {
+		raw = "JSON_LENGTH(" + quoteIdentifierPath(q.dialect, col) + ") " + op + " :" + name
+	} else {
+		raw = "JSON_LENGTH(" + quoteIdentifierPath(q.dialect, col) + ", '" + normalized + "') " + op + " :" + name
+	}
+	q.addJSONPredicate(raw, map[string]any{name: n}, generatedRef{column: col, path: normalized, operator: op, function: "json_length"})
+	return q
+}
+
+// SelectJSON selects the text value at a JSON path under alias.
+func (q *Query) SelectJSON(col, path, alias string) *Query {
+	q = q.derive()
+	if q.err != nil {
+		return q
+	}
+	segments, normalized, err := q.validateJSONTarget(col, path)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	if len(segments) == 0 {
+		q.err = fmt.Errorf("goquent: SelectJSON requires a JSON path")
+		return q
+	}
+	if err := validateSelectColumn(alias); err != nil || strings.ContainsAny(alias, ".*") {
+		q.err = fmt.Errorf("goquent: invalid SelectJSON alias %q", alias)
+		return q
+	}
+	raw := jsonExtractSQL(q.dialect, col, segments, true) + " AS " + q.dialect.QuoteIdent(alias)
+	q.builder.SelectRaw(raw)
+	q.recordGeneratedRef(raw, generatedRef{column: col, path: normalized, function: "json_extract"})
+	return q
+}
+
+func (q *Query) validateJSONTarget(col, path string) ([]jsonPathSegment, string, error) {
+	if err := validateSelectColumn(col); err != nil || strings.Contains(col, "*") {
+		return nil, "", fmt.Errorf("goquent: invalid JSON column %q", col)
+	}
+	return parseJSONPath(path)
+}
+
+func (q *Query) nextJSONParam() string {
+	return fmt.Sprintf("__goquent_json_%d", len(q.generatedRefs))
+}
+
+func (q *Query) addJSONPredicate(raw string, vals map[string]any, ref generatedRef) {
+	q.builder.WhereRaw(raw, vals)
+	q.recordGeneratedRef(raw, ref)
+}
@@ -0,0 +1,130 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const (
+	LockModeForUpdate = "for_update"
+	LockModeShared    = "shared"
+)
+
+// LockOption configures a LockForUpdate clause.
+type LockOption func(*lockState)
+
+type lockState struct {
+	mode       string
+	skipLocked bool
+	noWait     bool
+	of         []string
+}
+
+// SkipLocked makes the lock skip rows already locked by other transactions.
+func SkipLocked() LockOption {
+	return func(l *lockState) { l.skipLocked = true }
+}
+
+// NoWait makes the lock fail immediately instead of waiting for locked rows.
+func NoWait() LockOption {
+	return func(l *lockState) { l.noWait = true }
+}
+
+// Of limits the lock to rows from the given tables or aliases.
+func Of(tables ...string) LockOption {
+	return func(l *lockState) { l.of = append(l.of, tables...) }
+}
+
+// SharedLock adds LOCK IN SHARE MODE clause.
+func (q *Query) SharedLock() *Query {
+	q = q.derive()
+	q.builder.SharedLock()
+	q.lock = &lockState{mode: LockModeShared}
+	return q
+}
+
+// LockForUpdate adds FOR UPDATE clause. Options add OF, SKIP LOCKED, or
+// NOWAIT, which are supported by MySQL 8 and PostgreSQL.
+func (q *Query) LockForUpdate(opts ...LockOption) *Query {
+	q = q.derive()
+	if q.err != nil {
+		return q
+	}
+	l := &lockState{mode: LockModeForUpdate}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.skipLocked && l.noWait {
+		q.err = fmt.Errorf("goquent: SkipLocked and NoWait cannot be combined")
+		return q
+	}
+	for _, table := range l.of {
+		if err := validateSelectColumn(table); err != nil || strings.Contains(table, "*") {
+			q.err = fmt.Errorf("goquent: invalid lock table %q", table)
+			return q
+		}
+	}
+	q.builder.LockForUpdate()
+	q.lock = l
+	return q
+}
+
+// suffix returns the clause appended after FOR UPDATE.
+func (l *lockState) suffix(q *Query) string {
+	var b strings.Builder
+	if len(l.of) > 0 {
+		quoted := make([]string, len(l.of))
+		for i, table := range l.of {
+			quoted[i] = quoteIdentifierPath(q.dialect, table)
+		}
+		b.WriteString(" OF ")
+		b.WriteString(strings.Join(quoted, ", "))
+	}
+	if l.skipLocked {
+		b.WriteString(" SKIP LOCKED")
+	}
+	if l.noWait {
+		b.WriteString(" NOWAIT")
+	}
+	return b.String()
+}
+
+// applyLockOptions appends lock options to a built SELECT statement.
+func (q *Query) applyLockOptions(sqlStr string) (string, error) {
+	if q.lock == nil || q.lock.mode != LockModeForUpdate {
+		return sqlStr, nil
+	}
+	suffix := q.lock.suffix(q)
+	if suffix == "" {
+		return sqlStr, nil
+	}
+	if !strings.HasSuffix(sqlStr, " FOR UPDATE") {
+		return "", fmt.Errorf("goquent: lock options cannot be combined with UNION")
+	}
+	return sqlStr + suffix, nil
+}
+
+func (q *Query) lockRef() *LockRef {
+	if q.lock == nil {
+		return nil
+	}
+	ref := &LockRef{
+		Mode:       q.lock.mode,
+		SkipLocked: q.lock.skipLocked,
+		NoWait:     q.lock.noWait,
+		Of:         append([]string(nil), q.lock.of...),
+	}
+	ref.OutsideTransaction = outsideTransaction(q.exec)
+	return ref
+}
+
+// outsideTransaction reports whether exec runs statements on a *sql.DB.
+// Executors wrapping a *sql.DB or *sql.Tx report it with InTransaction.
+func outsideTransaction(exec executor) bool {
+	if t, ok := exec.(interface{ InTransaction() bool }); ok {
+		return !t.InTransaction()
+	}
+	_, ok := exec.(*sql.DB)
+	return ok
+}
@@ -0,0 +1,120 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/faciam-dev/goquent/orm/conv"
+	"github.com/faciam-dev/goquent/orm/driver"
+)
+
+// NamedParamsMetadataKey is the QueryPlan.Metadata key listing the parameter
+// name bound to each placeholder of a named raw query, in placeholder order.
+const NamedParamsMetadataKey = "named_params"
+
+// BindNamed rewrites :name and @name parameters in sqlStr to the dialect's
+// placeholders and returns the positional args and the parameter name for
+// each placeholder. params is a map[string]any or a struct (db tags or
+// snake_case field names). Slice values other than []byte expand to a
+// comma-separated placeholder list for IN (:ids). Parameters inside string
+// literals, quoted identifiers, and comments are left alone, as are
+// PostgreSQL casts (::type) and MySQL system variables (@@name).
+func BindNamed(d driver.Dialect, sqlStr string, params any) (string, []any, []string, error) {
+	values, err := namedValues(params)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	var (
+		args  []any
+		names []string
+	)
+	out, err := rewriteSQL(sqlStr, backslashEscapes(d), func(s string, i int) (string, int, bool, error) {
+		c := s[i]
+		if c != ':' && c != '@' {
+			return "", 0, false, nil
+		}
+		if i+1 < len(s) && s[i+1] == c {
+			return s[i : i+2], i + 2, true, nil
+		}
+		end := i + 1
+		for end < len(s) && isNamedParamByte(s[end], end == i+1) {
+			end++
+		}
+		if end == i+1 || (i > 0 && isNamedParamByte(s[i-1], false)) {
+			return "", 0, false, nil
+		}
+		name := s[i+1 : end]
+		value, ok := values[name]
+		if !ok {
+			return "", 0, false, fmt.Errorf("goquent: missing named parameter %q", name)
+		}
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+			args = append(args, value)
+			names = append(names, name)
+			return d.Placeholder(len(args)), end, true, nil
+		}
+		if rv.Len() == 0 {
+			return "", 0, false, fmt.Errorf("goquent: named parameter %q is an empty list", name)
+		}
+		var b []byte
+		for j := 0; j < rv.Len(); j++ {
+			if j > 0 {
+				b = append(b, ", "...)
+			}
+			args = append(args, rv.Index(j).Interface())
+			names = append(names, name+"["+strconv.Itoa(j)+"]")
+			b = append(b, d.Placeholder(len(args))...)
+		}
+		return string(b), end, true, nil
+	})
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return out, args, names, nil
+}
+
+// NewNamedRawPlan binds named parameters and plans the resulting raw SQL. The
+// parameter name for each placeholder is recorded under
+// NamedParamsMetadataKey.
+func NewNamedRawPlan(d driver.Dialect, sqlStr string, params any) (*QueryPlan, error) {
+	bound, args, names, err := BindNamed(d, sqlStr, params)
+	if err != nil {
+		return nil, err
+	}
+	plan := NewRawPlan(bound, args...)
+	if plan.Metadata == nil {
+		plan.Metadata = make(map[string]any)
+	}
+	plan.Metadata[NamedParamsMetadataKey] = names
+	return plan, nil
+}
+
+func namedValues(params any) (map[string]any, error) {
+	switch p := params.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case map[string]any:
+		return p, nil
+	}
+	rv := reflect.ValueOf(params)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("goquent: named parameters must be a map[string]any or struct, got %T", params)
+	}
+	return conv.StructToMap(rv.Interface())
+}
+
+func isNamedParamByte(c byte, first bool) bool {
+	switch {
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		return true
+	case !first && c >= '0' && c <= '9':
+		return true
+	default:
+		return false
+	}
+}
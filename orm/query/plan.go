@@ -21,6 +21,8 @@ const (
 	OperationUpdate OperationType = "update"
 	OperationDelete OperationType = "delete"
 	OperationRaw    OperationType = "raw"
+	// OperationMigration marks a migration statement passed to interceptors.
+	OperationMigration OperationType = "migration"
 )
 
 // RiskLevel is structural database risk, not a business-safety guarantee.
@@ -57,6 +59,16 @@ const (
 	WarningSuppressionNotAllowed   = "SUPPRESSION_NOT_ALLOWED"
 	WarningStaticReviewPartial     = "STATIC_REVIEW_PARTIAL"
 	WarningStaticReviewUnsupported = "STATIC_REVIEW_UNSUPPORTED"
+	WarningLockOutsideTransaction  = "LOCK_OUTSIDE_TRANSACTION"
+	WarningApprovalStale           = "APPROVAL_STALE"
+	WarningPredicateNotIndexed     = "PREDICATE_NOT_INDEXED"
+	WarningOrderByNotIndexed       = "ORDER_BY_NOT_INDEXED"
+	WarningDeepOffsetPagination    = "DEEP_OFFSET_PAGINATION"
+	WarningLeadingWildcardLike     = "LEADING_WILDCARD_LIKE"
+	WarningNonSargablePredicate    = "NON_SARGABLE_PREDICATE"
+	WarningLargeInList             = "LARGE_IN_LIST"
+	WarningOrderByRandom           = "ORDER_BY_RANDOM"
+	WarningDistinctPII             = "DISTINCT_PII_SELECTED"
 )
 
 // SourceLocation points at source code when a plan/finding is derived from static analysis.
@@ -91,6 +103,12 @@ type Approval struct {
 	CreatedBy string     `json:"created_by,omitempty"`
 	CreatedAt time.Time  `json:"created_at,omitempty"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Fingerprint is the plan fingerprint or migration checksum the approval
+	// is bound to, set for ApprovalRegistry records and signed approvals.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// KeyID and Signature are set by SignApproval.
+	KeyID     string `json:"key_id,omitempty"`
+	Signature string `json:"signature,omitempty"`
 }
 
 // TableRef describes a table touched by the query.
@@ -108,6 +126,7 @@ type ColumnRef struct {
 	Distinct   bool   `json:"distinct,omitempty"`
 	Count      bool   `json:"count,omitempty"`
 	Function   string `json:"function,omitempty"`
+	JSONPath   string `json:"json_path,omitempty"`
 }
 
 // JoinRef describes a JOIN visible in the query builder metadata.
@@ -133,6 +152,26 @@ type PredicateRef struct {
 	Function    string `json:"function,omitempty"`
 	Subquery    bool   `json:"subquery,omitempty"`
 	Negated     bool   `json:"negated,omitempty"`
+	JSONPath    string `json:"json_path,omitempty"`
+	// LeadingWildcard is set for LIKE predicates whose pattern starts with
+	// % or _. The pattern itself is not recorded.
+	LeadingWildcard bool `json:"leading_wildcard,omitempty"`
+}
+
+// OrderRef describes an ORDER BY term.
+type OrderRef struct {
+	Column    string `json:"column,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	Raw       string `json:"raw,omitempty"`
+}
+
+// LockRef describes a row-locking clause on a SELECT plan.
+type LockRef struct {
+	Mode               string   `json:"mode"`
+	SkipLocked         bool     `json:"skip_locked,omitempty"`
+	NoWait             bool     `json:"no_wait,omitempty"`
+	Of                 []string `json:"of,omitempty"`
+	OutsideTransaction bool     `json:"outside_transaction,omitempty"`
 }
 
 // QueryPlan explains SQL and metadata before the query is executed.
@@ -140,12 +179,15 @@ type QueryPlan struct {
 	Operation          OperationType     `json:"operation"`
 	SQL                string            `json:"sql"`
 	Params             []any             `json:"params"`
+	Fingerprint        string            `json:"fingerprint,omitempty"`
 	Tables             []TableRef        `json:"tables,omitempty"`
 	Columns            []ColumnRef       `json:"columns,omitempty"`
 	Joins              []JoinRef         `json:"joins,omitempty"`
 	Predicates         []PredicateRef    `json:"predicates,omitempty"`
+	OrderBy            []OrderRef        `json:"order_by,omitempty"`
 	Limit              *int64            `json:"limit,omitempty"`
 	Offset             *int64            `json:"offset,omitempty"`
+	Lock               *LockRef          `json:"lock,omitempty"`
 	EstimatedRows      *int64            `json:"estimated_rows,omitempty"`
 	UsesIndex          *bool             `json:"uses_index,omitempty"`
 	RiskLevel          RiskLevel         `json:"risk_level"`
@@ -182,6 +224,9 @@ func (p *QueryPlan) String() string {
 		b.WriteString("requires_approval: true\n")
 	}
 	fmt.Fprintf(&b, "sql: %s\n", p.SQL)
+	if p.Fingerprint != "" {
+		fmt.Fprintf(&b, "fingerprint: %s\n", p.Fingerprint)
+	}
 	if len(p.Params) > 0 {
 		fmt.Fprintf(&b, "params: %v\n", p.Params)
 	}
@@ -194,12 +239,18 @@ func (p *QueryPlan) String() string {
 	if len(p.Predicates) > 0 {
 		fmt.Fprintf(&b, "predicates: %s\n", predicateRefsString(p.Predicates))
 	}
+	if len(p.OrderBy) > 0 {
+		fmt.Fprintf(&b, "order_by: %s\n", orderRefsString(p.OrderBy))
+	}
 	if p.Limit != nil {
 		fmt.Fprintf(&b, "limit: %d\n", *p.Limit)
 	}
 	if p.Offset != nil {
 		fmt.Fprintf(&b, "offset: %d\n", *p.Offset)
 	}
+	if p.Lock != nil {
+		fmt.Fprintf(&b, "lock: %s\n", lockRefString(p.Lock))
+	}
 	for _, w := range p.Warnings {
 		fmt.Fprintf(&b, "warning[%s]: %s", w.Level, w.Code)
 		if w.Message != "" {
@@ -233,7 +284,6 @@ func (q *Query) Plan(ctx context.Context) (*QueryPlan, error) {
 }
 
 func (q *Query) planSelectBuilder(ctx context.Context, builder *qbapi.SelectQueryBuilder) (*QueryPlan, error) {
-	_ = ctx
 	if q.err != nil {
 		return nil, q.err
 	}
@@ -244,9 +294,14 @@ func (q *Query) planSelectBuilder(ctx context.Context, builder *qbapi.SelectQuer
 	if err != nil {
 		return nil, err
 	}
+	sqlStr, err = q.applyLockOptions(sqlStr)
+	if err != nil {
+		return nil, err
+	}
 	plan := newQueryPlan(OperationSelect, sqlStr, args)
 	appendSelectBuilderMetadata(plan, builder)
-	q.finalizePlan(plan)
+	plan.Lock = q.lockRef()
+	q.finalizePlan(ctx, plan)
 	return plan, nil
 }
 
@@ -278,10 +333,71 @@ func appendSelectBuilderMetadata(plan *QueryPlan, builder *qbapi.SelectQueryBuil
 		plan.Offset = &v
 	}
 
+	if src.Order != nil {
+		for _, o := range *src.Order {
+			ref := OrderRef{Column: o.Column, Raw: o.Raw}
+			if o.Raw == "" {
+				ref.Direction = "desc"
+				if o.IsAsc {
+					ref.Direction = "asc"
+				}
+			}
+			plan.OrderBy = append(plan.OrderBy, ref)
+		}
+	}
+
 	appendJoinMetadata(plan, src.Joins)
 	appendPredicateMetadata(plan, src.ConditionGroups)
 }
 
+// generatedRef records how a predicate or projection that Goquent rendered as
+// raw SQL maps back to its source column, so plans can still describe it.
+type generatedRef struct {
+	column     string
+	path       string
+	operator   string
+	function   string
+	valueCount int
+}
+
+func (q *Query) recordGeneratedRef(raw string, ref generatedRef) {
+	if q.generatedRefs == nil {
+		q.generatedRefs = make(map[string]generatedRef)
+	}
+	q.generatedRefs[raw] = ref
+}
+
+// annotateGeneratedRefs attaches column, operator, and JSON path metadata to
+// predicates and projections rendered by Goquent helpers.
+func (q *Query) annotateGeneratedRefs(plan *QueryPlan) {
+	if len(q.generatedRefs) == 0 {
+		return
+	}
+	for i := range plan.Predicates {
+		ref, ok := q.generatedRefs[plan.Predicates[i].Raw]
+		if !ok {
+			continue
+		}
+		plan.Predicates[i].Column = ref.column
+		plan.Predicates[i].Operator = ref.operator
+		plan.Predicates[i].Function = ref.function
+		plan.Predicates[i].JSONPath = ref.path
+		if ref.valueCount > 0 {
+			plan.Predicates[i].ValueCount = ref.valueCount
+		}
+	}
+	for i := range plan.Columns {
+		ref, ok := q.generatedRefs[plan.Columns[i].Expression]
+		if !ok {
+			continue
+		}
+		plan.Columns[i].Name = ref.column
+		plan.Columns[i].Function = ref.function
+		plan.Columns[i].JSONPath = ref.path
+		plan.Columns[i].Raw = false
+	}
+}
+
 func appendSelectBuilderWriteMetadata(plan *QueryPlan, builder *qbapi.SelectQueryBuilder) {
 	src := builder.GetQuery()
 	appendJoinMetadata(plan, src.Joins)
@@ -439,9 +555,22 @@ func predicateRefFromValue(cond any) PredicateRef {
 		Subquery:    !isNilValue(cv.FieldByName("Query")) || !isNilValue(cv.FieldByName("Exists")),
 	}
 	ref.ValueCount = valueCount(cv)
+	ref.LeadingWildcard = leadingWildcard(ref.Operator, cv)
 	return ref
 }
 
+func leadingWildcard(operator string, cv reflect.Value) bool {
+	if !strings.Contains(strings.ToUpper(operator), "LIKE") {
+		return false
+	}
+	values := indirectValue(cv.FieldByName("Value"))
+	if !values.IsValid() || values.Kind() != reflectSlice || values.Len() == 0 {
+		return false
+	}
+	pattern, ok := indirectValue(values.Index(0)).Interface().(string)
+	return ok && (strings.HasPrefix(pattern, "%") || strings.HasPrefix(pattern, "_"))
+}
+
 func valueCount(cv reflect.Value) int {
 	if values := indirectValue(cv.FieldByName("Value")); values.IsValid() && values.Kind() == reflectSlice {
 		return values.Len()
@@ -570,6 +699,8 @@ func columnRefsString(refs []ColumnRef) string {
 	parts := make([]string, 0, len(refs))
 	for _, ref := range refs {
 		switch {
+		case ref.Expression != "" && ref.Name != "":
+			parts = append(parts, ref.Name+" = "+ref.Expression)
 		case ref.Expression != "":
 			parts = append(parts, ref.Expression)
 		case ref.Function != "":
@@ -581,6 +712,35 @@ func columnRefsString(refs []ColumnRef) string {
 	return strings.Join(parts, ", ")
 }
 
+func orderRefsString(refs []OrderRef) string {
+	parts := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Raw != "" {
+			parts = append(parts, ref.Raw)
+			continue
+		}
+		parts = append(parts, ref.Column+" "+ref.Direction)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func lockRefString(ref *LockRef) string {
+	parts := []string{ref.Mode}
+	if len(ref.Of) > 0 {
+		parts = append(parts, "of "+strings.Join(ref.Of, ", "))
+	}
+	if ref.SkipLocked {
+		parts = append(parts, "skip_locked")
+	}
+	if ref.NoWait {
+		parts = append(parts, "no_wait")
+	}
+	if ref.OutsideTransaction {
+		parts = append(parts, "outside_transaction")
+	}
+	return strings.Join(parts, " ")
+}
+
 func predicateRefsString(refs []PredicateRef) string {
 	parts := make([]string, 0, len(refs))
 	for _, ref := range refs {
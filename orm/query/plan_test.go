@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -301,3 +302,707 @@ func TestWhereRawNoArgsPlan(t *testing.T) {
 		t.Fatalf("predicates=%#v", plan.Predicates)
 	}
 }
+
+func TestIncrementPlanRecordsExpression(t *testing.T) {
+	exec := &recordingExec{}
+	plan, err := newPlanTestQuery(exec).
+		Where("id", 7).
+		PlanIncrement(context.Background(), "balance", 10, map[string]any{"name": "alice"})
+	if err != nil {
+		t.Fatalf("plan increment: %v", err)
+	}
+	if exec.calls != 0 {
+		t.Fatalf("plan must not execute, calls=%d", exec.calls)
+	}
+	wantSQL := "UPDATE `users` SET `balance` = `balance` + ?, `name` = ? WHERE `id` = ?"
+	if plan.SQL != wantSQL {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", plan.SQL, wantSQL)
+	}
+	if len(plan.Params) != 3 || plan.Params[0] != 10 || plan.Params[1] != "alice" || plan.Params[2] != 7 {
+		t.Fatalf("unexpected params: %#v", plan.Params)
+	}
+	if len(plan.Columns) != 2 || plan.Columns[0].Function != "increment" || plan.Columns[0].Expression != "`balance` + ?" || plan.Columns[0].Raw {
+		t.Fatalf("unexpected columns: %#v", plan.Columns)
+	}
+}
+
+func TestDecrementPlanPostgresRenumbersPlaceholders(t *testing.T) {
+	plan, err := New(&recordingExec{}, "accounts", ormdriver.PostgresDialect{}).
+		Where("id", 3).
+		PlanDecrement(context.Background(), "stock", 2, nil)
+	if err != nil {
+		t.Fatalf("plan decrement: %v", err)
+	}
+	wantSQL := `UPDATE "accounts" SET "stock" = "stock" - $1 WHERE "id" = $2`
+	if plan.SQL != wantSQL {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", plan.SQL, wantSQL)
+	}
+	if plan.Columns[0].Function != "decrement" {
+		t.Fatalf("expected decrement column metadata, got %#v", plan.Columns)
+	}
+}
+
+func TestIncrementRejectsInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	if _, err := newPlanTestQuery(&recordingExec{}).PlanIncrement(ctx, "balance", "ten", nil); err == nil {
+		t.Fatal("expected non-numeric amount to fail")
+	}
+	if _, err := newPlanTestQuery(&recordingExec{}).PlanIncrement(ctx, "balance; DROP", 1, nil); err == nil {
+		t.Fatal("expected invalid column to fail")
+	}
+	if _, err := newPlanTestQuery(&recordingExec{}).PlanIncrement(ctx, "balance", 1, map[string]any{"balance": 0}); err == nil {
+		t.Fatal("expected duplicate column in extra values to fail")
+	}
+}
+
+func TestUpdatePlanWithExprValue(t *testing.T) {
+	plan, err := New(&recordingExec{}, "users", ormdriver.PostgresDialect{}).
+		Where("id", 1).
+		PlanUpdate(context.Background(), map[string]any{
+			"name":       "bob",
+			"updated_at": NewExpr("GREATEST(updated_at, ?)", "2026-01-01"),
+		})
+	if err != nil {
+		t.Fatalf("plan update: %v", err)
+	}
+	wantSQL := `UPDATE "users" SET "name" = $1, "updated_at" = GREATEST(updated_at, $2) WHERE "id" = $3`
+	if plan.SQL != wantSQL {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", plan.SQL, wantSQL)
+	}
+	if len(plan.Params) != 3 || plan.Params[1] != "2026-01-01" {
+		t.Fatalf("unexpected params: %#v", plan.Params)
+	}
+	if col := plan.Columns[1]; col.Name != "updated_at" || !col.Raw || col.Expression != "GREATEST(updated_at, ?)" {
+		t.Fatalf("unexpected expression column: %#v", col)
+	}
+
+	_, err = newPlanTestQuery(&recordingExec{}).Where("id", 1).
+		PlanUpdate(context.Background(), map[string]any{"name": NewExpr("?; DROP TABLE users", "x")})
+	if err == nil {
+		t.Fatal("expected unsafe expression to fail")
+	}
+	_, err = newPlanTestQuery(&recordingExec{}).Where("id", 1).
+		PlanUpdate(context.Background(), map[string]any{"name": NewExpr("UPPER(?)")})
+	if err == nil {
+		t.Fatal("expected placeholder/arg mismatch to fail")
+	}
+}
+
+func TestLockForUpdateOptionsPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect ormdriver.Dialect
+		opts    []LockOption
+		wantSQL string
+	}{
+		{
+			name:    "mysql skip locked",
+			dialect: ormdriver.MySQLDialect{},
+			opts:    []LockOption{SkipLocked()},
+			wantSQL: "SELECT `id` FROM `jobs` WHERE `status` = ? ORDER BY `id` ASC LIMIT 10 FOR UPDATE SKIP LOCKED",
+		},
+		{
+			name:    "postgres of nowait",
+			dialect: ormdriver.PostgresDialect{},
+			opts:    []LockOption{Of("jobs"), NoWait()},
+			wantSQL: `SELECT "id" FROM "jobs" WHERE "status" = $1 ORDER BY "id" ASC LIMIT 10 FOR UPDATE OF "jobs" NOWAIT`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := New(&recordingExec{}, "jobs", tt.dialect).
+				Select("id").
+				Where("status", "queued").
+				OrderBy("id", "asc").
+				Limit(10).
+				LockForUpdate(tt.opts...).
+				Plan(context.Background())
+			if err != nil {
+				t.Fatalf("plan: %v", err)
+			}
+			if plan.SQL != tt.wantSQL {
+				t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", plan.SQL, tt.wantSQL)
+			}
+			if plan.Lock == nil || plan.Lock.Mode != LockModeForUpdate {
+				t.Fatalf("expected lock metadata, got %#v", plan.Lock)
+			}
+			if plan.Lock.OutsideTransaction || warningCodeSet(plan.Warnings)[WarningLockOutsideTransaction] {
+				t.Fatalf("non *sql.DB executor must not be flagged: %#v", plan.Warnings)
+			}
+		})
+	}
+}
+
+type lockTestConnector struct{}
+
+func (lockTestConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("lock test connector does not connect")
+}
+
+func (lockTestConnector) Driver() driver.Driver { return nil }
+
+func TestLockForUpdateOutsideTransactionWarning(t *testing.T) {
+	db := sql.OpenDB(lockTestConnector{})
+	defer db.Close()
+
+	plan, err := New(db, "jobs", ormdriver.MySQLDialect{}).
+		Select("id").
+		Where("id", 1).
+		Limit(1).
+		LockForUpdate(SkipLocked()).
+		Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.Lock == nil || !plan.Lock.OutsideTransaction || !plan.Lock.SkipLocked {
+		t.Fatalf("unexpected lock metadata: %#v", plan.Lock)
+	}
+	if !warningCodeSet(plan.Warnings)[WarningLockOutsideTransaction] {
+		t.Fatalf("expected %s warning, got %#v", WarningLockOutsideTransaction, plan.Warnings)
+	}
+	if !strings.Contains(plan.String(), "lock: for_update skip_locked outside_transaction") {
+		t.Fatalf("expected lock in plan string, got:\n%s", plan.String())
+	}
+}
+
+func TestLockForUpdateRejectsInvalidOptions(t *testing.T) {
+	if _, err := newPlanTestQuery(&recordingExec{}).LockForUpdate(SkipLocked(), NoWait()).Plan(context.Background()); err == nil {
+		t.Fatal("expected SkipLocked with NoWait to fail")
+	}
+	if _, err := newPlanTestQuery(&recordingExec{}).LockForUpdate(Of("users; DROP")).Plan(context.Background()); err == nil {
+		t.Fatal("expected invalid lock table to fail")
+	}
+}
+
+func TestJSONPredicatesAndProjectionPlan(t *testing.T) {
+	tests := []struct {
+		name       string
+		dialect    ormdriver.Dialect
+		wantSQL    string
+		wantParams []any
+	}{
+		{
+			name:       "mysql",
+			dialect:    ormdriver.MySQLDialect{},
+			wantSQL:    "SELECT `id`, JSON_UNQUOTE(JSON_EXTRACT(`profile`, '$.address.city')) AS `city` FROM `users` WHERE JSON_UNQUOTE(JSON_EXTRACT(`profile`, '$.address.city')) = ? AND JSON_EXTRACT(`profile`, '$.age') >= ? AND JSON_CONTAINS(`profile`, ?, '$.tags') AND JSON_LENGTH(`profile`, '$.items[0].skus') > ? LIMIT 10",
+			wantParams: []any{"Tokyo", 18, `["vip"]`, 2},
+		},
+		{
+			name:       "postgres",
+			dialect:    ormdriver.PostgresDialect{},
+			wantSQL:    `SELECT "id", "profile"->'address'->>'city' AS "city" FROM "users" WHERE "profile"->'address'->>'city' = $1 AND ("profile"->>'age')::numeric >= $2 AND ("profile"->'tags')::jsonb @> CAST($3 AS jsonb) AND jsonb_array_length(("profile"->'items'->0->'skus')::jsonb) > $4 LIMIT 10`,
+			wantParams: []any{"Tokyo", 18, `["vip"]`, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := New(&recordingExec{}, "users", tt.dialect).
+				Select("id").
+				SelectJSON("profile", "address.city", "city").
+				WhereJSON("profile", "$.address.city", "=", "Tokyo").
+				WhereJSON("profile", "age", ">=", 18).
+				WhereJSONContains("profile", "tags", []string{"vip"}).
+				WhereJSONLength("profile", "items[0].skus", ">", 2).
+				Limit(10).
+				Plan(context.Background())
+			if err != nil {
+				t.Fatalf("plan: %v", err)
+			}
+			if plan.SQL != tt.wantSQL {
+				t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", plan.SQL, tt.wantSQL)
+			}
+			if fmt.Sprint(plan.Params) != fmt.Sprint(tt.wantParams) {
+				t.Fatalf("unexpected params: %#v", plan.Params)
+			}
+			wantPaths := []string{"$.address.city", "$.age", "$.tags", "$.items[0].skus"}
+			if len(plan.Predicates) != len(wantPaths) {
+				t.Fatalf("unexpected predicates: %#v", plan.Predicates)
+			}
+			for i, p := range plan.Predicates {
+				if p.Column != "profile" || p.JSONPath != wantPaths[i] {
+					t.Fatalf("predicate %d missing JSON metadata: %#v", i, p)
+				}
+			}
+			if col := plan.Columns[1]; col.Name != "profile" || col.JSONPath != "$.address.city" || col.Raw {
+				t.Fatalf("unexpected JSON column metadata: %#v", col)
+			}
+		})
+	}
+}
+
+func TestWhereJSONInsideGroup(t *testing.T) {
+	plan, err := New(&recordingExec{}, "users", ormdriver.PostgresDialect{}).
+		Where("active", true).
+		WhereGroup(func(g *Query) {
+			g.WhereJSON("profile", "address.city", "=", "Tokyo").
+				WhereJSON("profile", "age", ">=", 18)
+		}).
+		Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	want := `SELECT * FROM "users" WHERE "active" = $1 AND ("profile"->'address'->>'city' = $2 AND ("profile"->>'age')::numeric >= $3)`
+	if plan.SQL != want {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", plan.SQL, want)
+	}
+	var paths []string
+	for _, p := range plan.Predicates {
+		if p.JSONPath != "" {
+			if p.Column != "profile" {
+				t.Fatalf("grouped JSON predicate missing column metadata: %#v", p)
+			}
+			paths = append(paths, p.JSONPath)
+		}
+	}
+	if fmt.Sprint(paths) != "[$.address.city $.age]" {
+		t.Fatalf("grouped JSON predicates missing metadata: %#v", plan.Predicates)
+	}
+}
+
+func TestJSONPathValidation(t *testing.T) {
+	for _, path := range []string{"a'b", "a..b", "a[x]", "a[-1]", "a b", "a]", "1abc"} {
+		if _, err := newPlanTestQuery(&recordingExec{}).WhereJSON("profile", path, "=", "x").Plan(context.Background()); err == nil {
+			t.Fatalf("expected path %q to be rejected", path)
+		}
+	}
+	if _, err := newPlanTestQuery(&recordingExec{}).WhereJSON("profile", "a", "=", map[string]any{"x": 1}).Plan(context.Background()); err == nil {
+		t.Fatal("expected document value to be rejected")
+	}
+	if _, err := newPlanTestQuery(&recordingExec{}).SelectJSON("profile", "a", "bad alias").Plan(context.Background()); err == nil {
+		t.Fatal("expected invalid alias to be rejected")
+	}
+}
+
+func TestPostgresArrayPredicates(t *testing.T) {
+	plan, err := New(&recordingExec{}, "posts", ormdriver.PostgresDialect{}).
+		PostgresArrayIn().
+		Select("id").
+		WhereIn("id", []int64{1, 2, 3}).
+		WhereNotIn("status", []string{"draft"}).
+		WhereArrayContains("tags", []string{"go", "sql"}).
+		WhereArrayOverlaps("scores", []int64{7}).
+		Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	wantSQL := `SELECT "id" FROM "posts" WHERE "id" = ANY($1) AND "status" <> ALL($2) AND "tags" @> $3 AND "scores" && $4`
+	if plan.SQL != wantSQL {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", plan.SQL, wantSQL)
+	}
+	wantParams := []any{"{1,2,3}", `{"draft"}`, `{"go","sql"}`, "{7}"}
+	if fmt.Sprint(plan.Params) != fmt.Sprint(wantParams) {
+		t.Fatalf("unexpected params: %#v", plan.Params)
+	}
+	wantOps := []string{"IN", "NOT IN", "@>", "&&"}
+	if len(plan.Predicates) != len(wantOps) {
+		t.Fatalf("unexpected predicates: %#v", plan.Predicates)
+	}
+	for i, p := range plan.Predicates {
+		if p.Operator != wantOps[i] || p.Column == "" || p.Function == "" {
+			t.Fatalf("predicate %d missing array metadata: %#v", i, p)
+		}
+		if p.ValueCount != 1 {
+			t.Fatalf("predicate %d must bind a single array parameter: %#v", i, p)
+		}
+	}
+}
+
+func TestPostgresArrayPredicatesInsideGroup(t *testing.T) {
+	plan, err := New(&recordingExec{}, "posts", ormdriver.PostgresDialect{}).
+		PostgresArrayIn().
+		Select("id").
+		WhereGroup(func(g *Query) {
+			g.WhereIn("id", []int64{1, 2}).
+				WhereArrayContains("tags", []string{"go"}).
+				WhereArrayOverlaps("scores", []int64{7})
+		}).
+		Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	wantSQL := `SELECT "id" FROM "posts" WHERE ("id" = ANY($1) AND "tags" @> $2 AND "scores" && $3)`
+	if plan.SQL != wantSQL {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", plan.SQL, wantSQL)
+	}
+	wantOps := []string{"IN", "@>", "&&"}
+	if len(plan.Predicates) != len(wantOps) {
+		t.Fatalf("unexpected predicates: %#v", plan.Predicates)
+	}
+	for i, p := range plan.Predicates {
+		if p.Operator != wantOps[i] || p.Function == "" || p.ValueCount != 1 {
+			t.Fatalf("grouped predicate %d missing array metadata: %#v", i, p)
+		}
+	}
+}
+
+func TestPostgresArrayInDisabledOrOtherDialect(t *testing.T) {
+	plan, err := New(&recordingExec{}, "posts", ormdriver.PostgresDialect{}).
+		WhereIn("id", []int64{1, 2}).
+		Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if !strings.Contains(plan.SQL, `"id" IN ($1, $2)`) {
+		t.Fatalf("expected expanded IN list without PostgresArrayIn: %s", plan.SQL)
+	}
+
+	plan, err = newPlanTestQuery(&recordingExec{}).PostgresArrayIn().WhereIn("id", []int64{1, 2}).Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if !strings.Contains(plan.SQL, "`id` IN (?, ?)") {
+		t.Fatalf("expected MySQL IN list: %s", plan.SQL)
+	}
+
+	if _, err := newPlanTestQuery(&recordingExec{}).WhereArrayContains("tags", []string{"go"}).Plan(context.Background()); err == nil {
+		t.Fatal("expected array operators to require PostgreSQL")
+	}
+	if _, err := New(&recordingExec{}, "posts", ormdriver.PostgresDialect{}).WhereArrayOverlaps("tags", []float64{1}).Plan(context.Background()); err == nil {
+		t.Fatal("expected unsupported array element type to be rejected")
+	}
+}
+
+func TestBindArrayArgs(t *testing.T) {
+	args := []any{[]string{"a"}, []int64{1}, 3, []byte("raw")}
+	got := BindArrayArgs(ormdriver.PostgresDialect{}, args)
+	if fmt.Sprint(got) != fmt.Sprint([]any{`{"a"}`, "{1}", 3, []byte("raw")}) {
+		t.Fatalf("unexpected bound args: %#v", got)
+	}
+	if _, ok := args[0].([]string); !ok {
+		t.Fatal("BindArrayArgs must not modify its input")
+	}
+	if got := BindArrayArgs(ormdriver.MySQLDialect{}, args); &got[0] != &args[0] {
+		t.Fatal("expected MySQL args to pass through")
+	}
+}
+
+func planSQL(t *testing.T, q *Query) (string, []any) {
+	t.Helper()
+	plan, err := q.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	return plan.SQL, plan.Params
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	base := newPlanTestQuery(&recordingExec{}).
+		Select("users.id").
+		Join("profiles", "profiles.user_id", "=", "users.id").
+		WhereGroup(func(g *Query) {
+			g.Where("users.status", "active").OrWhere("users.role", "admin")
+		}).
+		Union(New(&recordingExec{}, "admins", ormdriver.MySQLDialect{}).Select("id").Where("active", true)).
+		SuppressWarning(WarningLimitMissing, "fixture")
+	baseSQL, baseParams := planSQL(t, base)
+
+	clone := base.Clone()
+	clone.
+		LeftJoin("teams", "teams.id", "=", "users.team_id").
+		WhereGroup(func(g *Query) { g.Where("teams.name", "core") }).
+		Union(New(&recordingExec{}, "owners", ormdriver.MySQLDialect{}).Select("id")).
+		RequireApproval("clone only").
+		OrderBy("users.id", "desc").
+		Limit(5)
+
+	if gotSQL, gotParams := planSQL(t, base); gotSQL != baseSQL || fmt.Sprint(gotParams) != fmt.Sprint(baseParams) {
+		t.Fatalf("clone changes leaked into base:\n got: %s %v\nwant: %s %v", gotSQL, gotParams, baseSQL, baseParams)
+	}
+	if base.approval != nil || len(base.suppressions) != 1 {
+		t.Fatalf("clone changed base approval or suppressions")
+	}
+	cloneSQL, cloneParams := planSQL(t, clone)
+	for _, want := range []string{"LEFT JOIN `teams`", "`teams`.`name` = ?", "`owners`", "ORDER BY `users`.`id` DESC", "LIMIT 5", "`profiles`", "`admins`"} {
+		if !strings.Contains(cloneSQL, want) {
+			t.Fatalf("clone SQL missing %q: %s", want, cloneSQL)
+		}
+	}
+	if len(cloneParams) != len(baseParams)+1 {
+		t.Fatalf("unexpected clone params: %v (base %v)", cloneParams, baseParams)
+	}
+
+	base.Where("users.id", 1)
+	if again, _ := planSQL(t, clone); again != cloneSQL {
+		t.Fatalf("base changes leaked into clone:\n got: %s\nwant: %s", again, cloneSQL)
+	}
+}
+
+func TestClonePostgresPlaceholdersAndState(t *testing.T) {
+	base := New(&recordingExec{}, "jobs", ormdriver.PostgresDialect{}).
+		Where("status", "queued").
+		WhereJSON("payload", "kind", "=", "email").
+		LockForUpdate(SkipLocked())
+	count := base.Clone().Where("attempts", "<", 3)
+	page := base.Clone().OrderBy("id", "asc").Limit(10)
+
+	countSQL, _ := planSQL(t, count)
+	pageSQL, _ := planSQL(t, page)
+	if !strings.Contains(countSQL, `"attempts" < $3`) || strings.Contains(countSQL, "LIMIT") {
+		t.Fatalf("unexpected count branch SQL: %s", countSQL)
+	}
+	if strings.Contains(pageSQL, "attempts") || !strings.HasSuffix(pageSQL, "FOR UPDATE SKIP LOCKED") {
+		t.Fatalf("unexpected page branch SQL: %s", pageSQL)
+	}
+	plan, err := page.Plan(context.Background())
+	if err != nil || len(plan.Predicates) < 2 || plan.Predicates[1].JSONPath != "$.kind" {
+		t.Fatalf("clone lost generated JSON metadata: %#v err=%v", plan, err)
+	}
+}
+
+func TestImmutableQueryReturnsNewQueries(t *testing.T) {
+	base := newPlanTestQuery(&recordingExec{}).Where("tenant_id", 7).Immutable()
+	baseSQL, _ := planSQL(t, base)
+
+	active := base.Where("status", "active")
+	page := base.OrderBy("id", "asc").Limit(10)
+	total := base.Max("age")
+	if active == base || page == base || total == base {
+		t.Fatal("immutable builder calls must return new queries")
+	}
+	if got, _ := planSQL(t, base); got != baseSQL {
+		t.Fatalf("immutable base changed: %s", got)
+	}
+	activeSQL, _ := planSQL(t, active)
+	pageSQL, _ := planSQL(t, page)
+	if !strings.Contains(activeSQL, "`status` = ?") || strings.Contains(activeSQL, "LIMIT") {
+		t.Fatalf("unexpected active SQL: %s", activeSQL)
+	}
+	if strings.Contains(pageSQL, "status") || !strings.Contains(pageSQL, "LIMIT 10") {
+		t.Fatalf("unexpected page SQL: %s", pageSQL)
+	}
+}
+
+func TestWhenUnlessTap(t *testing.T) {
+	status := ""
+	role := "admin"
+	var tapped bool
+	for _, immutable := range []bool{false, true} {
+		q := newPlanTestQuery(&recordingExec{})
+		if immutable {
+			q.Immutable()
+		}
+		q = q.
+			When(status != "", func(q *Query) { q.Where("status", status) }).
+			When(role != "", func(q *Query) { q.Where("role", role).Where("active", true) }).
+			Unless(role == "admin", func(q *Query) { q.Where("visible", true) }).
+			Tap(func(q *Query) { tapped = true; q.Limit(10) })
+		sqlStr, params := planSQL(t, q)
+		if sqlStr != "SELECT * FROM `users` WHERE `role` = ? AND `active` = ? LIMIT 10" {
+			t.Fatalf("immutable=%v: unexpected SQL: %s", immutable, sqlStr)
+		}
+		if len(params) != 2 || !tapped {
+			t.Fatalf("immutable=%v: unexpected params %v tapped=%v", immutable, params, tapped)
+		}
+	}
+}
+
+func TestBindNamed(t *testing.T) {
+	sqlStr := "SELECT id, @@version FROM users -- :ignored\nWHERE email = :email AND role IN (@roles) AND note <> ':literal' /* @x */ AND tag = :email"
+	got, args, names, err := BindNamed(ormdriver.MySQLDialect{}, sqlStr, map[string]any{
+		"email": "a@example.com",
+		"roles": []string{"admin", "owner"},
+	})
+	if err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	want := "SELECT id, @@version FROM users -- :ignored\nWHERE email = ? AND role IN (?, ?) AND note <> ':literal' /* @x */ AND tag = ?"
+	if got != want {
+		t.Fatalf("unexpected SQL:\n got %s\nwant %s", got, want)
+	}
+	if len(args) != 4 || args[1] != "admin" || args[2] != "owner" || args[3] != "a@example.com" {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+	if strings.Join(names, ",") != "email,roles[0],roles[1],email" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+
+	if _, _, _, err := BindNamed(ormdriver.MySQLDialect{}, "SELECT 1 WHERE id = :id", map[string]any{}); err == nil || !strings.Contains(err.Error(), `missing named parameter "id"`) {
+		t.Fatalf("expected missing parameter error, got %v", err)
+	}
+	if _, _, _, err := BindNamed(ormdriver.MySQLDialect{}, "SELECT 1 WHERE id IN (:ids)", map[string]any{"ids": []int{}}); err == nil {
+		t.Fatalf("expected empty list error")
+	}
+	if _, _, _, err := BindNamed(ormdriver.MySQLDialect{}, "SELECT 1", 42); err == nil {
+		t.Fatalf("expected invalid params error")
+	}
+}
+
+func TestBackslashEscapesOnlyForMySQL(t *testing.T) {
+	sqlStr := `SELECT 1 WHERE path = 'C:\' AND id = :id AND note <> E'it\'s :x'`
+	got, _, names, err := BindNamed(ormdriver.PostgresDialect{}, sqlStr, map[string]any{"id": 7})
+	if err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	if want := `SELECT 1 WHERE path = 'C:\' AND id = $1 AND note <> E'it\'s :x'`; got != want {
+		t.Fatalf("unexpected SQL:\n got %s\nwant %s", got, want)
+	}
+	if len(names) != 1 || names[0] != "id" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+
+	got, _, names, err = BindNamed(ormdriver.MySQLDialect{}, `SELECT 1 WHERE note = 'it\'s :x' AND id = :id`, map[string]any{"id": 7})
+	if err != nil {
+		t.Fatalf("bind mysql: %v", err)
+	}
+	if want := `SELECT 1 WHERE note = 'it\'s :x' AND id = ?`; got != want || len(names) != 1 {
+		t.Fatalf("unexpected MySQL SQL %s (%v)", got, names)
+	}
+
+	expr := NewExpr(`CONCAT(path, '\', ?)`, "x")
+	if sql, _, err := expr.Render(ormdriver.PostgresDialect{}, 1); err != nil || sql != `CONCAT(path, '\', $1)` {
+		t.Fatalf("render postgres = %s, %v", sql, err)
+	}
+}
+
+func TestWithInterceptorsVetoSkipsExecution(t *testing.T) {
+	exec := &recordingExec{}
+	veto := errors.New("vetoed")
+	var seen []OperationType
+	q := newPlanTestQuery(exec).WithInterceptors(func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+		seen = append(seen, plan.Operation)
+		return ExecResult{}, veto
+	})
+
+	if _, err := q.Where("id", 1).Update(map[string]any{"name": "bob"}); !errors.Is(err, veto) {
+		t.Fatalf("expected veto error, got %v", err)
+	}
+	if exec.calls != 0 {
+		t.Fatalf("expected no execution, got %d calls", exec.calls)
+	}
+	if len(seen) != 1 || seen[0] != OperationUpdate {
+		t.Fatalf("unexpected operations %v", seen)
+	}
+}
+
+func TestFormatAndAppendSQLComment(t *testing.T) {
+	comment := FormatSQLComment(map[string]string{
+		"route":  "/users/{id}",
+		"app":    "billing api",
+		"quote":  "it's",
+		"empty":  "",
+		"action": "list",
+	})
+	want := `/*action='list',app='billing+api',quote='it%27s',route='%2Fusers%2F%7Bid%7D'*/`
+	if comment != want {
+		t.Fatalf("comment = %s, want %s", comment, want)
+	}
+	if got := AppendSQLComment("SELECT 1;", comment); got != "SELECT 1 "+want+";" {
+		t.Fatalf("append with semicolon = %s", got)
+	}
+	if got := AppendSQLComment("SELECT 1 /* existing */", comment); got != "SELECT 1 /* existing */" {
+		t.Fatalf("expected SQL with a trailing comment unchanged, got %s", got)
+	}
+	if got := AppendSQLComment("SELECT 1 -- existing\n;", comment); got != "SELECT 1 -- existing\n;" {
+		t.Fatalf("expected SQL with a trailing line comment unchanged, got %s", got)
+	}
+	if got := AppendSQLComment("SELECT /*+ MAX_EXECUTION_TIME(100) */ id FROM t WHERE note = '-- not a comment'", comment); got != "SELECT /*+ MAX_EXECUTION_TIME(100) */ id FROM t WHERE note = '-- not a comment' "+want {
+		t.Fatalf("expected comments and -- inside literals to allow the append, got %s", got)
+	}
+	if got := AppendSQLComment("SELECT 'unterminated", comment); got != "SELECT 'unterminated" {
+		t.Fatalf("expected SQL ending inside a literal unchanged, got %s", got)
+	}
+	if got := AppendSQLComment("SELECT 1", ""); got != "SELECT 1" {
+		t.Fatalf("expected no comment, got %s", got)
+	}
+}
+
+func TestPlanFingerprintNormalizesSQLShape(t *testing.T) {
+	build := func(dialect ormdriver.Dialect, ids []any, limit int) *QueryPlan {
+		t.Helper()
+		plan, err := New(&recordingExec{}, "users", dialect).
+			Select("id", "name").
+			WhereIn("id", ids).
+			Where("status", "active").
+			Limit(limit).
+			Plan(context.Background())
+		if err != nil {
+			t.Fatalf("Plan: %v", err)
+		}
+		return plan
+	}
+	mysql := build(ormdriver.MySQLDialect{}, []any{1, 2}, 10)
+	postgres := build(ormdriver.PostgresDialect{}, []any{3, 4, 5, 6}, 50)
+	if mysql.Fingerprint == "" || !strings.HasPrefix(mysql.Fingerprint, "sha256:") {
+		t.Fatalf("fingerprint=%q", mysql.Fingerprint)
+	}
+	if mysql.Fingerprint != postgres.Fingerprint {
+		t.Fatalf("dialects differ:\n%s\n%s", NormalizeSQL(mysql.SQL), NormalizeSQL(postgres.SQL))
+	}
+
+	raw := NewRawPlan("select ID, name from users where id in (7, 8, 9) and status = 'active' /* app */ limit 5")
+	if got := NormalizeSQL(raw.SQL); got != NormalizeSQL(mysql.SQL) {
+		t.Fatalf("raw shape %q, builder shape %q", got, NormalizeSQL(mysql.SQL))
+	}
+
+	other := NewRawPlan("select id, name from accounts where id in (7, 8, 9) and status = 'active' limit 5")
+	if other.Fingerprint == raw.Fingerprint {
+		t.Fatal("different tables must not share a fingerprint")
+	}
+	del := NewRawPlan("DELETE FROM users WHERE id = ?")
+	del.Operation = OperationDelete
+	if PlanFingerprint(del) == del.Fingerprint {
+		t.Fatal("different operations must not share a fingerprint")
+	}
+
+	b, err := mysql.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"fingerprint": "`+mysql.Fingerprint+`"`) {
+		t.Fatalf("fingerprint missing from JSON: %s", b)
+	}
+}
+
+func TestNormalizeSQLCollapsesValuesRows(t *testing.T) {
+	one := NormalizeSQL("INSERT INTO `users` (`id`, `name`) VALUES (?, ?)")
+	many := NormalizeSQL(`INSERT INTO "users" ("id", "name") VALUES ($1, $2), ($3, $4), ($5, $6)`)
+	if one != many {
+		t.Fatalf("%q != %q", one, many)
+	}
+	if want := "insert into users ( id , name ) values (?+)"; one != want {
+		t.Fatalf("shape=%q, want %q", one, want)
+	}
+}
+
+func TestNormalizeSQLKeepsNegativeLiteralsWhole(t *testing.T) {
+	for _, sql := range []string{
+		"SELECT id FROM users WHERE balance = -1",
+		"SELECT id FROM users WHERE balance = 1",
+		"SELECT id FROM users WHERE balance = ?",
+	} {
+		if got, want := NormalizeSQL(sql), "select id from users where balance = ?"; got != want {
+			t.Fatalf("NormalizeSQL(%q)=%q, want %q", sql, got, want)
+		}
+	}
+	if got := NormalizeSQL("SELECT id FROM users WHERE id IN (-1, 2, -3.5)"); got != "select id from users where id in (?+)" {
+		t.Fatalf("negative IN list shape=%q", got)
+	}
+	if got, want := NormalizeSQL("SELECT balance - 1 FROM users"), "select balance - ? from users"; got != want {
+		t.Fatalf("subtraction shape=%q, want %q", got, want)
+	}
+}
+
+func TestAllowlistRoundTrip(t *testing.T) {
+	plan := NewRawPlan("SELECT id FROM users WHERE id = ?", 1)
+	a := NewAllowlist(NewAllowlistEntry(plan, "queries.sql:3 FindUser"), NewAllowlistEntry(NewRawPlan("select id from users where id = $1"), "dup"))
+	if got := len(a.Entries()); got != 1 {
+		t.Fatalf("expected same-shape entries to merge, got %d", got)
+	}
+	b, err := a.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseAllowlist(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Allows(plan.Fingerprint) || parsed.Allows(NewRawPlan("DELETE FROM users").Fingerprint) {
+		t.Fatalf("unexpected allowlist contents: %s", b)
+	}
+	if entry := parsed.Entries()[0]; entry.Source != "queries.sql:3 FindUser" || entry.SQL != "select id from users where id = ?" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if _, err := ParseAllowlist([]byte(`{"plans":[{"sql":"SELECT 1"}]}`)); err == nil {
+		t.Fatal("expected missing fingerprint to fail")
+	}
+}
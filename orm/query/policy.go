@@ -12,6 +12,7 @@ const (
 	WarningSoftDeleteFilterMissing = "SOFT_DELETE_FILTER_MISSING"
 	WarningPIIColumnSelected       = "PII_COLUMN_SELECTED"
 	WarningRequiredFilterMissing   = "REQUIRED_FILTER_MISSING"
+	WarningImmutableColumnUpdated  = "IMMUTABLE_COLUMN_UPDATED"
 )
 
 // PolicyMode controls how policy violations are represented in a QueryPlan.
@@ -34,6 +35,20 @@ type TablePolicy struct {
 	PIIMode               PolicyMode `json:"pii_mode,omitempty"`
 	RequiredFilterColumns []string   `json:"required_filter_columns,omitempty"`
 	RequiredFilterMode    PolicyMode `json:"required_filter_mode,omitempty"`
+	// ImmutableColumns, such as tenant_id, and InsertOnlyColumns, such as
+	// created_at, may be set by inserts but not by updates or the update
+	// part of upserts. Violations are reported at WriteMode.
+	ImmutableColumns  []string   `json:"immutable_columns,omitempty"`
+	InsertOnlyColumns []string   `json:"insert_only_columns,omitempty"`
+	WriteMode         PolicyMode `json:"write_mode,omitempty"`
+	// AllowedColumns maps a role to the columns it may read; a role with no
+	// columns may read every column. Once a table has rules, selects run as
+	// an Actor read only what the actor's roles allow, and selects without
+	// an actor read nothing unless AllowAnonymous is set. Violations are
+	// reported at AccessMode.
+	AllowedColumns map[string][]string `json:"allowed_columns,omitempty"`
+	AccessMode     PolicyMode          `json:"access_mode,omitempty"`
+	AllowAnonymous bool                `json:"allow_anonymous,omitempty"`
 }
 
 var policyRegistry = struct {
@@ -94,8 +109,21 @@ func normalizeTablePolicy(policy TablePolicy) TablePolicy {
 	policy.SoftDeleteMode = defaultPolicyMode(policy.SoftDeleteMode, PolicyModeEnforce)
 	policy.PIIMode = defaultPolicyMode(policy.PIIMode, PolicyModeWarn)
 	policy.RequiredFilterMode = defaultPolicyMode(policy.RequiredFilterMode, PolicyModeEnforce)
+	policy.WriteMode = defaultPolicyMode(policy.WriteMode, PolicyModeEnforce)
+	policy.AccessMode = defaultPolicyMode(policy.AccessMode, PolicyModeBlock)
 	policy.PIIColumns = normalizeColumns(policy.PIIColumns)
 	policy.RequiredFilterColumns = normalizeColumns(policy.RequiredFilterColumns)
+	policy.ImmutableColumns = normalizeColumns(policy.ImmutableColumns)
+	policy.InsertOnlyColumns = normalizeColumns(policy.InsertOnlyColumns)
+	if len(policy.AllowedColumns) > 0 {
+		allowed := make(map[string][]string, len(policy.AllowedColumns))
+		for role, cols := range policy.AllowedColumns {
+			if role = normalizeRole(role); role != "" {
+				allowed[role] = normalizeColumns(append(allowed[role], cols...))
+			}
+		}
+		policy.AllowedColumns = allowed
+	}
 	return policy
 }
 
@@ -129,9 +157,52 @@ func normalizeColumns(cols []string) []string {
 func cloneTablePolicy(policy TablePolicy) TablePolicy {
 	policy.PIIColumns = append([]string(nil), policy.PIIColumns...)
 	policy.RequiredFilterColumns = append([]string(nil), policy.RequiredFilterColumns...)
+	policy.ImmutableColumns = append([]string(nil), policy.ImmutableColumns...)
+	policy.InsertOnlyColumns = append([]string(nil), policy.InsertOnlyColumns...)
+	policy.AllowedColumns = cloneAllowedColumns(policy.AllowedColumns)
 	return policy
 }
 
+func cloneAllowedColumns(allowed map[string][]string) map[string][]string {
+	if allowed == nil {
+		return nil
+	}
+	out := make(map[string][]string, len(allowed))
+	for role, cols := range allowed {
+		out[role] = append([]string{}, cols...)
+	}
+	return out
+}
+
+// Writable reports whether updates and upserts may change column.
+func (p TablePolicy) Writable(column string) bool {
+	return writeProtection(&p, column) == ""
+}
+
+// ApplyWritePolicy adds IMMUTABLE_COLUMN_UPDATED warnings from the
+// registered policy of plan's table to an update or upsert plan built outside
+// Query, such as by generic CRUD helpers, and re-aggregates its risk. It
+// reports whether a warning was added.
+func ApplyWritePolicy(plan *QueryPlan) bool {
+	if plan == nil || len(plan.Tables) == 0 {
+		return false
+	}
+	policy, ok := PolicyForTable(plan.Tables[0].Name)
+	if !ok {
+		return false
+	}
+	warnings := writePolicyWarnings(plan, &policy)
+	if len(warnings) == 0 {
+		return false
+	}
+	plan.Warnings = append(plan.Warnings, warnings...)
+	level, blocked := aggregateWarnings(plan.Warnings)
+	plan.RiskLevel = level
+	plan.Blocked = plan.Blocked || blocked || level == RiskBlocked
+	plan.RequiredApproval = requiresApprovalLevel(level)
+	return true
+}
+
 func checkPolicy(plan *QueryPlan, policy *TablePolicy) []Warning {
 	if plan == nil || policy == nil || policy.Table == "" {
 		return nil
@@ -186,9 +257,72 @@ func checkPolicy(plan *QueryPlan, policy *TablePolicy) []Warning {
 			warnings = append(warnings, w)
 		}
 	}
+	warnings = append(warnings, writePolicyWarnings(plan, policy)...)
 	return warnings
 }
 
+// writePolicyWarnings reports immutable and insert-only columns changed by an
+// update, or by the update part of an upsert.
+func writePolicyWarnings(plan *QueryPlan, policy *TablePolicy) []Warning {
+	if len(policy.ImmutableColumns) == 0 && len(policy.InsertOnlyColumns) == 0 {
+		return nil
+	}
+	var warnings []Warning
+	for _, col := range updatedColumns(plan) {
+		kind := writeProtection(policy, col)
+		if kind == "" {
+			continue
+		}
+		w := policyWarning(
+			WarningImmutableColumnUpdated,
+			policyModeLevel(policy.WriteMode, RiskMedium),
+			fmt.Sprintf("%s column updated: %s.%s", strings.ReplaceAll(kind, "_", "-"), policy.Table, col),
+			"remove the column from the update; it may only be set on insert",
+			false,
+		)
+		w.Evidence = append(w.Evidence, Evidence{Key: "column", Value: col}, Evidence{Key: "policy", Value: kind})
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// updatedColumns returns the columns an update plan sets, or the
+// update_columns of an upsert plan.
+func updatedColumns(plan *QueryPlan) []string {
+	switch plan.Operation {
+	case OperationUpdate:
+		cols := make([]string, 0, len(plan.Columns))
+		for _, column := range plan.Columns {
+			if column.Name != "" {
+				cols = append(cols, column.Name)
+			}
+		}
+		return cols
+	case OperationInsert:
+		cols, _ := plan.Metadata["update_columns"].([]string)
+		return cols
+	default:
+		return nil
+	}
+}
+
+// writeProtection returns "immutable" or "insert_only" when policy protects
+// column from updates, and "" otherwise.
+func writeProtection(policy *TablePolicy, column string) string {
+	target := normalizeColumnName(column)
+	for _, col := range policy.ImmutableColumns {
+		if normalizeColumnName(col) == target {
+			return "immutable"
+		}
+	}
+	for _, col := range policy.InsertOnlyColumns {
+		if normalizeColumnName(col) == target {
+			return "insert_only"
+		}
+	}
+	return ""
+}
+
 func policyWarning(code string, level RiskLevel, message, hint string, suppressible bool) Warning {
 	return Warning{
 		Code:         code,
@@ -168,6 +168,49 @@ func TestSoftDeleteDefaultWithDeletedAndOnlyDeleted(t *testing.T) {
 	}
 }
 
+func TestCountAndPlanUpdateLeaveReceiverUnchanged(t *testing.T) {
+	registerUsersPolicy(t, TablePolicy{SoftDeleteColumn: "deleted_at"})
+
+	veto := errors.New("vetoed")
+	var countSQL string
+	base := newPolicyTestQuery(&recordingExec{}).
+		Select("id", "name").
+		Where("active", true).
+		OrderBy("id", "asc").
+		Limit(10).
+		Offset(20).
+		WithInterceptors(func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+			countSQL = plan.SQL
+			return ExecResult{}, veto
+		})
+	if _, err := base.Count(); !errors.Is(err, veto) {
+		t.Fatalf("Count error=%v", err)
+	}
+	want := "SELECT COUNT(*) FROM `users` WHERE `active` = ? AND `deleted_at` IS NULL ORDER BY `id` ASC"
+	if countSQL != want {
+		t.Fatalf("unexpected count SQL:\n got: %s\nwant: %s", countSQL, want)
+	}
+	plan, err := base.WithDeleted().Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if strings.Contains(plan.SQL, "deleted_at") || !strings.Contains(plan.SQL, "LIMIT 10 OFFSET 20") {
+		t.Fatalf("Count changed its receiver: %s", plan.SQL)
+	}
+
+	immutable := newPolicyTestQuery(&recordingExec{}).Where("id", 1).Immutable()
+	if _, err := immutable.PlanUpdate(context.Background(), map[string]any{"name": "bob"}); err != nil {
+		t.Fatalf("PlanUpdate: %v", err)
+	}
+	plan, err = immutable.WithDeleted().Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if strings.Contains(plan.SQL, "deleted_at") {
+		t.Fatalf("PlanUpdate changed its immutable receiver: %s", plan.SQL)
+	}
+}
+
 func TestPIIPolicyAndAccessReason(t *testing.T) {
 	registerUsersPolicy(t, TablePolicy{
 		TenantColumn: "tenant_id",
@@ -238,3 +281,333 @@ func TestRequiredFilterPolicy(t *testing.T) {
 		t.Fatalf("unexpected required filter warning=%#v", plan.Warnings)
 	}
 }
+
+func TestRedactParamsHidesPIIValues(t *testing.T) {
+	registerUsersPolicy(t, TablePolicy{PIIColumns: []string{"email"}})
+
+	plan, err := newPolicyTestQuery(&recordingExec{}).
+		Select("id").
+		Where("email", "alice@example.com").
+		Where("status", "active").
+		Limit(1).
+		Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	params := RedactParams(plan, false)
+	if len(params) != 2 || params[0] != RedactedParam || params[1] != "active" {
+		t.Fatalf("params=%#v", params)
+	}
+	if plan.Params[0] != "alice@example.com" {
+		t.Fatalf("plan params modified: %#v", plan.Params)
+	}
+
+	update, err := newPolicyTestQuery(&recordingExec{}).
+		Where("id", 1).
+		PlanUpdate(context.Background(), map[string]any{"email": "bob@example.com"})
+	if err != nil {
+		t.Fatalf("PlanUpdate: %v", err)
+	}
+	for _, p := range RedactParams(update, false) {
+		if p != RedactedParam {
+			t.Fatalf("expected all update params redacted, got %#v", RedactParams(update, false))
+		}
+	}
+
+	raw := NewRawPlan("SELECT * FROM sessions WHERE token = ?", "secret")
+	if got := RedactParams(raw, false); got[0] != RedactedParam {
+		t.Fatalf("raw params=%#v", got)
+	}
+	if got := RedactParams(raw, true); got[0] != "secret" {
+		t.Fatalf("opted-in raw params=%#v", got)
+	}
+}
+
+func TestWritePolicyImmutableAndInsertOnlyColumns(t *testing.T) {
+	registerUsersPolicy(t, TablePolicy{ImmutableColumns: []string{"tenant_id"}, InsertOnlyColumns: []string{"created_at"}})
+	ctx := context.Background()
+
+	plan, err := newPolicyTestQuery(&recordingExec{}).
+		Where("id", 1).
+		PlanUpdate(ctx, map[string]any{"tenant_id": 8, "name": "alice"})
+	if err != nil {
+		t.Fatalf("PlanUpdate: %v", err)
+	}
+	if !warningCodeSet(plan.Warnings)[WarningImmutableColumnUpdated] || plan.RiskLevel != RiskHigh || !plan.RequiredApproval {
+		t.Fatalf("risk=%s warnings=%#v", plan.RiskLevel, plan.Warnings)
+	}
+
+	plan, err = newPolicyTestQuery(&recordingExec{}).
+		Where("id", 1).
+		PlanUpdate(ctx, map[string]any{"name": "alice"})
+	if err != nil {
+		t.Fatalf("PlanUpdate without protected column: %v", err)
+	}
+	if warningCodeSet(plan.Warnings)[WarningImmutableColumnUpdated] {
+		t.Fatalf("unexpected write policy warning=%#v", plan.Warnings)
+	}
+
+	rows := []map[string]any{{"id": 1, "name": "alice", "created_at": "2026-01-01"}}
+	plan, err = newPolicyTestQuery(&recordingExec{}).planUpsert(ctx, rows, []string{"id"}, []string{"name", "created_at"})
+	if err != nil {
+		t.Fatalf("planUpsert: %v", err)
+	}
+	var found bool
+	for _, w := range plan.Warnings {
+		if w.Code == WarningImmutableColumnUpdated && strings.Contains(w.Message, "users.created_at") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("upsert warnings=%#v", plan.Warnings)
+	}
+
+	plan, err = newPolicyTestQuery(&recordingExec{}).planUpsert(ctx, rows, []string{"id"}, []string{"name"})
+	if err != nil {
+		t.Fatalf("planUpsert without protected column: %v", err)
+	}
+	if warningCodeSet(plan.Warnings)[WarningImmutableColumnUpdated] {
+		t.Fatalf("insert-only column set on insert should not warn: %#v", plan.Warnings)
+	}
+}
+
+func TestWritePolicyModes(t *testing.T) {
+	registerUsersPolicy(t, TablePolicy{ImmutableColumns: []string{"owner_id"}, WriteMode: PolicyModeWarn})
+
+	plan, err := newPolicyTestQuery(&recordingExec{}).
+		Where("id", 1).
+		PlanUpdate(context.Background(), map[string]any{"owner_id": 2})
+	if err != nil {
+		t.Fatalf("PlanUpdate: %v", err)
+	}
+	if !warningCodeSet(plan.Warnings)[WarningImmutableColumnUpdated] || plan.RiskLevel != RiskMedium || plan.RequiredApproval {
+		t.Fatalf("risk=%s required=%v warnings=%#v", plan.RiskLevel, plan.RequiredApproval, plan.Warnings)
+	}
+
+	registerUsersPolicy(t, TablePolicy{ImmutableColumns: []string{"owner_id"}, WriteMode: PolicyModeBlock})
+	exec := &recordingExec{}
+	_, err = newPolicyTestQuery(exec).Where("id", 1).Update(map[string]any{"owner_id": 2})
+	if !errors.Is(err, ErrBlockedOperation) {
+		t.Fatalf("Update error=%v", err)
+	}
+	if exec.calls != 0 {
+		t.Fatalf("blocked update executed database call count=%d", exec.calls)
+	}
+}
+
+func TestApplyWritePolicy(t *testing.T) {
+	registerUsersPolicy(t, TablePolicy{ImmutableColumns: []string{"tenant_id"}})
+
+	plan := &QueryPlan{
+		Operation: OperationUpdate,
+		SQL:       "UPDATE users SET tenant_id=? WHERE id=?",
+		Tables:    []TableRef{{Name: "users"}},
+		Columns:   []ColumnRef{{Name: "tenant_id"}},
+		RiskLevel: RiskLow,
+	}
+	if !ApplyWritePolicy(plan) {
+		t.Fatalf("expected write policy warning, got %#v", plan.Warnings)
+	}
+	if plan.RiskLevel != RiskHigh || !plan.RequiredApproval {
+		t.Fatalf("risk=%s required=%v", plan.RiskLevel, plan.RequiredApproval)
+	}
+
+	plan = &QueryPlan{Operation: OperationUpdate, Tables: []TableRef{{Name: "users"}}, Columns: []ColumnRef{{Name: "name"}}, RiskLevel: RiskLow}
+	if ApplyWritePolicy(plan) || len(plan.Warnings) != 0 {
+		t.Fatalf("unexpected warnings=%#v", plan.Warnings)
+	}
+	policy, _ := PolicyForTable("users")
+	if policy.Writable("tenant_id") || !policy.Writable("name") {
+		t.Fatalf("writable: tenant_id=%v name=%v", policy.Writable("tenant_id"), policy.Writable("name"))
+	}
+}
+
+func TestActorColumnAccessPolicy(t *testing.T) {
+	registerUsersPolicy(t, TablePolicy{AllowedColumns: map[string][]string{
+		"Support": {"id", "email"},
+		"admin":   nil,
+	}})
+	ctx := context.Background()
+	support := Actor{ID: "u1", Roles: []string{"support"}}
+
+	plan, err := newPolicyTestQuery(&recordingExec{}).As(support).Select("id", "users.email").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	codes := warningCodeSet(plan.Warnings)
+	if codes[WarningColumnAccessDenied] || codes[WarningTableAccessDenied] {
+		t.Fatalf("unexpected access warnings=%#v", plan.Warnings)
+	}
+	if actor, ok := PlanActor(plan); !ok || actor.ID != "u1" || len(actor.Roles) != 1 || actor.Roles[0] != "support" {
+		t.Fatalf("plan actor=%#v ok=%v metadata=%#v", actor, ok, plan.Metadata)
+	}
+
+	plan, err = newPolicyTestQuery(&recordingExec{}).As(support).Select("id", "ssn").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan denied column: %v", err)
+	}
+	if !warningCodeSet(plan.Warnings)[WarningColumnAccessDenied] || !plan.Blocked {
+		t.Fatalf("blocked=%v warnings=%#v", plan.Blocked, plan.Warnings)
+	}
+
+	plan, err = New(&recordingExec{}, "users as u", ormdriver.MySQLDialect{}).As(support).Select("u.id", "u.ssn").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan aliased: %v", err)
+	}
+	if !warningCodeSet(plan.Warnings)[WarningColumnAccessDenied] {
+		t.Fatalf("aliased warnings=%#v", plan.Warnings)
+	}
+
+	plan, err = newPolicyTestQuery(&recordingExec{}).As(support).Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan select star: %v", err)
+	}
+	if !warningCodeSet(plan.Warnings)[WarningColumnAccessDenied] {
+		t.Fatalf("select * warnings=%#v", plan.Warnings)
+	}
+
+	plan, err = newPolicyTestQuery(&recordingExec{}).As(Actor{Roles: []string{"guest"}}).Select("id").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan guest: %v", err)
+	}
+	if !warningCodeSet(plan.Warnings)[WarningTableAccessDenied] {
+		t.Fatalf("guest warnings=%#v", plan.Warnings)
+	}
+
+	plan, err = newPolicyTestQuery(&recordingExec{}).As(Actor{ID: "root", Roles: []string{"support", "admin"}}).Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan admin: %v", err)
+	}
+	if codes := warningCodeSet(plan.Warnings); codes[WarningColumnAccessDenied] || codes[WarningTableAccessDenied] {
+		t.Fatalf("admin warnings=%#v", plan.Warnings)
+	}
+
+	plan, err = newPolicyTestQuery(&recordingExec{}).Select("ssn").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan without actor: %v", err)
+	}
+	if warningCodeSet(plan.Warnings)[WarningColumnAccessDenied] {
+		t.Fatalf("plans without an actor are not checked: %#v", plan.Warnings)
+	}
+}
+
+func TestActorColumnAccessPolicyCoversJoinsFiltersAndOrders(t *testing.T) {
+	registerUsersPolicy(t, TablePolicy{
+		SoftDeleteColumn: "deleted_at",
+		AllowedColumns:   map[string][]string{"support": {"id", "name"}},
+	})
+	ctx := context.Background()
+	support := Actor{ID: "u1", Roles: []string{"support"}}
+	denied := func(plan *QueryPlan) map[string]bool {
+		cols := make(map[string]bool)
+		for _, w := range plan.Warnings {
+			if w.Code != WarningColumnAccessDenied {
+				continue
+			}
+			for _, e := range w.Evidence {
+				if e.Key == "column" {
+					cols[e.Value.(string)] = true
+				}
+			}
+		}
+		return cols
+	}
+
+	plan, err := New(&recordingExec{}, "orders", ormdriver.MySQLDialect{}).As(support).
+		Join("users", "users.id", "=", "orders.user_id").
+		Select("orders.id", "users.email").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan join: %v", err)
+	}
+	if cols := denied(plan); !cols["email"] || len(cols) != 1 || !plan.Blocked {
+		t.Fatalf("expected users.email denied through the join, got %v warnings=%#v", cols, plan.Warnings)
+	}
+
+	plan, err = New(&recordingExec{}, "orders", ormdriver.MySQLDialect{}).As(support).
+		Join("users", "users.id", "=", "orders.user_id").
+		Select("orders.id", "orders.email", "users.name").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan allowed join: %v", err)
+	}
+	if cols := denied(plan); len(cols) != 0 {
+		t.Fatalf("orders columns and allowed users columns must pass, got %v", cols)
+	}
+
+	plan, err = New(&recordingExec{}, "orders", ormdriver.MySQLDialect{}).As(support).
+		Join("users", "users.id", "=", "orders.user_id").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan join select star: %v", err)
+	}
+	if cols := denied(plan); !cols["*"] {
+		t.Fatalf("SELECT * over a joined restricted table must be denied, got %v", cols)
+	}
+
+	plan, err = newPolicyTestQuery(&recordingExec{}).As(support).Select("id").
+		Where("ssn", "123").OrderBy("email", "asc").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan filter and order: %v", err)
+	}
+	if cols := denied(plan); !cols["ssn"] || !cols["email"] {
+		t.Fatalf("expected filtered and sorted columns denied, got %v", cols)
+	}
+
+	plan, err = newPolicyTestQuery(&recordingExec{}).As(support).Select("id").Where("name", "alice").OrderBy("id", "desc").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan allowed filter: %v", err)
+	}
+	if cols := denied(plan); len(cols) != 0 {
+		t.Fatalf("allowed filters and the soft-delete predicate must pass, got %v", cols)
+	}
+}
+
+func TestColumnAccessPolicyDeniesQueriesWithoutActor(t *testing.T) {
+	registerUsersPolicy(t, TablePolicy{AllowedColumns: map[string][]string{"support": {"id"}}})
+	exec := &recordingExec{}
+	err := newPolicyTestQuery(exec).Select("email").Limit(10).GetMaps(&[]map[string]any{})
+	if !errors.Is(err, ErrBlockedOperation) || !strings.Contains(err.Error(), WarningTableAccessDenied) {
+		t.Fatalf("anonymous GetMaps error=%v", err)
+	}
+	if exec.calls != 0 {
+		t.Fatalf("anonymous query executed database call count=%d", exec.calls)
+	}
+
+	registerUsersPolicy(t, TablePolicy{AllowedColumns: map[string][]string{"support": {"id"}}, AllowAnonymous: true})
+	plan, err := newPolicyTestQuery(exec).Select("email").Limit(10).Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if warningCodeSet(plan.Warnings)[WarningTableAccessDenied] {
+		t.Fatalf("AllowAnonymous must let queries without an actor read the table: %#v", plan.Warnings)
+	}
+}
+
+func TestActorFromContextBlocksExecution(t *testing.T) {
+	registerUsersPolicy(t, TablePolicy{AllowedColumns: map[string][]string{"support": {"id"}}})
+	ctx := WithActor(context.Background(), Actor{ID: "u2", Roles: []string{"support"}})
+
+	exec := &recordingExec{}
+	err := newPolicyTestQuery(exec).WithContext(ctx).Select("id", "email").Limit(10).GetMaps(&[]map[string]any{})
+	if !errors.Is(err, ErrBlockedOperation) {
+		t.Fatalf("GetMaps error=%v", err)
+	}
+	if exec.calls != 0 {
+		t.Fatalf("blocked query executed database call count=%d", exec.calls)
+	}
+
+	plan, err := newPolicyTestQuery(exec).Select("id").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if plan.Metadata["actor"] != "u2" || warningCodeSet(plan.Warnings)[WarningColumnAccessDenied] {
+		t.Fatalf("metadata=%#v warnings=%#v", plan.Metadata, plan.Warnings)
+	}
+
+	registerUsersPolicy(t, TablePolicy{AllowedColumns: map[string][]string{"support": {"id"}}, AccessMode: PolicyModeWarn})
+	plan, err = newPolicyTestQuery(exec).Select("email").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan warn mode: %v", err)
+	}
+	if !warningCodeSet(plan.Warnings)[WarningColumnAccessDenied] || plan.Blocked || plan.RiskLevel != RiskMedium {
+		t.Fatalf("risk=%s blocked=%v warnings=%#v", plan.RiskLevel, plan.Blocked, plan.Warnings)
+	}
+}
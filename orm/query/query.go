@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"fmt"
 	"reflect"
@@ -14,6 +15,7 @@ import (
 	qbpostgres "github.com/faciam-dev/goquent-query-builder/database/postgres"
 	"github.com/faciam-dev/goquent/orm/conv"
 	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/internal/codec"
 	"github.com/faciam-dev/goquent/orm/scanner"
 )
 
@@ -46,9 +48,19 @@ type Query struct {
 	suppressions  []Suppression
 	policy        *TablePolicy
 	accessReason  string
+	actor         *Actor
 	withDeleted   bool
 	onlyDeleted   bool
 	policyApplied bool
+	lock          *lockState
+	generatedRefs map[string]generatedRef
+	arrayIn       bool
+	immutable     bool
+	interceptors  []Interceptor
+	commenter     SQLCommenter
+	approvals     *ApprovalRegistry
+	trustedKeys   []ed25519.PublicKey
+	riskEngine    RiskEngine
 }
 
 // CursorColumn describes an ordered column used by keyset cursor predicates.
@@ -123,6 +135,7 @@ func newDeleteBuilder(d driver.Dialect) *qbapi.DeleteQueryBuilder {
 
 // PrimaryKey sets the primary key column for the table.
 func (q *Query) PrimaryKey(col string) *Query {
+	q = q.derive()
 	q.primaryKey = col
 	return q
 }
@@ -136,12 +149,14 @@ func (q *Query) getPrimaryKeyColumn() string {
 
 // WithContext sets ctx on the query for context-aware execution.
 func (q *Query) WithContext(ctx context.Context) *Query {
+	q = q.derive()
 	q.ctx = ctx
 	return q
 }
 
 // RequireApproval records an explicit reason for executing a risky query.
 func (q *Query) RequireApproval(reason string) *Query {
+	q = q.derive()
 	reason = strings.TrimSpace(reason)
 	if reason == "" {
 		q.err = ErrApprovalReasonRequired
@@ -153,6 +168,7 @@ func (q *Query) RequireApproval(reason string) *Query {
 
 // SuppressWarning suppresses a suppressible warning for this query plan.
 func (q *Query) SuppressWarning(code, reason string, opts ...SuppressionOption) *Query {
+	q = q.derive()
 	s, err := NewSuppression(code, reason, opts...)
 	if err != nil {
 		q.err = err
@@ -162,8 +178,25 @@ func (q *Query) SuppressWarning(code, reason string, opts ...SuppressionOption)
 	return q
 }
 
+// WithSuppressions adds already validated suppressions, such as those from a
+// config file, to this query's plans.
+func (q *Query) WithSuppressions(suppressions ...Suppression) *Query {
+	q = q.derive()
+	q.suppressions = append(q.suppressions, suppressions...)
+	return q
+}
+
+// WithRiskEngine evaluates this query's plans with engine instead of
+// DefaultRiskEngine.
+func (q *Query) WithRiskEngine(engine RiskEngine) *Query {
+	q = q.derive()
+	q.riskEngine = engine
+	return q
+}
+
 // AccessReason records why this query needs access to sensitive columns.
 func (q *Query) AccessReason(reason string) *Query {
+	q = q.derive()
 	reason = strings.TrimSpace(reason)
 	if reason == "" {
 		q.err = ErrAccessReasonRequired
@@ -175,6 +208,7 @@ func (q *Query) AccessReason(reason string) *Query {
 
 // WithDeleted disables the default soft-delete filter for a policy table.
 func (q *Query) WithDeleted() *Query {
+	q = q.derive()
 	q.withDeleted = true
 	q.onlyDeleted = false
 	return q
@@ -182,17 +216,22 @@ func (q *Query) WithDeleted() *Query {
 
 // OnlyDeleted restricts a soft-delete policy table to deleted rows.
 func (q *Query) OnlyDeleted() *Query {
+	q = q.derive()
 	q.onlyDeleted = true
 	q.withDeleted = false
 	return q
 }
 
-func (q *Query) finalizePlan(plan *QueryPlan) {
+func (q *Query) finalizePlan(ctx context.Context, plan *QueryPlan) {
 	if plan == nil {
 		return
 	}
+	q.annotateGeneratedRefs(plan)
 	q.applyPolicyMetadata(plan)
-	finalizePlanWithPolicy(plan, q.approval, q.suppressions, q.policy)
+	if actor, ok := q.actorFor(ctx); ok {
+		setPlanActor(plan, actor)
+	}
+	finalizePlanWith(plan, q.riskEngine, q.approval, q.suppressions, q.policy)
 }
 
 func (q *Query) applyPolicyPredicates() {
@@ -231,31 +270,15 @@ func (q *Query) applyPolicyMetadata(plan *QueryPlan) {
 	}
 }
 
-// queryRows executes Query or QueryContext based on whether ctx is set.
-func (q *Query) queryRows(sqlStr string, args ...any) (*sql.Rows, error) {
-	if q.ctx != nil {
-		return q.exec.QueryContext(q.ctx, sqlStr, args...)
-	}
-	return q.exec.Query(sqlStr, args...)
-}
-
-func (q *Query) queryRow(sqlStr string, args ...any) *sql.Row {
-	if q.ctx != nil {
-		return q.exec.QueryRowContext(q.ctx, sqlStr, args...)
-	}
-	return q.exec.QueryRow(sqlStr, args...)
-}
-
-// execStmt executes Exec or ExecContext depending on ctx.
-func (q *Query) execStmt(sqlStr string, args ...any) (sql.Result, error) {
-	if q.ctx != nil {
-		return q.exec.ExecContext(q.ctx, sqlStr, args...)
-	}
-	return q.exec.Exec(sqlStr, args...)
+// bindArgs encodes registered codec types and Postgres array values just
+// before execution so plans keep the caller's values.
+func (q *Query) bindArgs(args []any) []any {
+	return BindArrayArgs(q.dialect, codec.WrapArgs(args, q.dialect))
 }
 
 // Select sets selected identifier columns. Use SelectRaw for SQL expressions.
 func (q *Query) Select(cols ...string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -273,6 +296,7 @@ func (q *Query) Select(cols ...string) *Query {
 // Values are always treated as literals. Use WhereColumn for
 // column-to-column comparisons.
 func (q *Query) Where(col string, args ...any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -299,11 +323,13 @@ func (q *Query) Where(col string, args ...any) *Query {
 
 // WhereCursorAfter adds a keyset pagination predicate after the given cursor.
 func (q *Query) WhereCursorAfter(columns []CursorColumn, values ...any) *Query {
+	q = q.derive()
 	return q.whereCursor(columns, values, true)
 }
 
 // WhereCursorBefore adds a keyset pagination predicate before the given cursor.
 func (q *Query) WhereCursorBefore(columns []CursorColumn, values ...any) *Query {
+	q = q.derive()
 	return q.whereCursor(columns, values, false)
 }
 
@@ -345,15 +371,12 @@ func (q *Query) First(dest any) error {
 	if err != nil {
 		return err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return err
-	}
-	rows, err := q.queryRows(plan.SQL, plan.Params...)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
-	return scanner.Struct(dest, rows)
+	return q.queryPlan(plan, func(rows *sql.Rows) (int64, error) {
+		if err := scanner.StructDialect(q.dialect, dest, rows); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	})
 }
 
 // FirstMap scans first row into map.
@@ -362,20 +385,14 @@ func (q *Query) FirstMap(dest *map[string]any) error {
 	if err != nil {
 		return err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return err
-	}
-	rows, err := q.queryRows(plan.SQL, plan.Params...)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
-	m, err := scanner.Map(rows)
-	if err != nil {
-		return err
-	}
-	*dest = m
-	return nil
+	return q.queryPlan(plan, func(rows *sql.Rows) (int64, error) {
+		m, err := scanner.Map(rows)
+		if err != nil {
+			return 0, err
+		}
+		*dest = m
+		return 1, nil
+	})
 }
 
 // GetMaps scans all rows into slice of maps.
@@ -384,20 +401,14 @@ func (q *Query) GetMaps(dest *[]map[string]any) error {
 	if err != nil {
 		return err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return err
-	}
-	rows, err := q.queryRows(plan.SQL, plan.Params...)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
-	m, err := scanner.Maps(rows)
-	if err != nil {
-		return err
-	}
-	*dest = m
-	return nil
+	return q.queryPlan(plan, func(rows *sql.Rows) (int64, error) {
+		m, err := scanner.Maps(rows)
+		if err != nil {
+			return 0, err
+		}
+		*dest = m
+		return int64(len(m)), nil
+	})
 }
 
 // Get scans all rows into the slice pointed to by dest.
@@ -406,31 +417,31 @@ func (q *Query) Get(dest any) error {
 	if err != nil {
 		return err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return err
-	}
-	rows, err := q.queryRows(plan.SQL, plan.Params...)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
-	return scanner.Structs(dest, rows)
+	return q.queryPlan(plan, func(rows *sql.Rows) (int64, error) {
+		if err := scanner.StructsDialect(q.dialect, dest, rows); err != nil {
+			return 0, err
+		}
+		return int64(reflect.Indirect(reflect.ValueOf(dest)).Len()), nil
+	})
 }
 
 // Limit sets a limit.
 func (q *Query) Limit(n int) *Query {
+	q = q.derive()
 	q.builder.Limit(int64(n))
 	return q
 }
 
 // Offset sets offset.
 func (q *Query) Offset(n int) *Query {
+	q = q.derive()
 	q.builder.Offset(int64(n))
 	return q
 }
 
 // SelectRaw adds a raw select expression.
 func (q *Query) SelectRaw(raw string, values ...any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -443,73 +454,63 @@ func (q *Query) SelectRaw(raw string, values ...any) *Query {
 }
 
 // Count executes a COUNT query using the current conditions and returns the
-// resulting row count.
+// resulting row count. The receiver is left unchanged.
 func (q *Query) Count(cols ...string) (int64, error) {
 	if q.err != nil {
 		return 0, q.err
 	}
-	q.applyPolicyPredicates()
-
-	b := newSelectBuilder(q.dialect)
-	b.Table(q.builder.GetQuery().Table.Name)
-	if err := copySelectBuilderState(q.builder, b); err != nil {
-		return 0, err
-	}
-	b.Count(cols...)
+	c := q.Clone()
+	c.applyPolicyPredicates()
+	c.builder = countSelectBuilder(c.builder)
+	c.builder.Count(cols...)
 
-	plan, err := q.planSelectBuilder(q.ctx, b)
+	plan, err := c.Plan(c.ctx)
 	if err != nil {
 		return 0, err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return 0, err
-	}
-
-	var row *sql.Row
-	if q.ctx != nil {
-		row = q.exec.QueryRowContext(q.ctx, plan.SQL, plan.Params...)
-	} else {
-		row = q.exec.QueryRow(plan.SQL, plan.Params...)
-	}
-	var c int64
-	if err := row.Scan(&c); err != nil {
+	var n int64
+	if err := c.queryPlan(plan, scanSingleRow(&n)); err != nil {
 		return 0, err
 	}
-	return c, nil
+	return n, nil
 }
 
 // Distinct marks columns as DISTINCT.
 func (q *Query) Distinct(cols ...string) *Query {
+	q = q.derive()
 	q.builder.Distinct(cols...)
 	return q
 }
 
 // Union adds a UNION with another query.
 func (q *Query) Union(sub *Query) *Query {
+	q = q.derive()
 	q.builder.Union(sub.builder)
 	return q
 }
 
 // UnionAll adds a UNION ALL with another query.
 func (q *Query) UnionAll(sub *Query) *Query {
+	q = q.derive()
 	q.builder.UnionAll(sub.builder)
 	return q
 }
 
 // Max adds MAX aggregate function.
-func (q *Query) Max(col string) *Query { q.builder.Max(col); return q }
+func (q *Query) Max(col string) *Query { q = q.derive(); q.builder.Max(col); return q }
 
 // Min adds MIN aggregate function.
-func (q *Query) Min(col string) *Query { q.builder.Min(col); return q }
+func (q *Query) Min(col string) *Query { q = q.derive(); q.builder.Min(col); return q }
 
 // Sum adds SUM aggregate function.
-func (q *Query) Sum(col string) *Query { q.builder.Sum(col); return q }
+func (q *Query) Sum(col string) *Query { q = q.derive(); q.builder.Sum(col); return q }
 
 // Avg adds AVG aggregate function.
-func (q *Query) Avg(col string) *Query { q.builder.Avg(col); return q }
+func (q *Query) Avg(col string) *Query { q = q.derive(); q.builder.Avg(col); return q }
 
 // Join adds INNER JOIN clause.
 func (q *Query) Join(table, localColumn, cond, target string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -524,24 +525,28 @@ func (q *Query) Join(table, localColumn, cond, target string) *Query {
 
 // JoinQuery adds a JOIN with additional ON/WHERE clauses defined in the callback.
 func (q *Query) JoinQuery(table string, fn func(b *qbapi.JoinClauseQueryBuilder)) *Query {
+	q = q.derive()
 	q.builder.JoinQuery(table, func(b *qbapi.JoinClauseQueryBuilder) { fn(b) })
 	return q
 }
 
 // LeftJoinQuery adds a LEFT JOIN with additional clauses defined in the callback.
 func (q *Query) LeftJoinQuery(table string, fn func(b *qbapi.JoinClauseQueryBuilder)) *Query {
+	q = q.derive()
 	q.builder.LeftJoinQuery(table, func(b *qbapi.JoinClauseQueryBuilder) { fn(b) })
 	return q
 }
 
 // RightJoinQuery adds a RIGHT JOIN with additional clauses defined in the callback.
 func (q *Query) RightJoinQuery(table string, fn func(b *qbapi.JoinClauseQueryBuilder)) *Query {
+	q = q.derive()
 	q.builder.RightJoinQuery(table, func(b *qbapi.JoinClauseQueryBuilder) { fn(b) })
 	return q
 }
 
 // JoinSubQuery joins a subquery with alias and join condition.
 func (q *Query) JoinSubQuery(sub *Query, alias, my, condition, target string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -556,6 +561,7 @@ func (q *Query) JoinSubQuery(sub *Query, alias, my, condition, target string) *Q
 
 // LeftJoinSubQuery performs a LEFT JOIN using a subquery.
 func (q *Query) LeftJoinSubQuery(sub *Query, alias, my, condition, target string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -570,6 +576,7 @@ func (q *Query) LeftJoinSubQuery(sub *Query, alias, my, condition, target string
 
 // RightJoinSubQuery performs a RIGHT JOIN using a subquery.
 func (q *Query) RightJoinSubQuery(sub *Query, alias, my, condition, target string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -584,18 +591,21 @@ func (q *Query) RightJoinSubQuery(sub *Query, alias, my, condition, target strin
 
 // JoinLateral performs a LATERAL JOIN using a subquery.
 func (q *Query) JoinLateral(sub *Query, alias string) *Query {
+	q = q.derive()
 	q.builder.JoinLateral(sub.builder, alias)
 	return q
 }
 
 // LeftJoinLateral performs a LEFT LATERAL JOIN using a subquery.
 func (q *Query) LeftJoinLateral(sub *Query, alias string) *Query {
+	q = q.derive()
 	q.builder.LeftJoinLateral(sub.builder, alias)
 	return q
 }
 
 // LeftJoin adds LEFT JOIN clause.
 func (q *Query) LeftJoin(table, localColumn, cond, target string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -610,6 +620,7 @@ func (q *Query) LeftJoin(table, localColumn, cond, target string) *Query {
 
 // RightJoin adds RIGHT JOIN clause.
 func (q *Query) RightJoin(table, localColumn, cond, target string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -624,12 +635,14 @@ func (q *Query) RightJoin(table, localColumn, cond, target string) *Query {
 
 // CrossJoin adds CROSS JOIN clause.
 func (q *Query) CrossJoin(table string) *Query {
+	q = q.derive()
 	q.builder.CrossJoin(table)
 	return q
 }
 
 // OrderBy adds ORDER BY clause.
 func (q *Query) OrderBy(col, dir string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -644,6 +657,7 @@ func (q *Query) OrderBy(col, dir string) *Query {
 
 // OrderByRaw adds raw ORDER BY clause.
 func (q *Query) OrderByRaw(raw string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -657,18 +671,21 @@ func (q *Query) OrderByRaw(raw string) *Query {
 
 // ReOrder clears ORDER BY clauses.
 func (q *Query) ReOrder() *Query {
+	q = q.derive()
 	q.builder.ReOrder()
 	return q
 }
 
 // GroupBy adds GROUP BY clause.
 func (q *Query) GroupBy(cols ...string) *Query {
+	q = q.derive()
 	q.builder.GroupBy(cols...)
 	return q
 }
 
 // Having adds HAVING condition.
 func (q *Query) Having(col, cond string, val any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -683,6 +700,7 @@ func (q *Query) Having(col, cond string, val any) *Query {
 
 // HavingRaw adds raw HAVING condition.
 func (q *Query) HavingRaw(raw string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -696,6 +714,7 @@ func (q *Query) HavingRaw(raw string) *Query {
 
 // OrHaving adds OR HAVING condition.
 func (q *Query) OrHaving(col, cond string, val any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -710,6 +729,7 @@ func (q *Query) OrHaving(col, cond string, val any) *Query {
 
 // OrHavingRaw adds raw OR HAVING condition.
 func (q *Query) OrHavingRaw(raw string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -723,6 +743,7 @@ func (q *Query) OrHavingRaw(raw string) *Query {
 
 // OrWhere appends OR condition.
 func (q *Query) OrWhere(col string, args ...any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -749,6 +770,7 @@ func (q *Query) OrWhere(col string, args ...any) *Query {
 
 // WhereRaw appends raw WHERE condition.
 func (q *Query) WhereRaw(raw string, vals map[string]any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -762,11 +784,13 @@ func (q *Query) WhereRaw(raw string, vals map[string]any) *Query {
 
 // WhereRawNoArgs appends a raw WHERE condition that has no placeholders.
 func (q *Query) WhereRawNoArgs(raw string) *Query {
+	q = q.derive()
 	return q.WhereRaw(raw, map[string]any{})
 }
 
 // OrWhereRaw appends raw OR WHERE condition.
 func (q *Query) OrWhereRaw(raw string, vals map[string]any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -780,11 +804,13 @@ func (q *Query) OrWhereRaw(raw string, vals map[string]any) *Query {
 
 // OrWhereRawNoArgs appends a raw OR WHERE condition that has no placeholders.
 func (q *Query) OrWhereRawNoArgs(raw string) *Query {
+	q = q.derive()
 	return q.OrWhereRaw(raw, map[string]any{})
 }
 
 // SafeWhereRaw appends a raw WHERE condition ensuring a values map is always used.
 func (q *Query) SafeWhereRaw(raw string, vals map[string]any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -798,6 +824,7 @@ func (q *Query) SafeWhereRaw(raw string, vals map[string]any) *Query {
 
 // SafeOrWhereRaw appends a raw OR WHERE condition ensuring a values map is used.
 func (q *Query) SafeOrWhereRaw(raw string, vals map[string]any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -809,14 +836,26 @@ func (q *Query) SafeOrWhereRaw(raw string, vals map[string]any) *Query {
 	return q
 }
 
+// groupQuery returns the query passed to WhereGroup-style callbacks. It
+// shares the dialect, array binding mode and generated predicate metadata of
+// q so that dialect-specific helpers behave the same inside the group.
+func (q *Query) groupQuery(b *qbapi.WhereSelectQueryBuilder) *Query {
+	if q.generatedRefs == nil {
+		q.generatedRefs = make(map[string]generatedRef)
+	}
+	grp := &Query{builder: q.builder, exec: q.exec, ctx: q.ctx, dialect: q.dialect, arrayIn: q.arrayIn, generatedRefs: q.generatedRefs}
+	_ = setFieldValue(reflect.ValueOf(&grp.builder.WhereQueryBuilder), "builder", reflect.ValueOf(b.GetBuilder()))
+	return grp
+}
+
 // WhereGroup groups conditions with parentheses using AND logic.
 func (q *Query) WhereGroup(fn func(g *Query)) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
 	q.builder.WhereGroup(func(b *qbapi.WhereSelectQueryBuilder) {
-		grp := &Query{builder: q.builder, exec: q.exec, ctx: q.ctx}
-		_ = setFieldValue(reflect.ValueOf(&grp.builder.WhereQueryBuilder), "builder", reflect.ValueOf(b.GetBuilder()))
+		grp := q.groupQuery(b)
 		fn(grp)
 		if grp.err != nil {
 			q.err = grp.err
@@ -827,12 +866,12 @@ func (q *Query) WhereGroup(fn func(g *Query)) *Query {
 
 // OrWhereGroup groups conditions with parentheses using OR logic.
 func (q *Query) OrWhereGroup(fn func(g *Query)) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
 	q.builder.OrWhereGroup(func(b *qbapi.WhereSelectQueryBuilder) {
-		grp := &Query{builder: q.builder, exec: q.exec, ctx: q.ctx}
-		_ = setFieldValue(reflect.ValueOf(&grp.builder.WhereQueryBuilder), "builder", reflect.ValueOf(b.GetBuilder()))
+		grp := q.groupQuery(b)
 		fn(grp)
 		if grp.err != nil {
 			q.err = grp.err
@@ -843,12 +882,12 @@ func (q *Query) OrWhereGroup(fn func(g *Query)) *Query {
 
 // WhereNot groups conditions inside NOT (...).
 func (q *Query) WhereNot(fn func(g *Query)) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
 	q.builder.WhereNot(func(b *qbapi.WhereSelectQueryBuilder) {
-		grp := &Query{builder: q.builder, exec: q.exec, ctx: q.ctx}
-		_ = setFieldValue(reflect.ValueOf(&grp.builder.WhereQueryBuilder), "builder", reflect.ValueOf(b.GetBuilder()))
+		grp := q.groupQuery(b)
 		fn(grp)
 		if grp.err != nil {
 			q.err = grp.err
@@ -859,12 +898,12 @@ func (q *Query) WhereNot(fn func(g *Query)) *Query {
 
 // OrWhereNot groups conditions inside OR NOT (...).
 func (q *Query) OrWhereNot(fn func(g *Query)) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
 	q.builder.OrWhereNot(func(b *qbapi.WhereSelectQueryBuilder) {
-		grp := &Query{builder: q.builder, exec: q.exec, ctx: q.ctx}
-		_ = setFieldValue(reflect.ValueOf(&grp.builder.WhereQueryBuilder), "builder", reflect.ValueOf(b.GetBuilder()))
+		grp := q.groupQuery(b)
 		fn(grp)
 		if grp.err != nil {
 			q.err = grp.err
@@ -875,54 +914,75 @@ func (q *Query) OrWhereNot(fn func(g *Query)) *Query {
 
 // WhereIn adds WHERE IN condition.
 func (q *Query) WhereIn(col string, vals any) *Query {
+	q = q.derive()
+	if q.whereArrayIn(col, vals, false, false) {
+		return q
+	}
 	q.builder.WhereIn(col, vals)
 	return q
 }
 
 // WhereNotIn adds WHERE NOT IN condition.
 func (q *Query) WhereNotIn(col string, vals any) *Query {
+	q = q.derive()
+	if q.whereArrayIn(col, vals, true, false) {
+		return q
+	}
 	q.builder.WhereNotIn(col, vals)
 	return q
 }
 
 // OrWhereIn adds OR WHERE IN condition.
 func (q *Query) OrWhereIn(col string, vals any) *Query {
+	q = q.derive()
+	if q.whereArrayIn(col, vals, false, true) {
+		return q
+	}
 	q.builder.OrWhereIn(col, vals)
 	return q
 }
 
 // OrWhereNotIn adds OR WHERE NOT IN condition.
 func (q *Query) OrWhereNotIn(col string, vals any) *Query {
+	q = q.derive()
+	if q.whereArrayIn(col, vals, true, true) {
+		return q
+	}
 	q.builder.OrWhereNotIn(col, vals)
 	return q
 }
 
 // WhereInSubQuery adds WHERE IN (subquery) condition.
 func (q *Query) WhereInSubQuery(col string, sub *Query) *Query {
+	q = q.derive()
 	q.builder.WhereInSubQuery(col, sub.builder)
 	return q
 }
 
 // WhereNotInSubQuery adds WHERE NOT IN (subquery) condition.
 func (q *Query) WhereNotInSubQuery(col string, sub *Query) *Query {
+	q = q.derive()
 	q.builder.WhereNotInSubQuery(col, sub.builder)
 	return q
 }
 
 // OrWhereInSubQuery adds OR WHERE IN (subquery) condition.
 func (q *Query) OrWhereInSubQuery(col string, sub *Query) *Query {
+	q = q.derive()
 	q.builder.OrWhereInSubQuery(col, sub.builder)
 	return q
 }
 
 // OrWhereNotInSubQuery adds OR WHERE NOT IN (subquery) condition.
 func (q *Query) OrWhereNotInSubQuery(col string, sub *Query) *Query {
+	q = q.derive()
 	q.builder.OrWhereNotInSubQuery(col, sub.builder)
 	return q
 }
 
 // WhereAny adds grouped OR conditions across columns.
 func (q *Query) WhereAny(cols []string, cond string, val any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -937,6 +997,7 @@ func (q *Query) WhereAny(cols []string, cond string, val any) *Query {
 
 // WhereAll adds grouped AND conditions across columns.
 func (q *Query) WhereAll(cols []string, cond string, val any) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -951,6 +1012,7 @@ func (q *Query) WhereAll(cols []string, cond string, val any) *Query {
 
 // WhereColumn adds WHERE column operator column condition.
 func (q *Query) WhereColumn(col string, args ...string) *Query {
+	q = q.derive()
 	var op, other string
 	switch len(args) {
 	case 1:
@@ -976,6 +1038,7 @@ func (q *Query) WhereColumn(col string, args ...string) *Query {
 
 // OrWhereColumn adds OR WHERE column operator column condition.
 func (q *Query) OrWhereColumn(col string, args ...string) *Query {
+	q = q.derive()
 	var op, other string
 	switch len(args) {
 	case 1:
@@ -1001,6 +1064,7 @@ func (q *Query) OrWhereColumn(col string, args ...string) *Query {
 
 // WhereColumns adds multiple column comparison conditions joined by AND.
 func (q *Query) WhereColumns(columns [][]string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1019,6 +1083,7 @@ func (q *Query) WhereColumns(columns [][]string) *Query {
 
 // OrWhereColumns adds multiple column comparison conditions joined by OR.
 func (q *Query) OrWhereColumns(columns [][]string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1037,54 +1102,63 @@ func (q *Query) OrWhereColumns(columns [][]string) *Query {
 
 // WhereNull adds WHERE column IS NULL condition.
 func (q *Query) WhereNull(col string) *Query {
+	q = q.derive()
 	q.builder.WhereNull(col)
 	return q
 }
 
 // WhereNotNull adds WHERE column IS NOT NULL condition.
 func (q *Query) WhereNotNull(col string) *Query {
+	q = q.derive()
 	q.builder.WhereNotNull(col)
 	return q
 }
 
 // OrWhereNull adds OR WHERE column IS NULL condition.
 func (q *Query) OrWhereNull(col string) *Query {
+	q = q.derive()
 	q.builder.OrWhereNull(col)
 	return q
 }
 
 // OrWhereNotNull adds OR WHERE column IS NOT NULL condition.
 func (q *Query) OrWhereNotNull(col string) *Query {
+	q = q.derive()
 	q.builder.OrWhereNotNull(col)
 	return q
 }
 
 // WhereBetween adds WHERE BETWEEN condition.
 func (q *Query) WhereBetween(col string, min, max any) *Query {
+	q = q.derive()
 	q.builder.WhereBetween(col, min, max)
 	return q
 }
 
 // WhereNotBetween adds WHERE NOT BETWEEN condition.
 func (q *Query) WhereNotBetween(col string, min, max any) *Query {
+	q = q.derive()
 	q.builder.WhereNotBetween(col, min, max)
 	return q
 }
 
 // OrWhereBetween adds OR WHERE BETWEEN condition.
 func (q *Query) OrWhereBetween(col string, min, max any) *Query {
+	q = q.derive()
 	q.builder.OrWhereBetween(col, min, max)
 	return q
 }
 
 // OrWhereNotBetween adds OR WHERE NOT BETWEEN condition.
 func (q *Query) OrWhereNotBetween(col string, min, max any) *Query {
+	q = q.derive()
 	q.builder.OrWhereNotBetween(col, min, max)
 	return q
 }
 
 // WhereBetweenColumns adds WHERE col BETWEEN minCol AND maxCol using columns.
 func (q *Query) WhereBetweenColumns(col, minCol, maxCol string) *Query {
+	q = q.derive()
 	cols := []string{col, minCol, maxCol}
 	q.builder.WhereBetweenColumns(cols, col, minCol, maxCol)
 	return q
@@ -1092,6 +1166,7 @@ func (q *Query) WhereBetweenColumns(col, minCol, maxCol string) *Query {
 
 // OrWhereBetweenColumns adds OR WHERE col BETWEEN minCol AND maxCol using columns.
 func (q *Query) OrWhereBetweenColumns(col, minCol, maxCol string) *Query {
+	q = q.derive()
 	cols := []string{col, minCol, maxCol}
 	q.builder.OrWhereBetweenColumns(cols, col, minCol, maxCol)
 	return q
@@ -1099,6 +1174,7 @@ func (q *Query) OrWhereBetweenColumns(col, minCol, maxCol string) *Query {
 
 // WhereNotBetweenColumns adds WHERE col NOT BETWEEN minCol AND maxCol using columns.
 func (q *Query) WhereNotBetweenColumns(col, minCol, maxCol string) *Query {
+	q = q.derive()
 	cols := []string{col, minCol, maxCol}
 	q.builder.WhereNotBetweenColumns(cols, col, minCol, maxCol)
 	return q
@@ -1106,6 +1182,7 @@ func (q *Query) WhereNotBetweenColumns(col, minCol, maxCol string) *Query {
 
 // OrWhereNotBetweenColumns adds OR WHERE col NOT BETWEEN minCol AND maxCol using columns.
 func (q *Query) OrWhereNotBetweenColumns(col, minCol, maxCol string) *Query {
+	q = q.derive()
 	cols := []string{col, minCol, maxCol}
 	q.builder.OrWhereNotBetweenColumns(cols, col, minCol, maxCol)
 	return q
@@ -1113,42 +1190,49 @@ func (q *Query) OrWhereNotBetweenColumns(col, minCol, maxCol string) *Query {
 
 // WhereFullText adds full-text search condition.
 func (q *Query) WhereFullText(cols []string, search string, opts map[string]any) *Query {
+	q = q.derive()
 	q.builder.WhereFullText(cols, search, opts)
 	return q
 }
 
 // OrWhereFullText adds OR full-text search condition.
 func (q *Query) OrWhereFullText(cols []string, search string, opts map[string]any) *Query {
+	q = q.derive()
 	q.builder.OrWhereFullText(cols, search, opts)
 	return q
 }
 
 // WhereExists adds WHERE EXISTS (subquery) condition.
 func (q *Query) WhereExists(sub *Query) *Query {
+	q = q.derive()
 	q.builder.WhereExistsSubQuery(sub.builder)
 	return q
 }
 
 // OrWhereExists adds OR WHERE EXISTS (subquery) condition.
 func (q *Query) OrWhereExists(sub *Query) *Query {
+	q = q.derive()
 	q.builder.OrWhereExistsSubQuery(sub.builder)
 	return q
 }
 
 // WhereNotExists adds WHERE NOT EXISTS (subquery) condition.
 func (q *Query) WhereNotExists(sub *Query) *Query {
+	q = q.derive()
 	q.builder.WhereNotExistsQuery(sub.builder)
 	return q
 }
 
 // OrWhereNotExists adds OR WHERE NOT EXISTS (subquery) condition.
 func (q *Query) OrWhereNotExists(sub *Query) *Query {
+	q = q.derive()
 	q.builder.OrWhereNotExistsQuery(sub.builder)
 	return q
 }
 
 // WhereDate adds WHERE DATE(column) comparison condition.
 func (q *Query) WhereDate(col, cond, date string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1163,6 +1247,7 @@ func (q *Query) WhereDate(col, cond, date string) *Query {
 
 // OrWhereDate adds OR WHERE DATE(column) comparison condition.
 func (q *Query) OrWhereDate(col, cond, date string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1177,6 +1262,7 @@ func (q *Query) OrWhereDate(col, cond, date string) *Query {
 
 // WhereTime adds WHERE TIME(column) comparison condition.
 func (q *Query) WhereTime(col, cond, time string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1191,6 +1277,7 @@ func (q *Query) WhereTime(col, cond, time string) *Query {
 
 // OrWhereTime adds OR WHERE TIME(column) comparison condition.
 func (q *Query) OrWhereTime(col, cond, time string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1205,6 +1292,7 @@ func (q *Query) OrWhereTime(col, cond, time string) *Query {
 
 // WhereDay adds WHERE DAY(column) comparison condition.
 func (q *Query) WhereDay(col, cond, day string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1219,6 +1307,7 @@ func (q *Query) WhereDay(col, cond, day string) *Query {
 
 // OrWhereDay adds OR WHERE DAY(column) comparison condition.
 func (q *Query) OrWhereDay(col, cond, day string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1233,6 +1322,7 @@ func (q *Query) OrWhereDay(col, cond, day string) *Query {
 
 // WhereMonth adds WHERE MONTH(column) comparison condition.
 func (q *Query) WhereMonth(col, cond, month string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1247,6 +1337,7 @@ func (q *Query) WhereMonth(col, cond, month string) *Query {
 
 // OrWhereMonth adds OR WHERE MONTH(column) comparison condition.
 func (q *Query) OrWhereMonth(col, cond, month string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1261,6 +1352,7 @@ func (q *Query) OrWhereMonth(col, cond, month string) *Query {
 
 // WhereYear adds WHERE YEAR(column) comparison condition.
 func (q *Query) WhereYear(col, cond, year string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1275,6 +1367,7 @@ func (q *Query) WhereYear(col, cond, year string) *Query {
 
 // OrWhereYear adds OR WHERE YEAR(column) comparison condition.
 func (q *Query) OrWhereYear(col, cond, year string) *Query {
+	q = q.derive()
 	if q.err != nil {
 		return q
 	}
@@ -1293,24 +1386,20 @@ func (q *Query) Take(n int) *Query { return q.Limit(n) }
 // Skip is an alias of Offset.
 func (q *Query) Skip(n int) *Query { return q.Offset(n) }
 
-// SharedLock adds LOCK IN SHARE MODE clause.
-func (q *Query) SharedLock() *Query {
-	q.builder.SharedLock()
-	return q
-}
-
-// LockForUpdate adds FOR UPDATE clause.
-func (q *Query) LockForUpdate() *Query {
-	q.builder.LockForUpdate()
-	return q
-}
-
 // Build returns the SQL and args.
 func (q *Query) Build() (string, []any, error) {
 	if q.err != nil {
 		return "", nil, q.err
 	}
-	return q.builder.Build()
+	sqlStr, args, err := q.builder.Build()
+	if err != nil {
+		return "", nil, err
+	}
+	sqlStr, err = q.applyLockOptions(sqlStr)
+	if err != nil {
+		return "", nil, err
+	}
+	return sqlStr, args, nil
 }
 
 // Dump returns SQL and args for debugging.
@@ -1329,11 +1418,16 @@ func (q *Query) RawSQL() (string, error) {
 	return q.builder.RawSql()
 }
 
-func dataToMap(data any) (map[string]any, error) {
+func (q *Query) dataToMap(data any) (map[string]any, error) {
 	if m, ok := data.(map[string]any); ok {
 		return m, nil
 	}
-	return conv.StructToMap(data)
+	m, err := conv.StructToMap(data)
+	if err != nil {
+		return nil, err
+	}
+	codec.WrapTagged(m, data, q.dialect)
+	return m, nil
 }
 
 // Insert executes an INSERT with the given data.
@@ -1342,19 +1436,15 @@ func (q *Query) Insert(data any) (sql.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return nil, err
-	}
-	return q.execStmt(plan.SQL, plan.Params...)
+	return q.execPlan(plan)
 }
 
 // PlanInsert builds an INSERT plan for data without executing it.
 func (q *Query) PlanInsert(ctx context.Context, data any) (*QueryPlan, error) {
-	_ = ctx
 	if q.err != nil {
 		return nil, q.err
 	}
-	m, err := dataToMap(data)
+	m, err := q.dataToMap(data)
 	if err != nil {
 		return nil, err
 	}
@@ -1367,7 +1457,7 @@ func (q *Query) PlanInsert(ctx context.Context, data any) (*QueryPlan, error) {
 	plan := newQueryPlan(OperationInsert, sqlStr, args)
 	plan.Tables = append(plan.Tables, TableRef{Name: q.builder.GetQuery().Table.Name})
 	plan.Columns = columnRefsFromNames(sortedMapKeys(m))
-	q.finalizePlan(plan)
+	q.finalizePlan(ctx, plan)
 	return plan, nil
 }
 
@@ -1375,7 +1465,7 @@ func (q *Query) PlanInsert(ctx context.Context, data any) (*QueryPlan, error) {
 // For PostgreSQL, it appends a RETURNING clause for the configured
 // primary key column because the driver does not support LastInsertId.
 func (q *Query) InsertGetId(data any) (int64, error) {
-	m, err := dataToMap(data)
+	m, err := q.dataToMap(data)
 	if err != nil {
 		return 0, err
 	}
@@ -1385,8 +1475,9 @@ func (q *Query) InsertGetId(data any) (int64, error) {
 			return 0, err
 		}
 		plan.SQL += " RETURNING " + q.dialect.QuoteIdent(q.getPrimaryKeyColumn())
+		plan.Fingerprint = PlanFingerprint(plan)
 		var id int64
-		if err := q.queryRow(plan.SQL, plan.Params...).Scan(&id); err != nil {
+		if err := q.queryPlan(plan, scanSingleRow(&id)); err != nil {
 			return 0, err
 		}
 		return id, nil
@@ -1409,15 +1500,11 @@ func (q *Query) InsertBatch(data []map[string]any) (sql.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return nil, err
-	}
-	return q.execStmt(plan.SQL, plan.Params...)
+	return q.execPlan(plan)
 }
 
 // PlanInsertBatch builds a batch INSERT plan without executing it.
 func (q *Query) PlanInsertBatch(ctx context.Context, data []map[string]any) (*QueryPlan, error) {
-	_ = ctx
 	if q.err != nil {
 		return nil, q.err
 	}
@@ -1431,7 +1518,7 @@ func (q *Query) PlanInsertBatch(ctx context.Context, data []map[string]any) (*Qu
 	plan.Tables = append(plan.Tables, TableRef{Name: q.builder.GetQuery().Table.Name})
 	plan.Columns = columnRefsFromNames(sortedBatchMapKeys(data))
 	plan.Metadata = map[string]any{"batch_size": len(data)}
-	q.finalizePlan(plan)
+	q.finalizePlan(ctx, plan)
 	return plan, nil
 }
 
@@ -1441,14 +1528,10 @@ func (q *Query) InsertOrIgnore(data []map[string]any) (sql.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return nil, err
-	}
-	return q.execStmt(plan.SQL, plan.Params...)
+	return q.execPlan(plan)
 }
 
 func (q *Query) planInsertOrIgnore(ctx context.Context, data []map[string]any) (*QueryPlan, error) {
-	_ = ctx
 	if q.err != nil {
 		return nil, q.err
 	}
@@ -1462,7 +1545,7 @@ func (q *Query) planInsertOrIgnore(ctx context.Context, data []map[string]any) (
 	plan.Tables = append(plan.Tables, TableRef{Name: q.builder.GetQuery().Table.Name})
 	plan.Columns = columnRefsFromNames(sortedBatchMapKeys(data))
 	plan.Metadata = map[string]any{"insert_mode": "ignore", "batch_size": len(data)}
-	q.finalizePlan(plan)
+	q.finalizePlan(ctx, plan)
 	return plan, nil
 }
 
@@ -1472,14 +1555,10 @@ func (q *Query) Upsert(data []map[string]any, unique []string, updateCols []stri
 	if err != nil {
 		return nil, err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return nil, err
-	}
-	return q.execStmt(plan.SQL, plan.Params...)
+	return q.execPlan(plan)
 }
 
 func (q *Query) planUpsert(ctx context.Context, data []map[string]any, unique []string, updateCols []string) (*QueryPlan, error) {
-	_ = ctx
 	if q.err != nil {
 		return nil, q.err
 	}
@@ -1493,7 +1572,7 @@ func (q *Query) planUpsert(ctx context.Context, data []map[string]any, unique []
 	plan.Tables = append(plan.Tables, TableRef{Name: q.builder.GetQuery().Table.Name})
 	plan.Columns = columnRefsFromNames(sortedBatchMapKeys(data))
 	plan.Metadata = map[string]any{"insert_mode": "upsert", "unique_columns": unique, "update_columns": updateCols}
-	q.finalizePlan(plan)
+	q.finalizePlan(ctx, plan)
 	return plan, nil
 }
 
@@ -1503,14 +1582,10 @@ func (q *Query) UpdateOrInsert(cond map[string]any, values map[string]any) (sql.
 	if err != nil {
 		return nil, err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return nil, err
-	}
-	return q.execStmt(plan.SQL, plan.Params...)
+	return q.execPlan(plan)
 }
 
 func (q *Query) planUpdateOrInsert(ctx context.Context, cond map[string]any, values map[string]any) (*QueryPlan, error) {
-	_ = ctx
 	if q.err != nil {
 		return nil, q.err
 	}
@@ -1531,7 +1606,7 @@ func (q *Query) planUpdateOrInsert(ctx context.Context, cond map[string]any, val
 	}
 	plan.Columns = columnRefsFromNames(sortedMapKeys(merged))
 	plan.Metadata = map[string]any{"insert_mode": "update_or_insert", "condition_columns": sortedMapKeys(cond), "update_columns": sortedMapKeys(values)}
-	q.finalizePlan(plan)
+	q.finalizePlan(ctx, plan)
 	return plan, nil
 }
 
@@ -1541,14 +1616,10 @@ func (q *Query) InsertUsing(columns []string, sub *Query) (sql.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return nil, err
-	}
-	return q.execStmt(plan.SQL, plan.Params...)
+	return q.execPlan(plan)
 }
 
 func (q *Query) planInsertUsing(ctx context.Context, columns []string, sub *Query) (*QueryPlan, error) {
-	_ = ctx
 	if q.err != nil {
 		return nil, q.err
 	}
@@ -1562,7 +1633,7 @@ func (q *Query) planInsertUsing(ctx context.Context, columns []string, sub *Quer
 	plan.Tables = append(plan.Tables, TableRef{Name: q.builder.GetQuery().Table.Name})
 	plan.Columns = columnRefsFromNames(columns)
 	plan.Metadata = map[string]any{"insert_mode": "insert_using"}
-	q.finalizePlan(plan)
+	q.finalizePlan(ctx, plan)
 	return plan, nil
 }
 
@@ -1572,20 +1643,17 @@ func (q *Query) Update(data any) (sql.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return nil, err
-	}
-	return q.execStmt(plan.SQL, plan.Params...)
+	return q.execPlan(plan)
 }
 
 // PlanUpdate builds an UPDATE plan for data without executing it.
 func (q *Query) PlanUpdate(ctx context.Context, data any) (*QueryPlan, error) {
-	_ = ctx
 	if q.err != nil {
 		return nil, q.err
 	}
+	q = q.derive()
 	q.applyPolicyPredicates()
-	m, err := dataToMap(data)
+	m, err := q.dataToMap(data)
 	if err != nil {
 		return nil, err
 	}
@@ -1596,11 +1664,15 @@ func (q *Query) PlanUpdate(ctx context.Context, data any) (*QueryPlan, error) {
 	if err != nil {
 		return nil, err
 	}
+	sqlStr, args, err = expandExprArgs(q.dialect, sqlStr, args)
+	if err != nil {
+		return nil, err
+	}
 	plan := newQueryPlan(OperationUpdate, sqlStr, args)
 	appendTableRef(plan, q.builder.GetQuery().Table.Name, "")
-	plan.Columns = columnRefsFromNames(sortedMapKeys(m))
+	plan.Columns = exprColumnRefs(m)
 	appendSelectBuilderWriteMetadata(plan, q.builder)
-	q.finalizePlan(plan)
+	q.finalizePlan(ctx, plan)
 	return plan, nil
 }
 
@@ -1610,18 +1682,15 @@ func (q *Query) Delete() (sql.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := ensurePlanExecutable(plan); err != nil {
-		return nil, err
-	}
-	return q.execStmt(plan.SQL, plan.Params...)
+	return q.execPlan(plan)
 }
 
 // PlanDelete builds a DELETE plan without executing it.
 func (q *Query) PlanDelete(ctx context.Context) (*QueryPlan, error) {
-	_ = ctx
 	if q.err != nil {
 		return nil, q.err
 	}
+	q = q.derive()
 	q.applyPolicyPredicates()
 	delBuilder := newDeleteBuilder(q.dialect)
 	delBuilder.Table(q.builder.GetQuery().Table.Name).Delete()
@@ -1633,7 +1702,7 @@ func (q *Query) PlanDelete(ctx context.Context) (*QueryPlan, error) {
 	plan := newQueryPlan(OperationDelete, sqlStr, args)
 	appendTableRef(plan, q.builder.GetQuery().Table.Name, "")
 	appendSelectBuilderWriteMetadata(plan, q.builder)
-	q.finalizePlan(plan)
+	q.finalizePlan(ctx, plan)
 	return plan, nil
 }
 
@@ -1677,48 +1746,6 @@ func copyBuilderStateDelete(src *qbapi.SelectQueryBuilder, dst *qbapi.DeleteQuer
 	_ = setFieldValue(reflect.ValueOf(dstOb), "Order", reflect.ValueOf(srcOb).Elem().FieldByName("Order"))
 }
 
-// copySelectBuilderState duplicates where, join, group and lock clauses from src
-// to dst. This relies on reflection because goquent-query-builder does not
-// expose a safe cloning API. TODO: replace this with qbapi.Clone when available.
-func copySelectBuilderState(src *qbapi.SelectQueryBuilder, dst *qbapi.SelectQueryBuilder) error {
-	srcWb := src.GetWhereBuilder()
-	dstWb := dst.GetWhereBuilder()
-	clonedWhere := reflect.New(reflect.ValueOf(srcWb.GetQuery()).Elem().Type())
-	clonedWhere.Elem().Set(reflect.ValueOf(srcWb.GetQuery()).Elem())
-	if err := setFieldValue(reflect.ValueOf(dstWb), "query", clonedWhere); err != nil {
-		return err
-	}
-
-	srcJb := src.GetJoinBuilder()
-	dstJb := dst.GetJoinBuilder()
-	newJoins := deepCopyJoins(srcJb)
-	if err := setFieldValue(reflect.ValueOf(dstJb), "Joins", newJoins); err != nil {
-		return err
-	}
-
-	srcOb := src.GetOrderByBuilder()
-	dstOb := dst.GetOrderByBuilder()
-	if err := setFieldValue(reflect.ValueOf(dstOb), "Order", reflect.ValueOf(srcOb).Elem().FieldByName("Order")); err != nil {
-		return err
-	}
-
-	srcSB := reflect.ValueOf(src).Elem().FieldByName("builder").Elem()
-	dstSB := reflect.ValueOf(dst).Elem().FieldByName("builder").Elem()
-	srcSel := srcSB.FieldByName("selectQuery").Elem()
-	dstSel := dstSB.FieldByName("selectQuery").Elem()
-
-	dstSelAddr := reflect.NewAt(dstSel.Type(), unsafe.Pointer(dstSel.UnsafeAddr())).Elem()
-
-	if err := setFieldValue(dstSelAddr.Addr(), "Group", srcSel.FieldByName("Group")); err != nil {
-		return err
-	}
-	if err := setFieldValue(dstSelAddr.Addr(), "Lock", srcSel.FieldByName("Lock")); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // deepCopyJoins clones the Joins value from a JoinBuilder using reflection.
 // Each field of Joins is a pointer to a slice, so we copy the underlying
 // slices to ensure the destination builder can modify them independently.
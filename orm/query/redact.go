@@ -0,0 +1,103 @@
+package query
+
+// RedactedParam replaces parameter values hidden by RedactParams.
+const RedactedParam = "[REDACTED]"
+
+// RedactParams returns a copy of plan.Params safe to log. Values that may be
+// bound to a PII column of a registered TablePolicy are replaced with
+// RedactedParam. When the predicates of a SELECT or DELETE plan account for
+// every parameter, only the parameters of PII predicates are redacted;
+// otherwise all parameters are redacted once the plan references a PII column
+// or writes a PII table without column metadata. Raw and migration plans are
+// fully redacted unless includeRaw is true.
+func RedactParams(plan *QueryPlan, includeRaw bool) []any {
+	if plan == nil || len(plan.Params) == 0 {
+		return nil
+	}
+	out := append([]any(nil), plan.Params...)
+	if plan.Operation == OperationRaw || plan.Operation == OperationMigration {
+		if !includeRaw {
+			redactAll(out)
+		}
+		return out
+	}
+	pii := planPIIColumns(plan)
+	if len(pii) == 0 {
+		return out
+	}
+	if cols, ok := predicateParamColumns(plan); ok {
+		for i, col := range cols {
+			if _, ok := pii[normalizeColumnName(col)]; ok {
+				out[i] = RedactedParam
+			}
+		}
+		return out
+	}
+	if referencesPIIColumn(plan, pii) {
+		redactAll(out)
+	}
+	return out
+}
+
+func redactAll(params []any) {
+	for i := range params {
+		params[i] = RedactedParam
+	}
+}
+
+// planPIIColumns returns the normalized PII columns of the plan's tables.
+func planPIIColumns(plan *QueryPlan) map[string]struct{} {
+	var pii map[string]struct{}
+	for _, table := range plan.Tables {
+		policy, ok := PolicyForTable(table.Name)
+		if !ok {
+			continue
+		}
+		for _, col := range policy.PIIColumns {
+			if pii == nil {
+				pii = make(map[string]struct{})
+			}
+			pii[normalizeColumnName(col)] = struct{}{}
+		}
+	}
+	return pii
+}
+
+// predicateParamColumns maps each parameter of a SELECT or DELETE plan to
+// the column of the predicate that binds it. It fails unless the predicates
+// account for exactly the plan's parameters.
+func predicateParamColumns(plan *QueryPlan) ([]string, bool) {
+	if plan.Operation != OperationSelect && plan.Operation != OperationDelete {
+		return nil, false
+	}
+	cols := make([]string, 0, len(plan.Params))
+	for _, p := range plan.Predicates {
+		if p.Subquery || (p.Column == "" && p.ValueCount > 0) || (p.Raw != "" && p.Column == "") {
+			return nil, false
+		}
+		for i := 0; i < p.ValueCount; i++ {
+			cols = append(cols, p.Column)
+		}
+	}
+	return cols, len(cols) == len(plan.Params)
+}
+
+func referencesPIIColumn(plan *QueryPlan, pii map[string]struct{}) bool {
+	if (plan.Operation == OperationInsert || plan.Operation == OperationUpdate) && len(plan.Columns) == 0 {
+		return true
+	}
+	for _, col := range plan.Columns {
+		if _, ok := pii[normalizeColumnName(col.Name)]; ok {
+			return true
+		}
+	}
+	for _, p := range plan.Predicates {
+		if p.Raw != "" && p.Column == "" {
+			return true
+		}
+		if _, ok := pii[normalizeColumnName(p.Column)]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"crypto/ed25519"
 	"errors"
 	"fmt"
 	"strings"
@@ -27,7 +28,7 @@ type RiskResult struct {
 	Blocked          bool      `json:"blocked"`
 }
 
-// RiskRuleConfig customizes a built-in warning rule.
+// RiskRuleConfig customizes a built-in or custom warning rule.
 type RiskRuleConfig struct {
 	Enabled        *bool      `json:"enabled,omitempty"`
 	Severity       *RiskLevel `json:"severity,omitempty"`
@@ -39,18 +40,31 @@ type RiskRuleConfig struct {
 type RiskConfig struct {
 	Environment string                    `json:"environment,omitempty"`
 	Rules       map[string]RiskRuleConfig `json:"rules,omitempty"`
+	// Indexes lists known indexes by table name. When a plan's table is
+	// present, predicates and ORDER BY are checked against its indexes and
+	// equality on every column of a unique index counts as primary-key-like.
+	Indexes map[string][]IndexInfo `json:"indexes,omitempty"`
+	// DeepOffset is the OFFSET above which DEEP_OFFSET_PAGINATION is raised;
+	// zero means DefaultDeepOffset.
+	DeepOffset int64 `json:"deep_offset,omitempty"`
+	// LargeInList is the IN list length above which LARGE_IN_LIST is raised;
+	// zero means DefaultLargeInList.
+	LargeInList int `json:"large_in_list,omitempty"`
 }
 
 // DefaultRiskEngine is the built-in deterministic risk engine.
 var DefaultRiskEngine RiskEngine = defaultRiskEngine{}
 
 // NewRiskEngine creates a deterministic risk engine using config overrides.
-func NewRiskEngine(config RiskConfig) RiskEngine {
-	return defaultRiskEngine{config: config}
+// The engine evaluates the built-in rules, the rules registered with
+// RegisterRiskRule, and rules.
+func NewRiskEngine(config RiskConfig, rules ...RiskRule) RiskEngine {
+	return defaultRiskEngine{config: config, rules: append([]RiskRule(nil), rules...)}
 }
 
 type defaultRiskEngine struct {
 	config RiskConfig
+	rules  []RiskRule
 }
 
 func (d defaultRiskEngine) CheckQuery(plan *QueryPlan) RiskResult {
@@ -81,6 +95,14 @@ func (d defaultRiskEngine) CheckQuery(plan *QueryPlan) RiskResult {
 				false,
 			))
 		}
+		if plan.Lock != nil && plan.Lock.OutsideTransaction {
+			add(newWarning(WarningLockOutsideTransaction, RiskMedium,
+				"locking SELECT runs outside a transaction; row locks are released when the statement ends",
+				"run the locking query inside db.Transaction(...) or a *sql.Tx",
+				true,
+				false,
+			))
+		}
 	case OperationUpdate:
 		if hasNoPredicate(plan) {
 			add(newWarning(WarningUpdateWithoutWhere, RiskBlocked,
@@ -89,7 +111,7 @@ func (d defaultRiskEngine) CheckQuery(plan *QueryPlan) RiskResult {
 				false,
 				false,
 			))
-		} else if !hasPrimaryKeyLikePredicate(plan) {
+		} else if !d.hasPrimaryKeyLikePredicate(plan) {
 			add(newWarning(WarningBulkUpdateDetected, RiskMedium,
 				"UPDATE predicate is not primary-key-like and may affect multiple rows",
 				"confirm the intended row set or add a narrower predicate",
@@ -105,7 +127,7 @@ func (d defaultRiskEngine) CheckQuery(plan *QueryPlan) RiskResult {
 				false,
 				false,
 			))
-		} else if !hasPrimaryKeyLikePredicate(plan) {
+		} else if !d.hasPrimaryKeyLikePredicate(plan) {
 			add(newWarning(WarningBulkDeleteDetected, RiskMedium,
 				"DELETE predicate is not primary-key-like and may affect multiple rows",
 				"confirm the intended row set or add a narrower predicate",
@@ -138,6 +160,9 @@ func (d defaultRiskEngine) CheckQuery(plan *QueryPlan) RiskResult {
 			false,
 		))
 	}
+	warnings = append(warnings, d.structuralWarnings(plan)...)
+	warnings = append(warnings, d.indexWarnings(plan)...)
+	warnings = append(warnings, customRuleWarnings(plan, d.rules)...)
 
 	warnings = applyRiskConfig(warnings, d.config)
 	level, blocked := aggregateWarnings(warnings)
@@ -178,12 +203,23 @@ func applyRiskConfig(warnings []Warning, config RiskConfig) []Warning {
 }
 
 func finalizePlan(plan *QueryPlan, approval *Approval, suppressions []Suppression) {
+	finalizePlanWith(plan, nil, approval, suppressions, nil)
+}
+
+// finalizePlanWith evaluates plan with engine (DefaultRiskEngine when nil)
+// and policy, then applies suppressions and approval.
+func finalizePlanWith(plan *QueryPlan, engine RiskEngine, approval *Approval, suppressions []Suppression, policy *TablePolicy) {
 	if plan == nil {
 		return
 	}
-	result := DefaultRiskEngine.CheckQuery(plan)
+	if engine == nil {
+		engine = DefaultRiskEngine
+	}
+	plan.Fingerprint = PlanFingerprint(plan)
+	result := engine.CheckQuery(plan)
 	allWarnings := append([]Warning(nil), result.Warnings...)
-	allWarnings = append(allWarnings, checkPolicy(plan, nil)...)
+	allWarnings = append(allWarnings, checkPolicy(plan, policy)...)
+	allWarnings = append(allWarnings, accessWarnings(plan, policy)...)
 	warnings, suppressed, suppressionWarnings := applySuppressions(allWarnings, suppressions, time.Now().UTC())
 	warnings = append(warnings, suppressionWarnings...)
 
@@ -203,38 +239,27 @@ func finalizePlan(plan *QueryPlan, approval *Approval, suppressions []Suppressio
 	}
 }
 
-func finalizePlanWithPolicy(plan *QueryPlan, approval *Approval, suppressions []Suppression, policy *TablePolicy) {
+// EvaluatePlan re-evaluates a raw, named or catalog plan with engine
+// (DefaultRiskEngine when nil) and suppressions, replacing its warnings and
+// risk level. The plan keeps its approval. Builder queries use
+// Query.WithRiskEngine and Query.WithSuppressions instead.
+func EvaluatePlan(plan *QueryPlan, engine RiskEngine, suppressions ...Suppression) {
 	if plan == nil {
 		return
 	}
-	result := DefaultRiskEngine.CheckQuery(plan)
-	allWarnings := append([]Warning(nil), result.Warnings...)
-	allWarnings = append(allWarnings, checkPolicy(plan, policy)...)
-	warnings, suppressed, suppressionWarnings := applySuppressions(allWarnings, suppressions, time.Now().UTC())
-	warnings = append(warnings, suppressionWarnings...)
-
-	level, blocked := aggregateWarnings(warnings)
-	if len(warnings) == 0 && len(suppressed) > 0 {
-		level = RiskLow
-	}
-
-	plan.Warnings = warnings
-	plan.SuppressedWarnings = suppressed
-	plan.RiskLevel = level
-	plan.Blocked = blocked || level == RiskBlocked
-	plan.RequiredApproval = requiresApprovalLevel(level)
-	if approval != nil {
-		copied := *approval
-		plan.Approval = &copied
-	}
+	finalizePlanWith(plan, engine, plan.Approval, suppressions, nil)
 }
 
 // EnsurePlanExecutable enforces approval and block rules for a finalized plan.
-func EnsurePlanExecutable(plan *QueryPlan) error {
-	return ensurePlanExecutable(plan)
+// Any inline approval is accepted unless trusted keys are given, in which case
+// the approval must be signed by one of them for the plan fingerprint (see
+// SignApproval). Use ApprovalRegistry.EnsurePlanExecutable to require
+// approvals from an approvals file.
+func EnsurePlanExecutable(plan *QueryPlan, trusted ...ed25519.PublicKey) error {
+	return ensurePlanExecutable(plan, trusted)
 }
 
-func ensurePlanExecutable(plan *QueryPlan) error {
+func ensurePlanExecutable(plan *QueryPlan, trusted []ed25519.PublicKey) error {
 	if plan == nil {
 		return nil
 	}
@@ -250,7 +275,7 @@ func ensurePlanExecutable(plan *QueryPlan) error {
 	if plan.Approval.ExpiresAt != nil && !plan.Approval.ExpiresAt.After(time.Now().UTC()) {
 		return fmt.Errorf("%w: approval expired", ErrApprovalRequired)
 	}
-	return nil
+	return ensureSignedApproval(plan.Approval, plan.Fingerprint, trusted)
 }
 
 func newWarning(code string, level RiskLevel, message, hint string, suppressible, requiresReason bool) Warning {
@@ -346,6 +371,10 @@ func hasNoPredicate(plan *QueryPlan) bool {
 	return len(plan.Predicates) == 0 && !strings.Contains(strings.ToUpper(plan.SQL), " WHERE ")
 }
 
+func (d defaultRiskEngine) hasPrimaryKeyLikePredicate(plan *QueryPlan) bool {
+	return hasPrimaryKeyLikePredicate(plan) || d.hasUniqueIndexPredicate(plan)
+}
+
 func hasPrimaryKeyLikePredicate(plan *QueryPlan) bool {
 	for _, predicate := range plan.Predicates {
 		col := strings.ToLower(strings.TrimSpace(predicate.Column))
@@ -0,0 +1,76 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RiskRule is a custom warning rule evaluated after the built-in rules.
+// Check returns the warnings it finds for plan; a warning without a Code or
+// Level gets the rule's Code and RiskMedium. RiskConfig.Rules can disable or
+// re-level custom rules by Code like built-in ones.
+type RiskRule struct {
+	Code        string
+	Description string
+	Check       func(plan *QueryPlan) []Warning
+}
+
+var riskRuleRegistry = struct {
+	sync.RWMutex
+	byCode map[string]RiskRule
+}{byCode: make(map[string]RiskRule)}
+
+// RegisterRiskRule registers or replaces a custom rule used by
+// DefaultRiskEngine, engines created by NewRiskEngine, and the plans built by
+// queries and static review.
+func RegisterRiskRule(rule RiskRule) error {
+	rule.Code = strings.TrimSpace(rule.Code)
+	if rule.Code == "" {
+		return fmt.Errorf("goquent: risk rule code is required")
+	}
+	if rule.Check == nil {
+		return fmt.Errorf("goquent: risk rule %s has no Check function", rule.Code)
+	}
+	riskRuleRegistry.Lock()
+	defer riskRuleRegistry.Unlock()
+	riskRuleRegistry.byCode[rule.Code] = rule
+	return nil
+}
+
+// RegisteredRiskRules returns all registered custom rules ordered by code.
+func RegisteredRiskRules() []RiskRule {
+	riskRuleRegistry.RLock()
+	defer riskRuleRegistry.RUnlock()
+	rules := make([]RiskRule, 0, len(riskRuleRegistry.byCode))
+	for _, rule := range riskRuleRegistry.byCode {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Code < rules[j].Code
+	})
+	return rules
+}
+
+// customRuleWarnings runs the registered rules followed by extra.
+func customRuleWarnings(plan *QueryPlan, extra []RiskRule) []Warning {
+	var warnings []Warning
+	for _, rules := range [][]RiskRule{RegisteredRiskRules(), extra} {
+		for _, rule := range rules {
+			if rule.Check == nil {
+				continue
+			}
+			for _, w := range rule.Check(plan) {
+				if w.Code == "" {
+					w.Code = rule.Code
+				}
+				if w.Level == "" {
+					w.Level = RiskMedium
+				}
+				warnings = append(warnings, w)
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,165 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultDeepOffset is the OFFSET above which DEEP_OFFSET_PAGINATION is
+	// raised when RiskConfig.DeepOffset is zero.
+	DefaultDeepOffset = 10000
+	// DefaultLargeInList is the IN list length above which LARGE_IN_LIST is
+	// raised when RiskConfig.LargeInList is zero.
+	DefaultLargeInList = 1000
+)
+
+var (
+	rawOffsetPattern      = regexp.MustCompile(`(?i)\bOFFSET\s+(\d+)`)
+	rawLeadingLikePattern = regexp.MustCompile(`(?i)\bLIKE\s+'[%_]`)
+	randomOrderPattern    = regexp.MustCompile(`(?i)^\s*(RAND|RANDOM|NEWID|DBMS_RANDOM\.VALUE)\s*(\(\s*\))?\s*$`)
+	rawRandomOrderPattern = regexp.MustCompile(`(?i)\bORDER\s+BY\s+(RAND|RANDOM|NEWID)\s*\(\s*\)`)
+	nonSargableFunctions  = map[string]bool{"DATE": true, "TIME": true, "DAY": true, "MONTH": true, "YEAR": true}
+)
+
+// structuralWarnings reports query shapes that are valid but scale poorly:
+// deep OFFSET pagination, leading-wildcard LIKE, date functions on filtered
+// columns, very long IN lists, random ordering, and DISTINCT over PII.
+func (d defaultRiskEngine) structuralWarnings(plan *QueryPlan) []Warning {
+	var warnings []Warning
+	add := func(w Warning) {
+		warnings = append(warnings, w)
+	}
+
+	deepOffset := d.config.DeepOffset
+	if deepOffset <= 0 {
+		deepOffset = DefaultDeepOffset
+	}
+	if offset, ok := planOffset(plan); ok && offset > deepOffset {
+		w := newWarning(WarningDeepOffsetPagination, RiskMedium,
+			fmt.Sprintf("OFFSET %d makes the database read and discard every skipped row", offset),
+			"use keyset pagination: filter on the last seen sort key instead of skipping rows",
+			true,
+			false,
+		)
+		w.Evidence = []Evidence{{Key: "offset", Value: offset}, {Key: "threshold", Value: deepOffset}}
+		add(w)
+	}
+
+	largeIn := d.config.LargeInList
+	if largeIn <= 0 {
+		largeIn = DefaultLargeInList
+	}
+	var wildcard, nonSargable, longIn []string
+	for _, p := range plan.Predicates {
+		switch {
+		case p.LeadingWildcard:
+			wildcard = append(wildcard, p.Column)
+		case p.Column != "" && p.JSONPath == "" && nonSargableFunctions[strings.ToUpper(p.Function)]:
+			nonSargable = append(nonSargable, strings.ToUpper(p.Function)+"("+p.Column+")")
+		case strings.HasSuffix(strings.ToUpper(p.Operator), "IN") && p.Function != "any" && p.ValueCount > largeIn:
+			// An array bound with = ANY($1) is one parameter however long.
+			longIn = append(longIn, p.Column)
+		}
+	}
+	if len(wildcard) > 0 || (plan.Operation == OperationRaw && rawLeadingLikePattern.MatchString(plan.SQL)) {
+		add(newWarning(WarningLeadingWildcardLike, RiskMedium,
+			"LIKE pattern starts with a wildcard and cannot use an index"+columnsSuffix(wildcard),
+			"anchor the pattern (LIKE 'x%'), or use a full-text index for substring search",
+			true,
+			false,
+		))
+	}
+	if len(nonSargable) > 0 {
+		add(newWarning(WarningNonSargablePredicate, RiskMedium,
+			"predicate wraps a column in a function and cannot use an index: "+strings.Join(nonSargable, ", "),
+			"compare the bare column with a range, e.g. WhereBetween(\"created_at\", start, end)",
+			true,
+			false,
+		))
+	}
+	if len(longIn) > 0 {
+		add(newWarning(WarningLargeInList, RiskMedium,
+			fmt.Sprintf("IN list has more than %d values%s", largeIn, columnsSuffix(longIn)),
+			"batch the values, join a temporary table, or use WithPostgresArrayIn",
+			true,
+			false,
+		))
+	}
+	if hasRandomOrder(plan) {
+		add(newWarning(WarningOrderByRandom, RiskMedium,
+			"ORDER BY RAND() sorts every matching row to pick a random sample",
+			"pick random keys in the application or sample by primary key range",
+			true,
+			false,
+		))
+	}
+	if plan.Operation == OperationSelect {
+		if columns := distinctPIIColumns(plan); len(columns) > 0 {
+			w := newWarning(WarningDistinctPII, RiskMedium,
+				"SELECT DISTINCT over PII columns enumerates personal data: "+strings.Join(columns, ", "),
+				"select identifiers or aggregates instead of distinct PII values",
+				true,
+				false,
+			)
+			w.RequiresReason = true
+			add(w)
+		}
+	}
+	return warnings
+}
+
+func planOffset(plan *QueryPlan) (int64, bool) {
+	if plan.Offset != nil {
+		return *plan.Offset, true
+	}
+	if plan.Operation != OperationRaw {
+		return 0, false
+	}
+	m := rawOffsetPattern.FindStringSubmatch(plan.SQL)
+	if m == nil {
+		return 0, false
+	}
+	offset, err := strconv.ParseInt(m[1], 10, 64)
+	return offset, err == nil
+}
+
+func hasRandomOrder(plan *QueryPlan) bool {
+	for _, o := range plan.OrderBy {
+		if randomOrderPattern.MatchString(o.Raw) {
+			return true
+		}
+	}
+	return plan.Operation == OperationRaw && rawRandomOrderPattern.MatchString(plan.SQL)
+}
+
+// distinctPIIColumns returns the PII columns of registered table policies
+// that plan selects with DISTINCT.
+func distinctPIIColumns(plan *QueryPlan) []string {
+	var out []string
+	for _, table := range plan.Tables {
+		policy, ok := PolicyForTable(table.Name)
+		if !ok || len(policy.PIIColumns) == 0 {
+			continue
+		}
+		pii := make(map[string]bool, len(policy.PIIColumns))
+		for _, column := range policy.PIIColumns {
+			pii[normalizeColumnName(column)] = true
+		}
+		for _, column := range plan.Columns {
+			if column.Distinct && pii[normalizeColumnName(column.Name)] {
+				out = append(out, policy.Table+"."+normalizeColumnName(column.Name))
+			}
+		}
+	}
+	return out
+}
+
+func columnsSuffix(columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(columns, ", ")
+}
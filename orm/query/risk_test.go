@@ -2,9 +2,12 @@ package query
 
 import (
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"testing"
 	"time"
+
+	ormdriver "github.com/faciam-dev/goquent/orm/driver"
 )
 
 func warningCodeSet(warnings []Warning) map[string]bool {
@@ -98,6 +101,114 @@ func TestApprovalRequiredAndProvided(t *testing.T) {
 	}
 }
 
+func TestApprovalRegistryBindsApprovalToFingerprint(t *testing.T) {
+	weakUpdate := func(exec *recordingExec, registry *ApprovalRegistry) *Query {
+		return newPlanTestQuery(exec).
+			WithApprovalRegistry(registry).
+			WhereRaw("1 = 1", map[string]any{}).
+			RequireApproval("operator reviewed weak predicate")
+	}
+	plan, err := weakUpdate(&recordingExec{}, nil).PlanUpdate(context.Background(), map[string]any{"age": 31})
+	if err != nil {
+		t.Fatalf("PlanUpdate: %v", err)
+	}
+
+	empty, err := NewApprovalRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &recordingExec{}
+	if _, err := weakUpdate(exec, empty).Update(map[string]any{"age": 31}); !errors.Is(err, ErrApprovalRequired) || exec.calls != 0 {
+		t.Fatalf("inline approval accepted without registry record: err=%v calls=%d", err, exec.calls)
+	}
+
+	registry, err := NewApprovalRegistry(ApprovalRecord{Fingerprint: plan.Fingerprint, Reason: "ticket 7", Approver: "dba"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec = &recordingExec{}
+	if _, err := weakUpdate(exec, registry).Update(map[string]any{"age": 32}); errors.Is(err, ErrApprovalRequired) || exec.calls != 1 {
+		t.Fatalf("registry approval not applied: err=%v calls=%d", err, exec.calls)
+	}
+	if err := registry.EnsurePlanExecutable(plan); err != nil || plan.Approval.Fingerprint != plan.Fingerprint || plan.Approval.CreatedBy != "dba" {
+		t.Fatalf("plan approval=%#v err=%v", plan.Approval, err)
+	}
+
+	if _, err := ParseApprovals([]byte(`{"approvals": [{"fingerprint": "sha256:a", "reason": "x"}, {"fingerprint": "sha256:a", "reason": "y"}]}`)); err == nil {
+		t.Fatal("expected duplicate fingerprint error")
+	}
+	if _, err := ParseApprovals([]byte(`{"approvals": [{"fingerprint": "sha256:a"}]}`)); !errors.Is(err, ErrApprovalReasonRequired) {
+		t.Fatalf("expected reason error, got %v", err)
+	}
+}
+
+func TestSignedApprovalVerification(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, otherKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := NewRawPlan("DELETE FROM sessions WHERE expires_at < ?", "2026-01-01")
+
+	plan.Approval = &Approval{Reason: "cleanup"}
+	if err := EnsurePlanExecutable(plan); err != nil {
+		t.Fatalf("inline approval without trusted keys: %v", err)
+	}
+	if err := EnsurePlanExecutable(plan, pub); !errors.Is(err, ErrApprovalRequired) || !errors.Is(err, ErrApprovalUnsigned) {
+		t.Fatalf("expected unsigned approval error, got %v", err)
+	}
+
+	signed := Approval{Reason: "cleanup", CreatedBy: "dba"}
+	if err := SignApproval(&signed, plan.Fingerprint, key); err != nil {
+		t.Fatal(err)
+	}
+	plan.Approval = &signed
+	if err := EnsurePlanExecutable(plan, otherPub, pub); err != nil {
+		t.Fatalf("signed approval: %v", err)
+	}
+	if err := EnsurePlanExecutable(plan, otherPub); !errors.Is(err, ErrApprovalSignatureInvalid) {
+		t.Fatalf("expected untrusted key error, got %v", err)
+	}
+	tampered := signed
+	tampered.CreatedBy = "agent"
+	plan.Approval = &tampered
+	if err := EnsurePlanExecutable(plan, pub); !errors.Is(err, ErrApprovalSignatureInvalid) {
+		t.Fatalf("expected tampered approver error, got %v", err)
+	}
+	other := NewRawPlan("DELETE FROM users")
+	other.Approval = &signed
+	if err := EnsurePlanExecutable(other, pub); !errors.Is(err, ErrApprovalSignatureInvalid) {
+		t.Fatalf("expected fingerprint mismatch error, got %v", err)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	expired := Approval{Reason: "cleanup", CreatedBy: "dba", ExpiresAt: &past}
+	if err := SignApproval(&expired, plan.Fingerprint, otherKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyApproval(&expired, plan.Fingerprint, []ed25519.PublicKey{otherPub}); !errors.Is(err, ErrApprovalExpired) {
+		t.Fatalf("expected expired approval error, got %v", err)
+	}
+
+	registry, err := NewApprovalRegistry(ApprovalRecord{
+		Fingerprint: signed.Fingerprint,
+		Reason:      signed.Reason,
+		Approver:    signed.CreatedBy,
+		KeyID:       signed.KeyID,
+		Signature:   signed.Signature,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.Approval = nil
+	if err := registry.EnsurePlanExecutable(plan, pub); err != nil || plan.Approval.KeyID != KeyID(pub) {
+		t.Fatalf("signed registry approval: err=%v approval=%#v", err, plan.Approval)
+	}
+}
+
 func TestRequireApprovalReasonRequired(t *testing.T) {
 	_, err := newPlanTestQuery(&recordingExec{}).
 		RequireApproval("  ").
@@ -261,3 +372,210 @@ func TestRiskEngineConfigOverrides(t *testing.T) {
 		}
 	}
 }
+
+// resetRiskRules clears registered custom rules.
+func resetRiskRules() {
+	riskRuleRegistry.Lock()
+	defer riskRuleRegistry.Unlock()
+	riskRuleRegistry.byCode = make(map[string]RiskRule)
+}
+
+func TestCustomRiskRules(t *testing.T) {
+	t.Cleanup(resetRiskRules)
+	ordersFilterStatus := RiskRule{
+		Code: "ORDERS_STATUS_FILTER_MISSING",
+		Check: func(plan *QueryPlan) []Warning {
+			for _, table := range plan.Tables {
+				if table.Name != "orders" {
+					continue
+				}
+				for _, p := range plan.Predicates {
+					if p.Column == "status" {
+						return nil
+					}
+				}
+				return []Warning{{Level: RiskHigh, Message: "orders queries must filter status", Suppressible: true}}
+			}
+			return nil
+		},
+	}
+	if err := RegisterRiskRule(RiskRule{Code: "NO_CHECK"}); err == nil {
+		t.Fatal("expected rule without Check to be rejected")
+	}
+	if err := RegisterRiskRule(ordersFilterStatus); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := New(&recordingExec{}, "orders", ormdriver.MySQLDialect{}).Select("id").Where("id", 1).Limit(1).Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !warningCodeSet(plan.Warnings)["ORDERS_STATUS_FILTER_MISSING"] || plan.RiskLevel != RiskHigh || !plan.RequiredApproval {
+		t.Fatalf("expected registered rule to apply to plans: %#v", plan.Warnings)
+	}
+
+	disabled := false
+	result := NewRiskEngine(RiskConfig{Rules: map[string]RiskRuleConfig{
+		"ORDERS_STATUS_FILTER_MISSING": {Enabled: &disabled},
+	}}).CheckQuery(plan)
+	if warningCodeSet(result.Warnings)["ORDERS_STATUS_FILTER_MISSING"] {
+		t.Fatalf("expected config to disable custom rule: %#v", result.Warnings)
+	}
+
+	resetRiskRules()
+	engine := NewRiskEngine(RiskConfig{}, RiskRule{
+		Code:  "ORDER_BY_RAND",
+		Check: func(plan *QueryPlan) []Warning { return []Warning{{Message: "random order"}} },
+	})
+	result = engine.CheckQuery(plan)
+	codes := warningCodeSet(result.Warnings)
+	if codes["ORDERS_STATUS_FILTER_MISSING"] || !codes["ORDER_BY_RAND"] || result.Level != RiskMedium {
+		t.Fatalf("expected only engine rule after reset: %#v", result.Warnings)
+	}
+	if codes := warningCodeSet(DefaultRiskEngine.CheckQuery(plan).Warnings); codes["ORDER_BY_RAND"] {
+		t.Fatalf("engine rules must not leak into DefaultRiskEngine: %#v", codes)
+	}
+}
+
+func TestIndexAwareRiskWarnings(t *testing.T) {
+	ctx := context.Background()
+	engine := NewRiskEngine(RiskConfig{Indexes: map[string][]IndexInfo{
+		"users": {
+			{Name: "PRIMARY", Columns: []string{"id"}, Unique: true},
+			{Name: "users_email_unique", Columns: []string{"email"}, Unique: true},
+			{Name: "users_tenant_created", Columns: []string{"tenant_id", "created_at"}},
+		},
+	}})
+
+	plan, err := newPlanTestQuery(&recordingExec{}).Select("id").Where("name", "alice").OrderBy("name", "asc").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := warningCodeSet(engine.CheckQuery(plan).Warnings)
+	if !codes[WarningPredicateNotIndexed] || !codes[WarningOrderByNotIndexed] {
+		t.Fatalf("expected index warnings: %#v", codes)
+	}
+	if codes := warningCodeSet(DefaultRiskEngine.CheckQuery(plan).Warnings); codes[WarningPredicateNotIndexed] || codes[WarningOrderByNotIndexed] {
+		t.Fatalf("index rules need index metadata: %#v", codes)
+	}
+
+	plan, err = newPlanTestQuery(&recordingExec{}).Select("id").Where("tenant_id", 7).Where("name", "alice").OrderBy("created_at", "desc").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := warningCodeSet(engine.CheckQuery(plan).Warnings); codes[WarningPredicateNotIndexed] || codes[WarningOrderByNotIndexed] {
+		t.Fatalf("index prefix should satisfy predicate and order: %#v", codes)
+	}
+
+	update, err := newPlanTestQuery(&recordingExec{}).Where("email", "a@example.com").PlanUpdate(ctx, map[string]any{"name": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !warningCodeSet(update.Warnings)[WarningBulkUpdateDetected] {
+		t.Fatalf("expected bulk update without index metadata: %#v", update.Warnings)
+	}
+	if codes := warningCodeSet(engine.CheckQuery(update).Warnings); codes[WarningBulkUpdateDetected] || codes[WarningPredicateNotIndexed] {
+		t.Fatalf("unique index predicate should be primary-key-like: %#v", codes)
+	}
+
+	update, err = newPlanTestQuery(&recordingExec{}).Where("email", "a@example.com").OrWhere("name", "alice").PlanUpdate(ctx, map[string]any{"name": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !warningCodeSet(engine.CheckQuery(update).Warnings)[WarningBulkUpdateDetected] {
+		t.Fatalf("OR predicate must not count as a unique key: %#v", update.Warnings)
+	}
+
+	aliased := func() *Query { return New(&recordingExec{}, "users as u", ormdriver.MySQLDialect{}) }
+	plan, err = aliased().Select("u.id").Where("u.name", "alice").OrderBy("u.name", "asc").Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := warningCodeSet(engine.CheckQuery(plan).Warnings); !codes[WarningPredicateNotIndexed] || !codes[WarningOrderByNotIndexed] {
+		t.Fatalf("expected index warnings for alias-qualified columns: %#v", codes)
+	}
+	update, err = aliased().Where("u.email", "a@example.com").PlanUpdate(ctx, map[string]any{"name": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := warningCodeSet(engine.CheckQuery(update).Warnings); codes[WarningBulkUpdateDetected] {
+		t.Fatalf("alias-qualified unique index predicate should be primary-key-like: %#v", codes)
+	}
+}
+
+func TestStructuralRiskWarnings(t *testing.T) {
+	ResetPolicyRegistry()
+	t.Cleanup(ResetPolicyRegistry)
+	if err := RegisterTablePolicy(TablePolicy{Table: "users", PIIColumns: []string{"email"}}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	ids := make([]int, DefaultLargeInList+1)
+
+	footguns, err := newPlanTestQuery(&recordingExec{}).
+		Distinct("email").
+		Where("name", "like", "%son").
+		WhereYear("created_at", "=", "2024").
+		WhereIn("id", ids).
+		OrderByRaw("RAND()").
+		Offset(DefaultDeepOffset + 1).
+		Limit(10).
+		Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := warningCodeSet(footguns.Warnings)
+	for _, code := range []string{
+		WarningDeepOffsetPagination,
+		WarningLeadingWildcardLike,
+		WarningNonSargablePredicate,
+		WarningLargeInList,
+		WarningOrderByRandom,
+		WarningDistinctPII,
+	} {
+		if !codes[code] {
+			t.Errorf("missing %s in %#v", code, footguns.Warnings)
+		}
+	}
+	if !footguns.Predicates[0].LeadingWildcard {
+		t.Fatalf("expected leading wildcard metadata: %#v", footguns.Predicates[0])
+	}
+
+	plan, err := newPlanTestQuery(&recordingExec{}).Select("id").Where("name", "like", "son%").WhereIn("id", []int{1, 2}).Offset(100).Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes = warningCodeSet(plan.Warnings)
+	if codes[WarningLeadingWildcardLike] || codes[WarningDeepOffsetPagination] || codes[WarningLargeInList] {
+		t.Fatalf("unexpected structural warnings: %#v", plan.Warnings)
+	}
+
+	arrayIDs := make([]int64, DefaultLargeInList+1)
+	array, err := New(&recordingExec{}, "users", ormdriver.PostgresDialect{}).PostgresArrayIn().Select("id").WhereIn("id", arrayIDs).Limit(10).Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := warningCodeSet(array.Warnings); codes[WarningLargeInList] {
+		t.Fatalf("= ANY($1) binds one array and must not raise %s: %#v", WarningLargeInList, array.Warnings)
+	}
+	array.Predicates[0].ValueCount = DefaultLargeInList + 1
+	if codes := warningCodeSet(DefaultRiskEngine.CheckQuery(array).Warnings); codes[WarningLargeInList] {
+		t.Fatalf("array predicates recorded with their length must not raise %s", WarningLargeInList)
+	}
+	if codes := warningCodeSet(NewRiskEngine(RiskConfig{DeepOffset: 50}).CheckQuery(plan).Warnings); !codes[WarningDeepOffsetPagination] {
+		t.Fatalf("expected configured offset threshold: %#v", codes)
+	}
+	disabled := false
+	result := NewRiskEngine(RiskConfig{Rules: map[string]RiskRuleConfig{
+		WarningLeadingWildcardLike: {Enabled: &disabled},
+	}}).CheckQuery(footguns)
+	if codes := warningCodeSet(result.Warnings); codes[WarningLeadingWildcardLike] || !codes[WarningOrderByRandom] {
+		t.Fatalf("expected only LEADING_WILDCARD_LIKE disabled: %#v", codes)
+	}
+
+	raw := NewRawPlan("SELECT id FROM users WHERE name LIKE '%son' ORDER BY RANDOM() LIMIT 10 OFFSET 50000")
+	codes = warningCodeSet(raw.Warnings)
+	if !codes[WarningLeadingWildcardLike] || !codes[WarningOrderByRandom] || !codes[WarningDeepOffsetPagination] {
+		t.Fatalf("expected raw structural warnings: %#v", raw.Warnings)
+	}
+}
@@ -0,0 +1,164 @@
+package query
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrApprovalUnsigned         = errors.New("goquent: approval is not signed")
+	ErrApprovalExpired          = errors.New("goquent: approval expired")
+	ErrApprovalSignatureInvalid = errors.New("goquent: approval signature is not valid")
+)
+
+// ApprovalPayload returns the bytes signed for an approval of subject, a plan
+// fingerprint or migration checksum, by approver until expiresAt.
+func ApprovalPayload(subject, approver string, expiresAt *time.Time) []byte {
+	expires := ""
+	if expiresAt != nil {
+		expires = expiresAt.UTC().Format(time.RFC3339)
+	}
+	return []byte("goquent-approval-v1\nsubject: " + subject + "\napprover: " + approver + "\nexpires_at: " + expires + "\n")
+}
+
+// KeyID returns a short identifier for a public key.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SignApproval binds a to subject and signs it with key. a.CreatedBy is the
+// signed approver and must be set.
+func SignApproval(a *Approval, subject string, key ed25519.PrivateKey) error {
+	if a == nil {
+		return fmt.Errorf("goquent: approval is nil")
+	}
+	if strings.TrimSpace(a.CreatedBy) == "" {
+		return fmt.Errorf("goquent: signed approval requires an approver")
+	}
+	if strings.TrimSpace(subject) == "" {
+		return fmt.Errorf("goquent: signed approval requires a fingerprint or checksum")
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("goquent: invalid ed25519 private key")
+	}
+	a.Fingerprint = subject
+	a.KeyID = KeyID(key.Public().(ed25519.PublicKey))
+	a.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, ApprovalPayload(subject, a.CreatedBy, a.ExpiresAt)))
+	return nil
+}
+
+// VerifyApproval checks that a approves subject, has not expired and is
+// signed by one of trusted. It returns ErrApprovalUnsigned,
+// ErrApprovalExpired or ErrApprovalSignatureInvalid.
+func VerifyApproval(a *Approval, subject string, trusted []ed25519.PublicKey) error {
+	if a == nil || a.Signature == "" {
+		return ErrApprovalUnsigned
+	}
+	if a.Fingerprint != subject {
+		return fmt.Errorf("%w: signed for %s, not %s", ErrApprovalSignatureInvalid, a.Fingerprint, subject)
+	}
+	if a.ExpiresAt != nil && !a.ExpiresAt.After(time.Now().UTC()) {
+		return fmt.Errorf("%w at %s", ErrApprovalExpired, a.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	sig, err := base64.StdEncoding.DecodeString(a.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrApprovalSignatureInvalid, err)
+	}
+	payload := ApprovalPayload(subject, a.CreatedBy, a.ExpiresAt)
+	for _, pub := range trusted {
+		if ed25519.Verify(pub, payload, sig) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no trusted key matches key %s", ErrApprovalSignatureInvalid, a.KeyID)
+}
+
+// ParsePublicKeys parses PEM "PUBLIC KEY" blocks holding ed25519 keys, as
+// written by `openssl pkey -pubout`.
+func ParsePublicKeys(b []byte) ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("goquent: parse public key: %w", err)
+		}
+		pub, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("goquent: public key is %T, not ed25519", parsed)
+		}
+		keys = append(keys, pub)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("goquent: no PEM public keys found")
+	}
+	return keys, nil
+}
+
+// LoadTrustedKeys reads the ed25519 public keys in the PEM file at path.
+func LoadTrustedKeys(path string) ([]ed25519.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := ParsePublicKeys(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// ParsePrivateKey parses a PEM "PRIVATE KEY" block holding an ed25519 key, as
+// written by `openssl genpkey -algorithm ed25519`.
+func ParsePrivateKey(b []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("goquent: no PEM private key found")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("goquent: parse private key: %w", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("goquent: private key is %T, not ed25519", parsed)
+	}
+	return key, nil
+}
+
+// WithTrustedKeys requires approvals of risky executions of the query to be
+// signed by one of keys.
+func (q *Query) WithTrustedKeys(keys ...ed25519.PublicKey) *Query {
+	q = q.derive()
+	q.trustedKeys = append([]ed25519.PublicKey(nil), keys...)
+	return q
+}
+
+// ensureSignedApproval verifies the approval of a plan that requires one when
+// trusted keys are configured.
+func ensureSignedApproval(a *Approval, subject string, trusted []ed25519.PublicKey) error {
+	if len(trusted) == 0 {
+		return nil
+	}
+	if err := VerifyApproval(a, subject, trusted); err != nil {
+		return fmt.Errorf("%w: %w", ErrApprovalRequired, err)
+	}
+	return nil
+}
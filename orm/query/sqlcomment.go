@@ -0,0 +1,97 @@
+package query
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SQLCommenter returns the comment appended to the SQL sent to the database
+// for plan, or "" for none. The comment is never part of QueryPlan.SQL.
+type SQLCommenter func(ctx context.Context, plan *QueryPlan) string
+
+// WithSQLCommenter sets the commenter applied to every execution of the
+// query.
+func (q *Query) WithSQLCommenter(c SQLCommenter) *Query {
+	q = q.derive()
+	q.commenter = c
+	return q
+}
+
+// FormatSQLComment serializes tags as a sqlcommenter comment:
+// keys and values are URL-encoded, values are single-quoted with ' escaped,
+// and pairs are sorted by key. Empty keys and values are skipped.
+//
+//	/*app='billing',route='%2Finvoices'*/
+func FormatSQLComment(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k, v := range tags {
+		if k != "" && v != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		value := strings.ReplaceAll(url.QueryEscape(tags[k]), "'", `\'`)
+		pairs[i] = url.QueryEscape(k) + "='" + value + "'"
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// AppendSQLComment appends comment to sqlStr, before a trailing semicolon.
+// SQL that already ends in a comment, or whose end cannot be found outside a
+// string literal, is returned unchanged; comments and "--" elsewhere in the
+// statement, including inside literals, do not prevent the append.
+func AppendSQLComment(sqlStr, comment string) string {
+	if comment == "" {
+		return sqlStr
+	}
+	trimmed := strings.TrimRight(sqlStr, " \t\r\n")
+	body := strings.TrimRight(strings.TrimSuffix(trimmed, ";"), " \t\r\n")
+	// The statement may be MySQL or standard SQL, so it must end in plain
+	// SQL under both string quoting rules.
+	if !endsOutsideComment(body, false) || !endsOutsideComment(body, true) {
+		return sqlStr
+	}
+	if len(body) < len(trimmed) {
+		return body + " " + comment + ";"
+	}
+	return body + " " + comment
+}
+
+// endsOutsideComment reports whether the last token of sqlStr is neither a
+// comment nor an unterminated string literal or quoted identifier.
+func endsOutsideComment(sqlStr string, backslash bool) bool {
+	ok := true
+	for i := 0; i < len(sqlStr); {
+		end := skipSQLLiteral(sqlStr, i, backslash)
+		if end == i {
+			if c := sqlStr[i]; c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				ok = true
+			}
+			i++
+			continue
+		}
+		switch c := sqlStr[i]; c {
+		case '-', '/':
+			ok = false
+		default:
+			ok = end < len(sqlStr) || (end-i >= 2 && sqlStr[end-1] == c)
+		}
+		i = end
+	}
+	return ok
+}
+
+// executedSQL returns the SQL sent to the database for plan.
+func (q *Query) executedSQL(ctx context.Context, plan *QueryPlan) string {
+	if q.commenter == nil {
+		return plan.SQL
+	}
+	return AppendSQLComment(plan.SQL, q.commenter(ctx, plan))
+}
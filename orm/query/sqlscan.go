@@ -0,0 +1,111 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/faciam-dev/goquent/orm/driver"
+)
+
+// backslashEscapes reports whether d treats a backslash inside a plain string
+// literal as an escape character. Only MySQL does by default; standard SQL
+// and PostgreSQL honour backslashes only in E'...' strings.
+func backslashEscapes(d driver.Dialect) bool {
+	_, ok := d.(driver.MySQLDialect)
+	return ok
+}
+
+// rewriteSQL copies sqlStr while letting visit replace tokens that appear
+// outside string literals, quoted identifiers, and comments. visit returns the
+// replacement text, the index to resume from, and whether it consumed input at i.
+// backslash selects MySQL string escaping (see backslashEscapes).
+func rewriteSQL(sqlStr string, backslash bool, visit func(s string, i int) (string, int, bool, error)) (string, error) {
+	var b strings.Builder
+	b.Grow(len(sqlStr))
+	for i := 0; i < len(sqlStr); {
+		if end := skipSQLLiteral(sqlStr, i, backslash); end > i {
+			b.WriteString(sqlStr[i:end])
+			i = end
+			continue
+		}
+		replacement, next, ok, err := visit(sqlStr, i)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			b.WriteString(replacement)
+			i = next
+			continue
+		}
+		b.WriteByte(sqlStr[i])
+		i++
+	}
+	return b.String(), nil
+}
+
+// skipSQLLiteral returns the end offset of a quoted string, quoted identifier,
+// or comment starting at i. It returns i when no such token starts there.
+// Backslash escapes inside '...' apply when backslash is set or the literal is
+// an E'...' escape string.
+func skipSQLLiteral(s string, i int, backslash bool) int {
+	switch s[i] {
+	case '\'', '"', '`':
+		quote := s[i]
+		escapes := quote == '\'' && (backslash || isEscapeStringPrefix(s, i))
+		j := i + 1
+		for j < len(s) {
+			if escapes && s[j] == '\\' && j+1 < len(s) {
+				j += 2
+				continue
+			}
+			if s[j] == quote {
+				if j+1 < len(s) && s[j+1] == quote {
+					j += 2
+					continue
+				}
+				return j + 1
+			}
+			j++
+		}
+		return len(s)
+	case '-':
+		if i+1 < len(s) && s[i+1] == '-' {
+			if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+				return i + end
+			}
+			return len(s)
+		}
+	case '/':
+		if i+1 < len(s) && s[i+1] == '*' {
+			if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+				return i + 2 + end + 2
+			}
+			return len(s)
+		}
+	}
+	return i
+}
+
+// isEscapeStringPrefix reports whether the quote at i opens a PostgreSQL
+// E'...' escape string.
+func isEscapeStringPrefix(s string, i int) bool {
+	if i == 0 || (s[i-1] != 'E' && s[i-1] != 'e') {
+		return false
+	}
+	if i == 1 {
+		return true
+	}
+	c := s[i-2]
+	return !(c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z')
+}
+
+// countPositionalPlaceholders counts ? placeholders outside literals and comments.
+func countPositionalPlaceholders(sqlStr string, backslash bool) int {
+	count := 0
+	_, _ = rewriteSQL(sqlStr, backslash, func(s string, i int) (string, int, bool, error) {
+		if s[i] == '?' {
+			count++
+		}
+		return "", 0, false, nil
+	})
+	return count
+}
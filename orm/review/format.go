@@ -33,6 +33,8 @@ func WritePretty(w io.Writer, report ReviewReport) error {
 		suffix := ""
 		if finding.Suppressed {
 			suffix = " suppressed"
+		} else if finding.Approval != nil {
+			suffix = " approved"
 		}
 		if _, err := fmt.Fprintf(w, "[%s%s] %s: %s\n", riskLabel(finding.Level), suffix, finding.Code, finding.Message); err != nil {
 			return err
@@ -48,6 +50,16 @@ func WritePretty(w io.Writer, report ReviewReport) error {
 				}
 			}
 		}
+		if finding.Query != "" {
+			if _, err := fmt.Fprintf(w, "  query: %s\n", finding.Query); err != nil {
+				return err
+			}
+		}
+		if finding.Fingerprint != "" {
+			if _, err := fmt.Fprintf(w, "  fingerprint: %s\n", finding.Fingerprint); err != nil {
+				return err
+			}
+		}
 		if _, err := fmt.Fprintf(w, "  precision: %s\n", finding.AnalysisPrecision); err != nil {
 			return err
 		}
@@ -61,6 +73,11 @@ func WritePretty(w io.Writer, report ReviewReport) error {
 				return err
 			}
 		}
+		if finding.Approval != nil {
+			if _, err := fmt.Fprintf(w, "  approval_reason: %s\n", finding.Approval.Reason); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -1,12 +1,15 @@
 package review
 
 import (
+	"context"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"reflect"
 	"strconv"
 	"strings"
 
+	"github.com/faciam-dev/goquent/orm/driver"
 	"github.com/faciam-dev/goquent/orm/query"
 )
 
@@ -15,14 +18,20 @@ type chainCall struct {
 	Call   *ast.CallExpr
 }
 
-func reviewGoFile(path string) ([]Finding, error) {
+// reviewGoFile reviews the raw SQL and builder chains of a Go file. It also
+// returns the fingerprints of the queries it reconstructed, and whether every
+// query it found could be fingerprinted. Builder chains are replayed with
+// dialect.
+func reviewGoFile(path string, dialect driver.Dialect) ([]Finding, []string, bool, error) {
 	fset := token.NewFileSet()
 	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
 	if err != nil {
-		return nil, err
+		return nil, nil, false, err
 	}
 
 	var findings []Finding
+	var fingerprints []string
+	complete := true
 	ast.Inspect(file, func(n ast.Node) bool {
 		call, ok := n.(*ast.CallExpr)
 		if !ok {
@@ -35,10 +44,24 @@ func reviewGoFile(path string) ([]Finding, error) {
 
 		loc := sourceLocation(fset, path, sel.Sel.Pos())
 		if isRawSQLMethod(sel.Sel.Name) {
-			findings = append(findings, reviewRawSQLCall(sel, call, loc)...)
+			found := reviewRawSQLCall(sel, call, loc)
+			for _, finding := range found {
+				if finding.Fingerprint != "" {
+					fingerprints = append(fingerprints, finding.Fingerprint)
+				} else if finding.Code == query.WarningStaticReviewUnsupported {
+					complete = false
+				}
+			}
+			findings = append(findings, found...)
 		}
 		if isGoquentTerminal(sel.Sel.Name) {
-			findings = append(findings, reviewGoquentChain(sel, call, loc)...)
+			found, fingerprint, isChain := reviewGoquentChain(sel, call, loc, dialect)
+			if fingerprint != "" {
+				fingerprints = append(fingerprints, fingerprint)
+			} else if isChain {
+				complete = false
+			}
+			findings = append(findings, found...)
 		}
 		if isRawBuilderMethod(sel.Sel.Name) {
 			findings = append(findings, reviewRawBuilderCall(sel, call, loc)...)
@@ -46,7 +69,8 @@ func reviewGoFile(path string) ([]Finding, error) {
 		return true
 	})
 
-	return applyFileSuppressions(path, findings)
+	findings, err = applyFileSuppressions(path, findings)
+	return findings, fingerprints, complete, err
 }
 
 func reviewRawSQLCall(sel *ast.SelectorExpr, call *ast.CallExpr, loc *query.SourceLocation) []Finding {
@@ -56,7 +80,7 @@ func reviewRawSQLCall(sel *ast.SelectorExpr, call *ast.CallExpr, loc *query.Sour
 	}
 	sqlText, ok := stringLiteralValue(call.Args[argIndex])
 	if !ok {
-		if sel.Sel.Name == "RawPlan" || receiverLooksDatabase(sel.X) {
+		if sel.Sel.Name == "RawPlan" || sel.Sel.Name == "NamedPlan" || receiverLooksDatabase(sel.X) {
 			return []Finding{staticFinding(
 				query.WarningStaticReviewUnsupported,
 				query.RiskMedium,
@@ -74,13 +98,16 @@ func reviewRawSQLCall(sel *ast.SelectorExpr, call *ast.CallExpr, loc *query.Sour
 	return findingsFromPlan(query.NewRawPlan(sqlText), query.AnalysisPrecise, loc)
 }
 
-func reviewGoquentChain(sel *ast.SelectorExpr, call *ast.CallExpr, loc *query.SourceLocation) []Finding {
+// reviewGoquentChain reviews a builder chain ending in a terminal call. It
+// returns the fingerprint of the replayed plan, empty when the chain could
+// not be replayed, and whether the call looked like a Goquent query at all.
+func reviewGoquentChain(sel *ast.SelectorExpr, call *ast.CallExpr, loc *query.SourceLocation, dialect driver.Dialect) ([]Finding, string, bool) {
 	calls, root, _ := collectChainCalls(call)
 	if !chainHasRootBuilder(calls) {
 		if receiverLooksQuery(sel.X) || receiverLooksQuery(root) {
-			return []Finding{staticReviewPartial(loc)}
+			return []Finding{staticReviewPartial(loc)}, "", true
 		}
-		return nil
+		return nil, "", false
 	}
 
 	method := sel.Sel.Name
@@ -148,7 +175,28 @@ func reviewGoquentChain(sel *ast.SelectorExpr, call *ast.CallExpr, loc *query.So
 			))
 		}
 	}
-	return findings
+	plan, ok := staticChainPlan(calls, dialect)
+	if !ok {
+		return findings, "", true
+	}
+	findings = append(findings, warningsToFindings(customRuleWarnings(plan), query.AnalysisPrecise, loc)...)
+	return withFingerprint(findings, plan.Fingerprint), plan.Fingerprint, true
+}
+
+// customRuleWarnings returns the warnings of plan raised by rules registered
+// with query.RegisterRiskRule; the built-in rules are checked above.
+func customRuleWarnings(plan *query.QueryPlan) []query.Warning {
+	custom := make(map[string]bool)
+	for _, rule := range query.RegisteredRiskRules() {
+		custom[rule.Code] = true
+	}
+	var warnings []query.Warning
+	for _, w := range plan.Warnings {
+		if custom[w.Code] {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
 }
 
 func reviewRawBuilderCall(sel *ast.SelectorExpr, call *ast.CallExpr, loc *query.SourceLocation) []Finding {
@@ -206,6 +254,224 @@ func sourceLocation(fset *token.FileSet, path string, pos token.Pos) *query.Sour
 	return &query.SourceLocation{File: path, Line: p.Line, Column: p.Column}
 }
 
+var (
+	queryType   = reflect.TypeOf((*query.Query)(nil))
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
+
+// staticChainPlan replays a Table("name") builder chain ending in terminal
+// against a query.Query without an executor and returns the plan it builds,
+// so static findings carry the fingerprint the query has at runtime. Only
+// literal table, column and operator arguments are replayed; other values
+// are bound as placeholders. It reports false when the chain cannot be
+// replayed, e.g. for Model roots, callbacks or dynamic column names. The
+// query is built with dialect and the registered table policies.
+func staticChainPlan(calls []chainCall, dialect driver.Dialect) (plan *query.QueryPlan, ok bool) {
+	defer func() {
+		// A placeholder the builder cannot handle must not abort the review.
+		if recover() != nil {
+			plan, ok = nil, false
+		}
+	}()
+	return replayChain(calls, dialect)
+}
+
+func replayChain(calls []chainCall, dialect driver.Dialect) (*query.QueryPlan, bool) {
+	if len(calls) < 2 || calls[0].Call == nil {
+		return nil, false
+	}
+	root := -1
+	for i, call := range calls {
+		if call.Method == "Table" {
+			root = i
+			break
+		}
+	}
+	if root <= 0 || calls[root].Call == nil || len(calls[root].Call.Args) != 1 {
+		return nil, false
+	}
+	table, ok := stringLiteralValue(calls[root].Call.Args[0])
+	if !ok {
+		return nil, false
+	}
+	q := query.New(nil, table, dialect)
+	for i := root - 1; i > 0; i-- {
+		if q, ok = replayChainCall(q, calls[i]); !ok {
+			return nil, false
+		}
+	}
+
+	terminal := calls[0]
+	ctx := context.Background()
+	var plan *query.QueryPlan
+	var err error
+	switch terminal.Method {
+	case "Get", "GetMaps", "First", "FirstMap", "Plan":
+		plan, err = q.Plan(ctx)
+	case "Delete", "PlanDelete":
+		plan, err = q.PlanDelete(ctx)
+	case "Update", "PlanUpdate":
+		args := terminal.Call.Args
+		if terminal.Method == "PlanUpdate" && len(args) == 2 {
+			args = args[1:]
+		}
+		if len(args) != 1 {
+			return nil, false
+		}
+		data, ok := staticUpdateData(args[0])
+		if !ok {
+			return nil, false
+		}
+		plan, err = q.PlanUpdate(ctx, data)
+	default:
+		return nil, false
+	}
+	if err != nil || plan == nil {
+		return nil, false
+	}
+	return plan, true
+}
+
+// replayChainCall applies one builder call to q through reflection.
+func replayChainCall(q *query.Query, call chainCall) (*query.Query, bool) {
+	if call.Call == nil || call.Call.Ellipsis.IsValid() {
+		return nil, false
+	}
+	method := reflect.ValueOf(q).MethodByName(call.Method)
+	if !method.IsValid() {
+		return nil, false
+	}
+	mt := method.Type()
+	if mt.NumOut() != 1 || mt.Out(0) != queryType {
+		return nil, false
+	}
+	if len(call.Call.Args) < mt.NumIn()-1 || (!mt.IsVariadic() && len(call.Call.Args) != mt.NumIn()) {
+		return nil, false
+	}
+	in := make([]reflect.Value, 0, len(call.Call.Args))
+	for i, arg := range call.Call.Args {
+		var pt reflect.Type
+		if mt.IsVariadic() && i >= mt.NumIn()-1 {
+			pt = mt.In(mt.NumIn() - 1).Elem()
+		} else {
+			pt = mt.In(i)
+		}
+		v, ok := staticArgValue(arg, pt, listArgMethod(call.Method))
+		if !ok {
+			return nil, false
+		}
+		in = append(in, v)
+	}
+	next, _ := method.Call(in)[0].Interface().(*query.Query)
+	return next, next != nil
+}
+
+// staticArgValue converts a call argument to a value of type t. Literals
+// keep their value; other expressions are only accepted for interface
+// parameters, where they bind as a placeholder, or a placeholder list when
+// list is set.
+func staticArgValue(arg ast.Expr, t reflect.Type, list bool) (reflect.Value, bool) {
+	if t == contextType {
+		return reflect.ValueOf(context.Background()), true
+	}
+	if lit, ok := literalValue(arg); ok {
+		lt := reflect.TypeOf(lit)
+		if t.Kind() == reflect.Interface || lt.Kind() == t.Kind() || (isNumberKind(lt.Kind()) && isNumberKind(t.Kind())) {
+			if lt.ConvertibleTo(t) {
+				return reflect.ValueOf(lit).Convert(t), true
+			}
+		}
+		return reflect.Value{}, false
+	}
+	if t.Kind() != reflect.Interface || !reflect.TypeOf(0).Implements(t) {
+		return reflect.Value{}, false
+	}
+	if list {
+		return reflect.ValueOf([]any{0}), true
+	}
+	return reflect.ValueOf(0), true
+}
+
+// listArgMethod reports whether the value argument of method is a list.
+func listArgMethod(method string) bool {
+	switch method {
+	case "WhereIn", "WhereNotIn", "OrWhereIn", "OrWhereNotIn", "WhereArrayContains", "WhereArrayOverlaps":
+		return true
+	default:
+		return false
+	}
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// literalValue returns the Go value of a string, number or bool literal.
+func literalValue(expr ast.Expr) (any, bool) {
+	if s, ok := stringLiteralValue(expr); ok {
+		return s, true
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return literalValue(e.X)
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	case *ast.UnaryExpr:
+		if e.Op != token.SUB {
+			return nil, false
+		}
+		v, _ := literalValue(e.X)
+		switch n := v.(type) {
+		case int:
+			return -n, true
+		case float64:
+			return -n, true
+		}
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			if n, err := strconv.ParseInt(e.Value, 0, 64); err == nil {
+				return int(n), true
+			}
+		case token.FLOAT:
+			if f, err := strconv.ParseFloat(e.Value, 64); err == nil {
+				return f, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// staticUpdateData returns the columns of a map literal passed to Update with
+// placeholder values.
+func staticUpdateData(expr ast.Expr) (map[string]any, bool) {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := lit.Type.(*ast.MapType); !ok {
+		return nil, false
+	}
+	data := make(map[string]any, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, false
+		}
+		col, ok := stringLiteralValue(kv.Key)
+		if !ok {
+			return nil, false
+		}
+		data[col] = 0
+	}
+	return data, len(data) > 0
+}
+
 func collectChainCalls(expr ast.Expr) ([]chainCall, ast.Expr, bool) {
 	var calls []chainCall
 	for expr != nil {
@@ -310,7 +576,7 @@ func isPredicateMethod(method string) bool {
 
 func isRawSQLMethod(method string) bool {
 	switch method {
-	case "Exec", "ExecContext", "Query", "QueryContext", "QueryRow", "QueryRowContext", "RawPlan":
+	case "Exec", "ExecContext", "Query", "QueryContext", "QueryRow", "QueryRowContext", "RawPlan", "NamedQuery", "NamedExec", "NamedPlan":
 		return true
 	default:
 		return false
@@ -321,7 +587,7 @@ func rawSQLArgIndex(method string) int {
 	switch method {
 	case "Exec", "Query", "QueryRow":
 		return 0
-	case "ExecContext", "QueryContext", "QueryRowContext", "RawPlan":
+	case "ExecContext", "QueryContext", "QueryRowContext", "RawPlan", "NamedQuery", "NamedExec", "NamedPlan":
 		return 1
 	default:
 		return -1
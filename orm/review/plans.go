@@ -0,0 +1,149 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	"github.com/faciam-dev/goquent/orm/config"
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// CollectPlans returns allowlist entries for the query plans that can be
+// reconstructed statically from paths: QueryPlan JSON files, named queries
+// of SQL catalogs, other non-migration SQL files, raw SQL string literals
+// passed to Query, Exec and Named* calls in Go source, and Go builder chains
+// rooted at Table("name") with literal arguments. Chains rooted at Model or
+// using callbacks or dynamic column names are skipped; emit their QueryPlan
+// JSON from tests instead. Generic CRUD plans (orm.Insert, Update, Upsert
+// and friends) are built from runtime values and are not collected either.
+//
+// cfg, when non-nil, must be the configuration of the application: its table
+// policies are registered and its dialect is used, so that chains get the
+// soft-delete predicates and SQL they have at runtime.
+func CollectPlans(paths []string, cfg *config.Config) ([]query.AllowlistEntry, error) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	var entries []query.AllowlistEntry
+	var errs []error
+	var dialect driver.Dialect = driver.MySQLDialect{}
+	if cfg != nil {
+		if err := cfg.RegisterPolicies(); err != nil {
+			return nil, err
+		}
+		dialect = cfg.SQLDialect()
+	}
+	for _, path := range paths {
+		files, err := discoverFiles(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, file := range files {
+			found, err := collectFilePlans(file, dialect)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			entries = append(entries, found...)
+		}
+	}
+	return entries, errors.Join(errs...)
+}
+
+func collectFilePlans(path string, dialect driver.Dialect) ([]query.AllowlistEntry, error) {
+	switch filepath.Ext(path) {
+	case ".go":
+		return collectGoPlans(path, dialect)
+	case ".sql":
+		return collectSQLPlans(path)
+	case ".json":
+		return collectPlanJSON(path)
+	default:
+		return nil, nil
+	}
+}
+
+func collectSQLPlans(path string) ([]query.AllowlistEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sqlText := string(b)
+	if query.IsCatalog(sqlText) {
+		catalog, err := query.ParseCatalog(path, sqlText)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]query.AllowlistEntry, 0, len(catalog))
+		for _, entry := range catalog {
+			entries = append(entries, query.NewAllowlistEntry(query.NewRawPlan(entry.SQL), fmt.Sprintf("%s:%d %s", path, entry.Location.Line, entry.Name)))
+		}
+		return entries, nil
+	}
+	if looksLikeMigrationSQL(sqlText) || !looksLikeSQL(sqlText) {
+		return nil, nil
+	}
+	return []query.AllowlistEntry{query.NewAllowlistEntry(query.NewRawPlan(sqlText), path)}, nil
+}
+
+func collectPlanJSON(path string) ([]query.AllowlistEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plan query.QueryPlan
+	if err := json.Unmarshal(b, &plan); err != nil || plan.Operation == "" || plan.SQL == "" {
+		return nil, nil
+	}
+	return []query.AllowlistEntry{query.NewAllowlistEntry(&plan, path)}, nil
+}
+
+func collectGoPlans(path string, dialect driver.Dialect) ([]query.AllowlistEntry, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	var entries []query.AllowlistEntry
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if isGoquentTerminal(sel.Sel.Name) {
+			calls, _, _ := collectChainCalls(call)
+			if plan, ok := staticChainPlan(calls, dialect); ok {
+				loc := sourceLocation(fset, path, sel.Sel.Pos())
+				entries = append(entries, query.NewAllowlistEntry(plan, fmt.Sprintf("%s:%d", loc.File, loc.Line)))
+			}
+			return true
+		}
+		if !isRawSQLMethod(sel.Sel.Name) {
+			return true
+		}
+		argIndex := rawSQLArgIndex(sel.Sel.Name)
+		if argIndex < 0 || argIndex >= len(call.Args) {
+			return true
+		}
+		sqlText, ok := stringLiteralValue(call.Args[argIndex])
+		if !ok || !looksLikeSQL(sqlText) {
+			return true
+		}
+		loc := sourceLocation(fset, path, sel.Sel.Pos())
+		entries = append(entries, query.NewAllowlistEntry(query.NewRawPlan(sqlText), fmt.Sprintf("%s:%d", loc.File, loc.Line)))
+		return true
+	})
+	return entries, nil
+}
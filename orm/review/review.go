@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/faciam-dev/goquent/orm/config"
+	"github.com/faciam-dev/goquent/orm/driver"
 	"github.com/faciam-dev/goquent/orm/manifest"
 	"github.com/faciam-dev/goquent/orm/migration"
 	"github.com/faciam-dev/goquent/orm/query"
@@ -26,6 +28,9 @@ type Finding struct {
 	AnalysisPrecision query.AnalysisPrecision `json:"analysis_precision"`
 	Suppressed        bool                    `json:"suppressed"`
 	Suppression       *query.Suppression      `json:"suppression,omitempty"`
+	Query             string                  `json:"query,omitempty"`
+	Fingerprint       string                  `json:"fingerprint,omitempty"`
+	Approval          *query.Approval         `json:"approval,omitempty"`
 }
 
 // ReviewSummary aggregates findings for machine-readable output and CI.
@@ -56,6 +61,20 @@ type Options struct {
 	ShowSuppressed       bool
 	ManifestPath         string
 	RequireFreshManifest bool
+	// ApprovalsPath is an approvals file (see query.ApprovalRegistry).
+	// Findings whose fingerprint it approves are reported as approved, and
+	// records matching no reviewed plan are reported as APPROVAL_STALE.
+	// goquent:approve comments in SQL catalogs count only when the file sets
+	// allow_inline or Config enables review.inline_approvals.
+	// Staleness is not reported when some reviewed Go query could not be
+	// fingerprinted, since a record may belong to that query.
+	ApprovalsPath string
+	// Config applies a goquent.yaml configuration: its table policies are
+	// registered, files are filtered by its review patterns, findings are
+	// re-leveled by the risk rules of Environment, and its suppressions are
+	// applied. Callers resolve the manifest path and fail-on threshold.
+	Config      *config.Config
+	Environment string
 }
 
 // Run reviews all configured paths.
@@ -67,14 +86,38 @@ func Run(opts Options) (ReviewReport, error) {
 
 	var report ReviewReport
 	var errs []error
+	var approvals *query.ApprovalRegistry
+	if strings.TrimSpace(opts.ApprovalsPath) != "" {
+		registry, err := query.LoadApprovals(opts.ApprovalsPath)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		approvals = registry
+	}
+	var riskConfig query.RiskConfig
+	var dialect driver.Dialect = driver.MySQLDialect{}
+	if opts.Config != nil {
+		if err := opts.Config.RegisterPolicies(); err != nil {
+			errs = append(errs, err)
+		}
+		riskConfig = opts.Config.RiskConfig(opts.Environment)
+		dialect = opts.Config.SQLDialect()
+	}
+	inlineApprovals := (approvals != nil && approvals.AllowInline) || (opts.Config != nil && opts.Config.Review.InlineApprovals)
+	seen := make(map[string]bool)
+	fingerprinted := true
+	var indexEngine query.RiskEngine
 	if strings.TrimSpace(opts.ManifestPath) != "" {
-		findings, status, err := reviewManifestFreshness(opts.ManifestPath)
+		findings, status, m, err := reviewManifestFreshness(opts.ManifestPath)
 		if err != nil {
 			errs = append(errs, err)
 		}
 		if status != nil {
 			report.ManifestStatus = status
 		}
+		if m != nil {
+			indexEngine = manifest.NewRiskEngine(m, query.RiskConfig{})
+		}
 		report.Findings = append(report.Findings, findings...)
 	}
 	for _, path := range paths {
@@ -84,12 +127,27 @@ func Run(opts Options) (ReviewReport, error) {
 			continue
 		}
 		for _, file := range files {
-			findings, err := reviewFile(file)
+			if opts.Config != nil && !opts.Config.IncludesFile(file) {
+				continue
+			}
+			findings, fingerprints, complete, err := reviewFile(file, indexEngine, dialect)
 			if err != nil {
 				errs = append(errs, err)
 				continue
 			}
+			fingerprinted = fingerprinted && complete
+			for _, fingerprint := range fingerprints {
+				seen[fingerprint] = true
+			}
+			if opts.Config != nil {
+				findings = applySuppressions(findings, opts.Config.SuppressionsFor(file))
+				findings = applyRiskConfig(findings, riskConfig)
+			}
 			for _, finding := range findings {
+				if !inlineApprovals {
+					finding.Approval = nil
+				}
+				applyRegistryApproval(&finding, approvals)
 				if finding.Suppressed {
 					report.SuppressedFindings = append(report.SuppressedFindings, finding)
 					if opts.ShowSuppressed {
@@ -101,21 +159,24 @@ func Run(opts Options) (ReviewReport, error) {
 			}
 		}
 	}
+	if approvals != nil && fingerprinted {
+		report.Findings = append(report.Findings, staleApprovalFindings(opts.ApprovalsPath, approvals, seen)...)
+	}
 	report.Summary = summarize(report)
 	return report, errors.Join(errs...)
 }
 
-func reviewManifestFreshness(path string) ([]Finding, *ManifestStatus, error) {
+func reviewManifestFreshness(path string) ([]Finding, *ManifestStatus, *manifest.Manifest, error) {
 	m, err := manifest.Load(path)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	status := &ManifestStatus{Fresh: true, Path: path}
 	if m.Verification != nil {
 		status.Fresh = m.Verification.Fresh
 	}
 	if m.Verification == nil || m.Verification.Fresh {
-		return nil, status, nil
+		return nil, status, m, nil
 	}
 	var evidence []query.Evidence
 	for _, check := range m.Verification.Checks {
@@ -131,7 +192,51 @@ func reviewManifestFreshness(path string) ([]Finding, *ManifestStatus, error) {
 		Hint:              "regenerate the manifest or run goquent manifest verify against current inputs",
 		Evidence:          evidence,
 		AnalysisPrecision: query.AnalysisPrecise,
-	}}, status, nil
+	}}, status, m, nil
+}
+
+// applyRegistryApproval marks finding approved when approvals holds an
+// unexpired record for its fingerprint.
+func applyRegistryApproval(finding *Finding, approvals *query.ApprovalRegistry) {
+	if finding.Suppressed || finding.Approval != nil || !requiresApprovalLevel(finding.Level) {
+		return
+	}
+	record, ok := approvals.Lookup(finding.Fingerprint)
+	if !ok || record.Expired(time.Now().UTC()) {
+		return
+	}
+	finding.Approval = record.Approval()
+}
+
+// staleApprovalFindings reports approval records whose fingerprint matched
+// none of the reviewed plans.
+func staleApprovalFindings(path string, approvals *query.ApprovalRegistry, seen map[string]bool) []Finding {
+	b, _ := os.ReadFile(path)
+	var findings []Finding
+	for _, record := range approvals.Records() {
+		if seen[record.Fingerprint] {
+			continue
+		}
+		line := 1
+		if idx := strings.Index(string(b), record.Fingerprint); idx >= 0 {
+			line += strings.Count(string(b[:idx]), "\n")
+		}
+		evidence := []query.Evidence{{Key: "reason", Value: record.Reason}}
+		if record.Approver != "" {
+			evidence = append(evidence, query.Evidence{Key: "approver", Value: record.Approver})
+		}
+		findings = append(findings, Finding{
+			Code:              query.WarningApprovalStale,
+			Level:             query.RiskLow,
+			Message:           "approval does not match any reviewed query",
+			Location:          &query.SourceLocation{File: path, Line: line},
+			Hint:              "remove the approval, or re-approve the query under its new fingerprint",
+			Evidence:          evidence,
+			AnalysisPrecision: query.AnalysisPrecise,
+			Fingerprint:       record.Fingerprint,
+		})
+	}
+	return findings
 }
 
 func discoverFiles(root string) ([]string, error) {
@@ -192,25 +297,49 @@ func supportedFile(path string) bool {
 	}
 }
 
-func reviewFile(path string) ([]Finding, error) {
+// reviewFile reviews one file and returns its findings, the fingerprints of
+// the query plans it contains, and whether every query in it could be
+// fingerprinted. indexEngine, when non-nil, re-checks QueryPlan JSON files
+// against the manifest indexes, and dialect is used to replay builder chains.
+func reviewFile(path string, indexEngine query.RiskEngine, dialect driver.Dialect) ([]Finding, []string, bool, error) {
+	var findings []Finding
+	var err error
 	switch filepath.Ext(path) {
 	case ".go":
-		return reviewGoFile(path)
+		return reviewGoFile(path, dialect)
 	case ".sql":
-		return reviewSQLFile(path)
+		findings, err = reviewSQLFile(path)
 	case ".json":
-		return reviewPlanJSONFile(path)
-	default:
-		return nil, nil
+		var fingerprint string
+		findings, fingerprint, err = reviewPlanJSONFile(path, indexEngine)
+		if fingerprint != "" {
+			return findings, []string{fingerprint}, true, err
+		}
+	}
+	var fingerprints []string
+	for _, finding := range findings {
+		if finding.Fingerprint != "" {
+			fingerprints = append(fingerprints, finding.Fingerprint)
+		}
 	}
+	return findings, fingerprints, true, err
 }
 
+// reviewSQLFile reviews a .sql file query by query when it is laid out as a
+// catalog, and otherwise as a migration or a single raw statement.
 func reviewSQLFile(path string) ([]Finding, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 	sqlText := string(b)
+	if query.IsCatalog(sqlText) {
+		entries, err := query.ParseCatalog(path, sqlText)
+		if err != nil {
+			return nil, err
+		}
+		return reviewCatalogEntries(entries), nil
+	}
 	if looksLikeMigrationSQL(sqlText) {
 		if plan, err := migration.PlanSQL(sqlText); err != nil {
 			return nil, err
@@ -224,6 +353,31 @@ func reviewSQLFile(path string) ([]Finding, error) {
 	return applyFileSuppressions(path, findings)
 }
 
+// reviewCatalogEntries reviews each `-- name:` query of a catalog file on its
+// own. Suppressions and approvals apply only to the query whose comments
+// declare them; expired approvals are ignored.
+func reviewCatalogEntries(entries []query.CatalogEntry) []Finding {
+	var findings []Finding
+	now := time.Now().UTC()
+	for _, entry := range entries {
+		loc := entry.Location
+		entryFindings := findingsFromPlan(query.NewRawPlan(entry.SQL), query.AnalysisPrecise, &loc)
+		approval := entry.Approval
+		if approval != nil && approval.ExpiresAt != nil && !approval.ExpiresAt.After(now) {
+			approval = nil
+		}
+		for _, finding := range applySuppressions(entryFindings, entry.Suppressions) {
+			finding.Query = entry.Name
+			if approval != nil && !finding.Suppressed && requiresApprovalLevel(finding.Level) {
+				copied := *approval
+				finding.Approval = &copied
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
 func looksLikeMigrationSQL(sqlText string) bool {
 	upper := strings.ToUpper(sqlText)
 	for _, token := range []string{"CREATE", "ALTER", "DROP", "RENAME", "GRANT", "REVOKE", "TRUNCATE"} {
@@ -234,33 +388,68 @@ func looksLikeMigrationSQL(sqlText string) bool {
 	return false
 }
 
-func reviewPlanJSONFile(path string) ([]Finding, error) {
+func reviewPlanJSONFile(path string, indexEngine query.RiskEngine) ([]Finding, string, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	var plan query.QueryPlan
 	if err := json.Unmarshal(b, &plan); err != nil {
-		return nil, nil
+		return nil, "", nil
 	}
 	if plan.Operation == "" || plan.SQL == "" {
 		migrationFindings, ok, err := reviewMigrationPlanJSON(path, b)
 		if err != nil || ok {
-			return migrationFindings, err
+			return migrationFindings, "", err
 		}
-		return nil, nil
+		return nil, "", nil
 	}
 	result := query.DefaultRiskEngine.CheckQuery(&plan)
+	if plan.Fingerprint == "" {
+		plan.Fingerprint = query.PlanFingerprint(&plan)
+	}
 	warnings := plan.Warnings
 	if len(warnings) == 0 {
 		warnings = result.Warnings
 	}
+	if indexEngine != nil {
+		warnings = mergeIndexWarnings(warnings, indexEngine.CheckQuery(&plan).Warnings)
+	}
 	findings := warningsToFindings(warnings, query.AnalysisPrecise, &query.SourceLocation{File: path, Line: 1})
 	for _, finding := range warningsToFindings(plan.SuppressedWarnings, query.AnalysisPrecise, &query.SourceLocation{File: path, Line: 1}) {
 		finding.Suppressed = true
 		findings = append(findings, finding)
 	}
-	return applyFileSuppressions(path, findings)
+	findings, err = applyFileSuppressions(path, withFingerprint(findings, plan.Fingerprint))
+	return findings, plan.Fingerprint, err
+}
+
+// mergeIndexWarnings adds the index warnings of an index-aware check to the
+// warnings recorded in a plan, and drops bulk warnings the check no longer
+// raises because a unique index pins the affected row.
+func mergeIndexWarnings(recorded, checked []query.Warning) []query.Warning {
+	codes := make(map[string]bool, len(checked))
+	for _, w := range checked {
+		codes[w.Code] = true
+	}
+	out := make([]query.Warning, 0, len(recorded)+2)
+	for _, w := range recorded {
+		switch w.Code {
+		case query.WarningBulkUpdateDetected, query.WarningBulkDeleteDetected:
+			if !codes[w.Code] {
+				continue
+			}
+		case query.WarningPredicateNotIndexed, query.WarningOrderByNotIndexed:
+			continue
+		}
+		out = append(out, w)
+	}
+	for _, w := range checked {
+		if w.Code == query.WarningPredicateNotIndexed || w.Code == query.WarningOrderByNotIndexed {
+			out = append(out, w)
+		}
+	}
+	return out
 }
 
 func reviewMigrationPlanJSON(path string, b []byte) ([]Finding, bool, error) {
@@ -288,7 +477,16 @@ func findingsFromPlan(plan *query.QueryPlan, precision query.AnalysisPrecision,
 	if plan == nil {
 		return nil
 	}
-	return warningsToFindings(plan.Warnings, precision, loc)
+	return withFingerprint(warningsToFindings(plan.Warnings, precision, loc), plan.Fingerprint)
+}
+
+// withFingerprint tags findings with the fingerprint of the plan that
+// produced them.
+func withFingerprint(findings []Finding, fingerprint string) []Finding {
+	for i := range findings {
+		findings[i].Fingerprint = fingerprint
+	}
+	return findings
 }
 
 func warningsToFindings(warnings []query.Warning, precision query.AnalysisPrecision, loc *query.SourceLocation) []Finding {
@@ -331,13 +529,21 @@ func applyFileSuppressions(path string, findings []Finding) ([]Finding, error) {
 	if err != nil {
 		return nil, err
 	}
-	if len(suppressions) == 0 {
-		return findings, nil
+	return applySuppressions(findings, suppressions), nil
+}
+
+func applySuppressions(findings []Finding, suppressions []query.Suppression) []Finding {
+	if len(findings) == 0 || len(suppressions) == 0 {
+		return findings
 	}
 
 	var out []Finding
 	now := time.Now().UTC()
 	for _, finding := range findings {
+		if finding.Suppressed {
+			out = append(out, finding)
+			continue
+		}
 		suppression, ok := findSuppressionForFinding(finding, suppressions)
 		if !ok {
 			out = append(out, finding)
@@ -357,7 +563,39 @@ func applyFileSuppressions(path string, findings []Finding) ([]Finding, error) {
 		finding.Suppression = &suppression
 		out = append(out, finding)
 	}
-	return out, nil
+	return out
+}
+
+// applyRiskConfig disables and re-levels findings by their rule
+// configuration. A finding made unsuppressible by config loses its
+// suppression and gains a SUPPRESSION_NOT_ALLOWED finding.
+func applyRiskConfig(findings []Finding, config query.RiskConfig) []Finding {
+	if len(findings) == 0 || len(config.Rules) == 0 {
+		return findings
+	}
+	out := make([]Finding, 0, len(findings))
+	for _, finding := range findings {
+		rule, ok := config.Rules[finding.Code]
+		if !ok {
+			out = append(out, finding)
+			continue
+		}
+		if rule.Enabled != nil && !*rule.Enabled {
+			continue
+		}
+		if rule.Severity != nil {
+			finding.Level = *rule.Severity
+		}
+		if rule.Suppressible != nil && !*rule.Suppressible && finding.Suppressed {
+			finding.Suppressed = false
+			finding.Suppression = nil
+			out = append(out, finding)
+			out = append(out, suppressionFinding(query.WarningSuppressionNotAllowed, "finding is not suppressible", finding.Location))
+			continue
+		}
+		out = append(out, finding)
+	}
+	return out
 }
 
 func suppressionsForFile(path string) ([]query.Suppression, error) {
@@ -387,7 +625,7 @@ func findSuppressionForFinding(finding Finding, suppressions []query.Suppression
 		if suppression.Code != finding.Code {
 			continue
 		}
-		if finding.Location == nil || suppression.Location == nil {
+		if finding.Location == nil || suppression.Location == nil || suppression.Scope == query.SuppressionScopeQuery {
 			return suppression, true
 		}
 		if suppression.Location.Line == finding.Location.Line || suppression.Location.Line+1 == finding.Location.Line {
@@ -440,7 +678,7 @@ func summarize(report ReviewReport) ReviewSummary {
 // HasFindingsAtOrAbove reports whether report should fail CI at threshold.
 func HasFindingsAtOrAbove(report ReviewReport, threshold query.RiskLevel) bool {
 	for _, finding := range report.Findings {
-		if finding.Suppressed {
+		if finding.Suppressed || finding.Approval != nil {
 			continue
 		}
 		if compareRisk(finding.Level, threshold) >= 0 {
@@ -472,6 +710,10 @@ func compareRisk(a, b query.RiskLevel) int {
 	return riskRank(a) - riskRank(b)
 }
 
+func requiresApprovalLevel(level query.RiskLevel) bool {
+	return compareRisk(level, query.RiskHigh) >= 0 && level != query.RiskBlocked
+}
+
 func riskRank(level query.RiskLevel) int {
 	switch level {
 	case query.RiskLow, "":
@@ -2,12 +2,16 @@ package review
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/faciam-dev/goquent/orm/config"
+	"github.com/faciam-dev/goquent/orm/driver"
 	"github.com/faciam-dev/goquent/orm/manifest"
 	"github.com/faciam-dev/goquent/orm/migration"
 	"github.com/faciam-dev/goquent/orm/query"
@@ -119,6 +123,64 @@ func TestRunReviewsStaleManifest(t *testing.T) {
 	}
 }
 
+func TestRunReviewsQueryPlanJSONAgainstManifestIndexes(t *testing.T) {
+	dir := t.TempDir()
+	m := &manifest.Manifest{Version: manifest.Version, Tables: []manifest.Table{{
+		Name:    "users",
+		Columns: []manifest.Column{{Name: "id", Primary: true}, {Name: "email"}, {Name: "name"}},
+		Indexes: []manifest.Index{{Name: "users_email_idx", Columns: []string{"email"}, Unique: true}},
+	}}}
+	b, err := m.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(dir, "goquent.manifest.json")
+	if err := os.WriteFile(manifestPath, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writePlan := func(name string, predicates []query.PredicateRef) {
+		t.Helper()
+		plan := &query.QueryPlan{
+			Operation:  query.OperationUpdate,
+			SQL:        "UPDATE users SET name = ? WHERE " + predicates[0].Column + " = ?",
+			Tables:     []query.TableRef{{Name: "users"}},
+			Predicates: predicates,
+		}
+		b, err := plan.ToJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePlan("by_email.json", []query.PredicateRef{{Column: "email", Operator: "=", ValueCount: 1}})
+
+	report, err := Run(Options{Paths: []string{dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasFinding(report.Findings, query.WarningBulkUpdateDetected) {
+		t.Fatalf("expected bulk update without manifest, got %#v", report.Findings)
+	}
+	report, err = Run(Options{Paths: []string{dir}, ManifestPath: manifestPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasFinding(report.Findings, query.WarningBulkUpdateDetected) || hasFinding(report.Findings, query.WarningPredicateNotIndexed) {
+		t.Fatalf("expected unique index update to pass, got %#v", report.Findings)
+	}
+
+	writePlan("by_name.json", []query.PredicateRef{{Column: "name", Operator: "=", ValueCount: 1}})
+	report, err = Run(Options{Paths: []string{dir}, ManifestPath: manifestPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasFinding(report.Findings, query.WarningPredicateNotIndexed) {
+		t.Fatalf("expected unindexed predicate finding, got %#v", report.Findings)
+	}
+}
+
 func TestRunReviewsSuppressedWarningsFromQueryPlanJSON(t *testing.T) {
 	dir := t.TempDir()
 	plan := query.QueryPlan{
@@ -306,3 +368,389 @@ func findFinding(findings []Finding, code string) (Finding, bool) {
 	}
 	return Finding{}, false
 }
+
+func TestRunReviewsCatalogQueriesIndividually(t *testing.T) {
+	dir := t.TempDir()
+	sqlPath := filepath.Join(dir, "reports.sql")
+	src := `-- Reporting queries.
+
+-- name: ListUsers :many
+-- goquent:suppress RAW_SQL_USED reason="report reviewed with the data team"
+SELECT id FROM users;
+
+-- name: ListOrders :many
+-- goquent:approve reason="finance report reviewed"
+SELECT id FROM orders;
+
+-- name: ListRefunds :many
+SELECT id FROM refunds;
+`
+	if err := os.WriteFile(sqlPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{Paths: []string{sqlPath}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, finding := range report.Findings {
+		if finding.Approval != nil {
+			t.Fatalf("inline approvals must not count without an approvals file or config, got %#v", finding)
+		}
+	}
+
+	report, err = Run(Options{Paths: []string{sqlPath}, Config: &config.Config{Review: config.Review{InlineApprovals: true}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.SuppressedFindings) != 1 || report.SuppressedFindings[0].Query != "ListUsers" {
+		t.Fatalf("expected only ListUsers to be suppressed, got %#v", report.SuppressedFindings)
+	}
+	byQuery := map[string]Finding{}
+	for _, finding := range report.Findings {
+		if finding.Code == query.WarningRawSQLUsed {
+			byQuery[finding.Query] = finding
+		}
+	}
+	orders, ok := byQuery["ListOrders"]
+	if !ok || orders.Approval == nil || orders.Approval.Reason != "finance report reviewed" {
+		t.Fatalf("expected approved ListOrders finding, got %#v", report.Findings)
+	}
+	if orders.Location == nil || orders.Location.Line != 7 {
+		t.Fatalf("expected ListOrders finding at its annotation line, got %#v", orders.Location)
+	}
+	refunds, ok := byQuery["ListRefunds"]
+	if !ok || refunds.Approval != nil {
+		t.Fatalf("expected unapproved ListRefunds finding, got %#v", report.Findings)
+	}
+	if !HasFindingsAtOrAbove(report, query.RiskHigh) {
+		t.Fatalf("expected unapproved ListRefunds to fail the high threshold")
+	}
+	if HasFindingsAtOrAbove(ReviewReport{Findings: []Finding{orders}}, query.RiskHigh) {
+		t.Fatalf("approved findings should not fail the high threshold")
+	}
+
+	approvalsPath := filepath.Join(dir, query.DefaultApprovalsFile)
+	if err := os.WriteFile(approvalsPath, []byte(`{"allow_inline": true, "approvals": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report, err = Run(Options{Paths: []string{sqlPath}, ApprovalsPath: approvalsPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finding, ok := findingByQuery(report, "ListOrders"); !ok || finding.Approval == nil {
+		t.Fatalf("expected allow_inline to honour the inline approval, got %#v", report.Findings)
+	}
+}
+
+func findingByQuery(report ReviewReport, name string) (Finding, bool) {
+	for _, finding := range report.Findings {
+		if finding.Query == name && finding.Code == query.WarningRawSQLUsed {
+			return finding, true
+		}
+	}
+	return Finding{}, false
+}
+
+func TestRunFallsBackWhenSQLFileIsNotCatalog(t *testing.T) {
+	dir := t.TempDir()
+	migrationPath := filepath.Join(dir, "0001_drop.sql")
+	if err := os.WriteFile(migrationPath, []byte("-- name: drop legacy sessions\nDROP TABLE sessions;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	scriptPath := filepath.Join(dir, "script.sql")
+	if err := os.WriteFile(scriptPath, []byte("DELETE FROM sessions;\n-- name: ListUsers :many\nSELECT id FROM users;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{Paths: []string{dir}})
+	if err != nil {
+		t.Fatalf("non-catalog SQL files must not fail catalog parsing: %v", err)
+	}
+	files := map[string]bool{}
+	for _, finding := range report.Findings {
+		if finding.Query != "" {
+			t.Fatalf("non-catalog finding tagged with a catalog query: %#v", finding)
+		}
+		if finding.Location != nil {
+			files[filepath.Base(finding.Location.File)] = true
+		}
+	}
+	if !files["0001_drop.sql"] || !files["script.sql"] {
+		t.Fatalf("expected migration and raw findings for both files, got %#v", report.Findings)
+	}
+}
+
+func TestRunTagsFindingsWithPlanFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	sqlPath := filepath.Join(dir, "cleanup.sql")
+	if err := os.WriteFile(sqlPath, []byte("DELETE FROM sessions WHERE expires_at < '2024-01-01'"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	plan := query.NewRawPlan("delete from `sessions` where expires_at < ?", "2025-01-01")
+	plan.Fingerprint = ""
+	planJSON, err := plan.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plan.json"), planJSON, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{Paths: []string{dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query.NewRawPlan("DELETE FROM sessions WHERE expires_at < '2024-01-01'").Fingerprint
+	var tagged int
+	for _, finding := range report.Findings {
+		if finding.Code != query.WarningRawSQLUsed {
+			continue
+		}
+		if finding.Fingerprint != want {
+			t.Fatalf("finding %s fingerprint = %q, want %q", finding.Location.File, finding.Fingerprint, want)
+		}
+		tagged++
+	}
+	if tagged != 2 {
+		t.Fatalf("expected raw SQL findings from both files, got %#v", report.Findings)
+	}
+}
+
+func TestRunTagsBuilderChainFindingsWithRuntimeFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	goPath := filepath.Join(dir, "repo.go")
+	src := `package sample
+
+func run(db any, ids []int) {
+	var rows []map[string]any
+	db.Table("users").Select("id", "name").Where("status", "=", "active").WhereIn("id", ids).GetMaps(&rows)
+	db.Table("sessions").Where("expires_at", "<", -1).Delete()
+}
+`
+	if err := os.WriteFile(goPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{Paths: []string{goPath}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	selectPlan, err := query.New(nil, "users", driver.PostgresDialect{}).
+		Select("id", "name").Where("status", "=", "inactive").WhereIn("id", []int{4, 5, 6}).
+		Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	deletePlan, err := query.New(nil, "sessions", driver.PostgresDialect{}).Where("expires_at", "<", 0).PlanDelete(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit, ok := findFinding(report.Findings, query.WarningLimitMissing)
+	if !ok || limit.Fingerprint != selectPlan.Fingerprint {
+		t.Fatalf("expected %s tagged %q, got %#v", query.WarningLimitMissing, selectPlan.Fingerprint, report.Findings)
+	}
+	bulk, ok := findFinding(report.Findings, query.WarningBulkDeleteDetected)
+	if !ok || bulk.Fingerprint != deletePlan.Fingerprint {
+		t.Fatalf("expected %s tagged %q, got %#v", query.WarningBulkDeleteDetected, deletePlan.Fingerprint, report.Findings)
+	}
+}
+
+func TestRunReportsStaleApprovalsOnlyWhenEveryQueryIsFingerprinted(t *testing.T) {
+	dir := t.TempDir()
+	goPath := filepath.Join(dir, "repo.go")
+	src := `package sample
+
+func run(db any) {
+	db.Table("sessions").Where("expires_at", "<", 0).Delete()
+}
+`
+	if err := os.WriteFile(goPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := query.New(nil, "sessions", driver.MySQLDialect{}).Where("expires_at", "<", 0).PlanDelete(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	approvals := filepath.Join(t.TempDir(), "goquent.approvals.json")
+	body := `{"approvals": [
+  {"fingerprint": "` + plan.Fingerprint + `", "reason": "nightly cleanup"},
+  {"fingerprint": "sha256:gone", "reason": "removed report"}
+]}`
+	if err := os.WriteFile(approvals, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{Paths: []string{dir}, ApprovalsPath: approvals})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stale []string
+	for _, finding := range report.Findings {
+		if finding.Code == query.WarningApprovalStale {
+			stale = append(stale, finding.Fingerprint)
+		}
+	}
+	if len(stale) != 1 || stale[0] != "sha256:gone" {
+		t.Fatalf("expected only sha256:gone to be stale, got %v", stale)
+	}
+
+	src = strings.Replace(src, "}\n", "\tdb.Model(&User{}).Where(\"id\", 1).Delete()\n}\n", 1)
+	if err := os.WriteFile(goPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report, err = Run(Options{Paths: []string{dir}, ApprovalsPath: approvals})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasFinding(report.Findings, query.WarningApprovalStale) {
+		t.Fatalf("expected no stale approvals while a Model chain is not fingerprinted, got %#v", report.Findings)
+	}
+}
+
+func TestCollectPlansExportsLiteralBuilderChains(t *testing.T) {
+	dir := t.TempDir()
+	goPath := filepath.Join(dir, "repo.go")
+	src := `package sample
+
+func run(db any, col string) {
+	var rows []map[string]any
+	db.Table("users").Select("id").Where("status", "active").Limit(10).GetMaps(&rows)
+	db.Table("users").Where(col, 1).Delete()
+	db.Model(&User{}).Where("id", 1).Delete()
+}
+`
+	if err := os.WriteFile(goPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := CollectPlans([]string{dir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := query.New(nil, "users", driver.MySQLDialect{}).Select("id").Where("status", "active").Limit(10).Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the literal chain to be exported, got %#v", entries)
+	}
+	if entries[0].Fingerprint != plan.Fingerprint || entries[0].Source != goPath+":5" {
+		t.Fatalf("unexpected entry %#v, want fingerprint %s", entries[0], plan.Fingerprint)
+	}
+}
+
+func TestRunAppliesRegisteredRiskRules(t *testing.T) {
+	if err := query.RegisterRiskRule(query.RiskRule{
+		Code: "ORDER_BY_RAND",
+		Check: func(plan *query.QueryPlan) []query.Warning {
+			if strings.Contains(strings.ToUpper(plan.SQL), "ORDER BY RAND()") {
+				return []query.Warning{{Message: "ORDER BY RAND() sorts the whole table", Suppressible: true}}
+			}
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pick.sql"), []byte("SELECT id FROM prizes ORDER BY RAND() LIMIT 1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := `package sample
+
+func pick(db any) {
+	var rows []map[string]any
+	db.Table("prizes").Select("id").OrderByRaw("RAND()").Limit(1).GetMaps(&rows)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "pick.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{Paths: []string{dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]bool)
+	for _, finding := range report.Findings {
+		if finding.Code == "ORDER_BY_RAND" {
+			if finding.Level != query.RiskMedium {
+				t.Fatalf("unexpected level %s", finding.Level)
+			}
+			files[filepath.Ext(finding.Location.File)] = true
+		}
+	}
+	if !files[".sql"] || !files[".go"] {
+		t.Fatalf("expected ORDER_BY_RAND findings for the SQL file and the builder chain, got %#v", report.Findings)
+	}
+}
+
+func TestRunAppliesConfig(t *testing.T) {
+	query.ResetPolicyRegistry()
+	t.Cleanup(query.ResetPolicyRegistry)
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"admin/export.sql":     "SELECT id, email FROM users",
+		"reports/daily.sql":    "SELECT id FROM users WHERE 1=1",
+		"generated/models.sql": "DELETE FROM users",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfgPath := filepath.Join(dir, config.DefaultFile)
+	if err := os.WriteFile(cfgPath, []byte(`
+environments:
+  ci:
+    rules:
+      WEAK_PREDICATE:
+        enabled: false
+      RAW_SQL_USED:
+        severity: medium
+review:
+  exclude: ["generated/**"]
+suppressions:
+  - code: RAW_SQL_USED
+    path: admin/**
+    reason: admin export is reviewed by the platform team
+    owner: platform
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{Paths: []string{dir}, Config: cfg, Environment: "ci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type key struct{ file, code string }
+	got := map[key]query.RiskLevel{}
+	for _, finding := range report.Findings {
+		rel, _ := filepath.Rel(dir, finding.Location.File)
+		got[key{filepath.ToSlash(rel), finding.Code}] = finding.Level
+	}
+	for k := range got {
+		if k.file == "generated/models.sql" {
+			t.Fatalf("expected excluded file to be skipped: %#v", got)
+		}
+	}
+	if _, ok := got[key{"reports/daily.sql", query.WarningWeakPredicate}]; ok {
+		t.Fatalf("expected WEAK_PREDICATE disabled: %#v", got)
+	}
+	if got[key{"reports/daily.sql", query.WarningRawSQLUsed}] != query.RiskMedium {
+		t.Fatalf("expected RAW_SQL_USED re-leveled to medium: %#v", got)
+	}
+	if _, ok := got[key{"admin/export.sql", query.WarningRawSQLUsed}]; ok {
+		t.Fatalf("expected config suppression for admin/: %#v", got)
+	}
+	if len(report.SuppressedFindings) != 1 || report.SuppressedFindings[0].Suppression.Scope != query.SuppressionScopeConfig {
+		t.Fatalf("unexpected suppressed findings: %#v", report.SuppressedFindings)
+	}
+}
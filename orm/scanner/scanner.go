@@ -7,11 +7,19 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/internal/codec"
+	"github.com/faciam-dev/goquent/orm/internal/pgarray"
 	"github.com/faciam-dev/goquent/orm/internal/stringutil"
 )
 
 // Struct scans current row into dest struct using column mapping.
 func Struct(dest any, rows *sql.Rows) error {
+	return StructDialect(nil, dest, rows)
+}
+
+// StructDialect is like Struct but selects codec overrides registered for d.
+func StructDialect(d driver.Dialect, dest any, rows *sql.Rows) error {
 	cols, err := rows.Columns()
 	if err != nil {
 		return err
@@ -33,11 +41,17 @@ func Struct(dest any, rows *sql.Rows) error {
 	}
 	scannerType := reflect.TypeOf((*sql.Scanner)(nil)).Elem()
 	for i, col := range cols {
-		f := fieldByColumn(v, col)
+		f, codecName := fieldByColumn(v, col)
 		if !f.IsValid() || !f.CanSet() {
 			continue
 		}
 		val := reflect.ValueOf(fields[i]).Elem().Interface()
+		if handled, err := decodeCodec(d, f, codecName, val); handled {
+			if err != nil {
+				return fmt.Errorf("scan %s: %w", col, err)
+			}
+			continue
+		}
 
 		// handle specialized bool types first
 		switch f.Type() {
@@ -79,6 +93,10 @@ func Struct(dest any, rows *sql.Rows) error {
 				return fmt.Errorf("scan %s: %w", col, err)
 			}
 			f.Set(inst.Elem())
+		} else if handled, err := pgarray.Scan(f, val); handled {
+			if err != nil {
+				return fmt.Errorf("scan %s: %w", col, err)
+			}
 		} else {
 			fv := reflect.ValueOf(val)
 			if fv.Type().ConvertibleTo(f.Type()) {
@@ -151,6 +169,11 @@ func Maps(rows *sql.Rows) ([]map[string]any, error) {
 // Structs scans all remaining rows into the slice pointed to by dest.
 // dest must be a pointer to a slice of structs.
 func Structs(dest any, rows *sql.Rows) error {
+	return StructsDialect(nil, dest, rows)
+}
+
+// StructsDialect is like Structs but selects codec overrides registered for d.
+func StructsDialect(d driver.Dialect, dest any, rows *sql.Rows) error {
 	cols, err := rows.Columns()
 	if err != nil {
 		return err
@@ -175,11 +198,17 @@ func Structs(dest any, rows *sql.Rows) error {
 		}
 		elem := reflect.New(elemType).Elem()
 		for i, col := range cols {
-			f := fieldByColumn(elem, col)
+			f, codecName := fieldByColumn(elem, col)
 			if !f.IsValid() || !f.CanSet() {
 				continue
 			}
 			val := reflect.ValueOf(fields[i]).Elem().Interface()
+			if handled, err := decodeCodec(d, f, codecName, val); handled {
+				if err != nil {
+					return fmt.Errorf("scan %s: %w", col, err)
+				}
+				continue
+			}
 
 			switch f.Type() {
 			case reflect.TypeOf(true):
@@ -220,6 +249,10 @@ func Structs(dest any, rows *sql.Rows) error {
 					return fmt.Errorf("scan %s: %w", col, err)
 				}
 				f.Set(inst.Elem())
+			} else if handled, err := pgarray.Scan(f, val); handled {
+				if err != nil {
+					return fmt.Errorf("scan %s: %w", col, err)
+				}
 			} else {
 				fv := reflect.ValueOf(val)
 				if fv.Type().ConvertibleTo(f.Type()) {
@@ -234,14 +267,15 @@ func Structs(dest any, rows *sql.Rows) error {
 	return rows.Err()
 }
 
-func fieldByColumn(v reflect.Value, col string) reflect.Value {
+func fieldByColumn(v reflect.Value, col string) (reflect.Value, string) {
 	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
 		sf := t.Field(i)
 		if sf.PkgPath != "" {
 			continue
 		}
-		name := sf.Tag.Get("db")
+		dbTag := sf.Tag.Get("db")
+		name, _, _ := strings.Cut(dbTag, ",")
 		if name == "" || name == "-" {
 			if tag := sf.Tag.Get("orm"); tag != "" {
 				name = parseTag(tag)
@@ -251,10 +285,18 @@ func fieldByColumn(v reflect.Value, col string) reflect.Value {
 			name = stringutil.ToSnake(sf.Name)
 		}
 		if name == col {
-			return v.Field(i)
+			return v.Field(i), codec.TagName(dbTag)
 		}
 	}
-	return reflect.Value{}
+	return reflect.Value{}, ""
+}
+
+func decodeCodec(d driver.Dialect, f reflect.Value, name string, src any) (bool, error) {
+	c, err := codec.Lookup(f.Type(), name, d)
+	if err != nil || c == nil {
+		return err != nil, err
+	}
+	return codec.DecodeInto(c, f, src)
 }
 
 func parseTag(tag string) string {
@@ -69,6 +69,14 @@ func CursorBefore(columns []CursorColumn, values ...any) Scope {
 	}
 }
 
+// Filters adds WHERE predicates for the set fields of a filter struct. See
+// query.Query.Filter for the `filter:"column,op=..."` tag format.
+func Filters(filter any) Scope {
+	return func(q *query.Query) *query.Query {
+		return q.Filter(filter)
+	}
+}
+
 func scopedQuery(base *query.Query, scopes ...Scope) (*query.Query, error) {
 	if base == nil {
 		return nil, fmt.Errorf("base query is nil")
@@ -90,10 +98,10 @@ func SelectOneBy[T any](ctx context.Context, db *DB, base *query.Query, scopes .
 	if err != nil {
 		return zero, err
 	}
-	if err := query.EnsurePlanExecutable(plan); err != nil {
+	if err := db.ensureExecutable(plan); err != nil {
 		return zero, err
 	}
-	return SelectOne[T](ctx, db.RequireRawApproval("goquent generated scoped query"), plan.SQL, plan.Params...)
+	return selectOnePlan[T](ctx, db, plan)
 }
 
 // SelectAllBy builds a scoped query and scans all rows into []T.
@@ -109,10 +117,10 @@ func SelectAllBy[T any](ctx context.Context, db *DB, base *query.Query, scopes .
 	if err != nil {
 		return nil, err
 	}
-	if err := query.EnsurePlanExecutable(plan); err != nil {
+	if err := db.ensureExecutable(plan); err != nil {
 		return nil, err
 	}
-	return SelectAll[T](ctx, db.RequireRawApproval("goquent generated scoped query"), plan.SQL, plan.Params...)
+	return selectAllPlan[T](ctx, db, plan)
 }
 
 // UpdateBy applies scopes to base and executes an UPDATE using the resulting query.
@@ -138,7 +146,7 @@ func UpdateByReturning[T any](ctx context.Context, db *DB, base *query.Query, da
 	if err != nil {
 		return zero, err
 	}
-	if err := query.EnsurePlanExecutable(plan); err != nil {
+	if err := db.ensureExecutable(plan); err != nil {
 		return zero, err
 	}
 	cols, err := returningColumnsForQuery[T]()
@@ -149,7 +157,9 @@ func UpdateByReturning[T any](ctx context.Context, db *DB, base *query.Query, da
 	if err != nil {
 		return zero, err
 	}
-	return queryReturningOne[T](ctx, db, sqlStr, plan.Params...)
+	plan.SQL = sqlStr
+	plan.Fingerprint = query.PlanFingerprint(plan)
+	return queryReturningOne[T](ctx, db, plan)
 }
 
 // DeleteBy applies scopes to base and executes a DELETE using the resulting query.
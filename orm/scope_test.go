@@ -272,3 +272,57 @@ func TestScopedHelpersRejectNilBase(t *testing.T) {
 		t.Fatalf("expected nil base error for delete")
 	}
 }
+
+type userSearch struct {
+	Status *string  `filter:"status,op=="`
+	MinAge *int     `filter:"age,op=>="`
+	Name   *string  `filter:"name,op=LIKE"`
+	Roles  []string `filter:"role,op=in"`
+	Page   int
+}
+
+func TestFiltersScopeSkipsUnsetFields(t *testing.T) {
+	db, _ := newScopeMockDB(t, driver.MySQLDialect{})
+
+	status, age := "active", 18
+	q := ApplyScopes(db.Table("users"), Filters(userSearch{Status: &status, MinAge: &age, Roles: []string{"admin", "ops"}, Page: 2}))
+	sqlStr, args, err := q.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if !strings.Contains(sqlStr, "WHERE `status` = ? AND `age` >= ? AND `role` IN (?, ?)") || strings.Contains(sqlStr, "name") {
+		t.Fatalf("unexpected filter SQL: %s", sqlStr)
+	}
+	if len(args) != 4 || args[0] != "active" || args[1] != 18 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	sqlStr, args, err = ApplyScopes(db.Table("users"), Filters(&userSearch{})).Build()
+	if err != nil || strings.Contains(sqlStr, "WHERE") || len(args) != 0 {
+		t.Fatalf("empty filter must not add predicates: %s %#v err=%v", sqlStr, args, err)
+	}
+}
+
+func TestFiltersRejectInvalidTags(t *testing.T) {
+	db, _ := newScopeMockDB(t, driver.MySQLDialect{})
+	v := "x"
+	for _, filter := range []any{
+		struct {
+			Name *string `filter:"name,op=; DROP"`
+		}{Name: &v},
+		struct {
+			Name *string `filter:"name,cmp=="`
+		}{Name: &v},
+		struct {
+			Name *string `filter:"name,op=in"`
+		}{Name: &v},
+		struct {
+			Tags []string `filter:"tags"`
+		}{Tags: []string{"a"}},
+		"not a struct",
+	} {
+		if _, _, err := ApplyScopes(db.Table("users"), Filters(filter)).Build(); err == nil {
+			t.Fatalf("expected error for filter %#v", filter)
+		}
+	}
+}
@@ -10,12 +10,11 @@ import (
 // SelectOne runs the query and scans the first row into T.
 func SelectOne[T any](ctx context.Context, db *DB, q string, args ...any) (T, error) {
 	var zero T
-	rows, err := db.QueryContext(ctx, q, args...)
+	plan, err := db.ensureRawExecutable(ctx, q, args...)
 	if err != nil {
 		return zero, err
 	}
-	defer rows.Close()
-	return scanRowsOne[T](db, rows)
+	return selectOnePlan[T](ctx, db, plan)
 }
 
 func scanRowsOne[T any](db *DB, rows *sql.Rows) (T, error) {
@@ -92,6 +91,12 @@ func scanRowsOne[T any](db *DB, rows *sql.Rows) (T, error) {
 			if !f.CanSet() {
 				continue
 			}
+			if handled, err := fm.decodeCodec(db.drv.Dialect, f, val); handled {
+				if err != nil {
+					return zero, fmt.Errorf("scan %s: %w", fm.Col, err)
+				}
+				continue
+			}
 			if fm.Decoder != nil {
 				pol := db.scanOpts.BoolPolicy
 				if fm.BoolPolicy != nil {
@@ -134,12 +139,14 @@ func scanRowsOne[T any](db *DB, rows *sql.Rows) (T, error) {
 
 // SelectAll runs the query and scans all rows into []T.
 func SelectAll[T any](ctx context.Context, db *DB, q string, args ...any) ([]T, error) {
-	rows, err := db.QueryContext(ctx, q, args...)
+	plan, err := db.ensureRawExecutable(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return selectAllPlan[T](ctx, db, plan)
+}
 
+func scanRowsAll[T any](db *DB, rows *sql.Rows) ([]T, error) {
 	var t T
 	typ := reflect.TypeOf(t)
 	switch {
@@ -206,6 +213,12 @@ func SelectAll[T any](ctx context.Context, db *DB, q string, args ...any) ([]T,
 				if !f.CanSet() {
 					continue
 				}
+				if handled, err := fm.decodeCodec(db.drv.Dialect, f, val); handled {
+					if err != nil {
+						return nil, fmt.Errorf("scan %s: %w", fm.Col, err)
+					}
+					continue
+				}
 				if fm.Decoder != nil {
 					pol := db.scanOpts.BoolPolicy
 					if fm.BoolPolicy != nil {
@@ -3,6 +3,7 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"reflect"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -17,13 +18,20 @@ type boolRow struct {
 }
 
 func newMockDB(t *testing.T, p BoolScanPolicy) (*DB, sqlmock.Sqlmock) {
-	db, mock, err := sqlmock.New()
+	ormDB, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithBoolScanPolicy(p))
+	return ormDB.RequireRawApproval("raw bool scan test"), mock
+}
+
+// newSQLMockDB returns a DB for dialect d backed by sqlmock, closed when the
+// test ends.
+func newSQLMockDB(t *testing.T, d driver.Dialect, opts ...Option) (*DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("sqlmock: %v", err)
 	}
-	t.Cleanup(func() { db.Close() })
-	ormDB := NewDB(db, driver.MySQLDialect{}, WithBoolScanPolicy(p))
-	return ormDB.RequireRawApproval("raw bool scan test"), mock
+	t.Cleanup(func() { sqlDB.Close() })
+	return NewDB(sqlDB, d, opts...), mock
 }
 
 func TestSelectBoolPolicies(t *testing.T) {
@@ -76,3 +84,29 @@ func TestSelectBoolNilHandling(t *testing.T) {
 		t.Fatalf("expected error for nil bool")
 	}
 }
+
+type arrayRow struct {
+	Tags   []string `db:"tags"`
+	Scores []int64  `db:"scores"`
+}
+
+func TestSelectPostgresArrays(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMockDB(t, BoolCompat)
+
+	rows := sqlmock.NewRows([]string{"tags", "scores"}).AddRow([]byte(`{a,"b c","d\"e"}`), "{1,-2,3}")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	r, err := SelectOne[arrayRow](ctx, db, "SELECT tags, scores FROM t")
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if !reflect.DeepEqual(r.Tags, []string{"a", "b c", `d"e`}) || !reflect.DeepEqual(r.Scores, []int64{1, -2, 3}) {
+		t.Fatalf("unexpected arrays: %+v", r)
+	}
+
+	rows = sqlmock.NewRows([]string{"tags", "scores"}).AddRow("{}", "{1,x}")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	if _, err := SelectOne[arrayRow](ctx, db, "SELECT tags, scores FROM t"); err == nil {
+		t.Fatalf("expected error for invalid integer element")
+	}
+}
@@ -0,0 +1,67 @@
+package orm
+
+import (
+	"context"
+	"maps"
+
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// FingerprintTag is the sqlcommenter key holding the plan fingerprint.
+const FingerprintTag = "goquent_fp"
+
+type queryTagsKey struct{}
+
+// ContextWithQueryTags returns a context carrying sqlcommenter tags, such as
+// route or action, merged over the tags already on ctx.
+func ContextWithQueryTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := maps.Clone(QueryTagsFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(tags))
+	}
+	maps.Copy(merged, tags)
+	return context.WithValue(ctx, queryTagsKey{}, merged)
+}
+
+// QueryTagsFromContext returns the tags set with ContextWithQueryTags.
+func QueryTagsFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	tags, _ := ctx.Value(queryTagsKey{}).(map[string]string)
+	return tags
+}
+
+// WithSQLCommenter appends a sqlcommenter comment to every executed
+// statement, combining tags, the tags from ContextWithQueryTags and the plan
+// fingerprint under FingerprintTag:
+//
+//	SELECT ... /*app='billing',goquent_fp='sha256%3A...',route='%2Finvoices'*/
+//
+// Context tags override tags of the same key. The comment is added after
+// interceptors ran and never appears in QueryPlan.SQL, fingerprints or review
+// output. Statements that already contain a comment are sent unchanged.
+// With WithStatementCache, statements whose comment includes context tags
+// are executed without the cache because their SQL text changes per request.
+func WithSQLCommenter(tags map[string]string) Option {
+	static := maps.Clone(tags)
+	return func(db *DB) {
+		db.commenter = func(ctx context.Context, plan *QueryPlan) string {
+			merged := make(map[string]string, len(static)+2)
+			maps.Copy(merged, static)
+			maps.Copy(merged, QueryTagsFromContext(ctx))
+			if plan.Fingerprint != "" {
+				merged[FingerprintTag] = plan.Fingerprint
+			}
+			return query.FormatSQLComment(merged)
+		}
+	}
+}
+
+// executedSQL returns the SQL sent to the database for plan.
+func (db *DB) executedSQL(ctx context.Context, plan *QueryPlan) string {
+	if db.commenter == nil {
+		return plan.SQL
+	}
+	return query.AppendSQLComment(plan.SQL, db.commenter(ctx, plan))
+}
@@ -0,0 +1,56 @@
+package orm
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/driver"
+)
+
+func TestWithSQLCommenterTagsExecutedSQLOnly(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer sqlDB.Close()
+	var planSQL []string
+	db := NewDB(sqlDB, driver.MySQLDialect{},
+		WithInterceptors(func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+			planSQL = append(planSQL, plan.SQL)
+			return next(ctx, plan)
+		}),
+		WithSQLCommenter(map[string]string{"app": "billing", "route": "default"}))
+
+	selectSQL := "SELECT `id` FROM `users` WHERE `id` = ? LIMIT 1"
+	plan, err := db.Table("users").Select("id").Where("id", 1).Limit(1).Plan(context.Background())
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.SQL != selectSQL {
+		t.Fatalf("plan SQL = %s", plan.SQL)
+	}
+	comment := "/*app='billing',goquent_fp='" + strings.ReplaceAll(plan.Fingerprint, ":", "%3A") + "',route='%2Finvoices'*/"
+	mock.ExpectQuery(regexp.QuoteMeta(selectSQL + " " + comment)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM sessions /*app='billing',goquent_fp=")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := ContextWithQueryTags(context.Background(), map[string]string{"route": "/invoices"})
+	var row map[string]any
+	if err := db.Table("users").WithContext(ctx).Select("id").Where("id", 1).Limit(1).FirstMap(&row); err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	if _, err := db.RequireRawApproval("cleanup").ExecContext(context.Background(), "DELETE FROM sessions"); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if len(planSQL) != 2 || planSQL[0] != selectSQL || planSQL[1] != "DELETE FROM sessions" {
+		t.Fatalf("interceptors saw commented SQL: %#v", planSQL)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,309 @@
+package orm
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// preparer is an executor that can prepare statements, such as *sql.DB.
+type preparer interface {
+	executor
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// StatementCacheStats reports prepared statement cache activity.
+type StatementCacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+}
+
+// WithStatementCache prepares statements on first use and reuses them for
+// later executions of the same final SQL, keeping at most size statements and
+// evicting the least recently used. Transactions reuse the cached statements
+// through Tx.StmtContext. Statements that fail with a connection error or
+// need re-preparing after a schema change are dropped and prepared again.
+// Statements with a WithSQLCommenter comment that includes per-request tags
+// from ContextWithQueryTags bypass the cache and run unprepared; comments
+// built only from static tags and the fingerprint are cached.
+// The option is ignored when size is not positive or the DB does not wrap a
+// *sql.DB.
+func WithStatementCache(size int) Option {
+	return func(db *DB) {
+		p, ok := db.exec.(preparer)
+		if !ok || size <= 0 {
+			return
+		}
+		db.stmts = newStmtCache(p, size)
+		db.exec = db.stmts
+	}
+}
+
+// StatementCacheStats returns hit, miss and eviction counters of the
+// statement cache. It returns zero stats when WithStatementCache is not set.
+func (db *DB) StatementCacheStats() StatementCacheStats {
+	if db.stmts == nil {
+		return StatementCacheStats{}
+	}
+	return db.stmts.stats()
+}
+
+type stmtCache struct {
+	base preparer
+	size int
+
+	mu    sync.Mutex
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+type stmtCacheEntry struct {
+	sql  string
+	stmt *sql.Stmt
+}
+
+func newStmtCache(base preparer, size int) *stmtCache {
+	return &stmtCache{base: base, size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *stmtCache) stats() StatementCacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+	return StatementCacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+	}
+}
+
+// errUncachedStatement makes callers run a statement unprepared.
+var errUncachedStatement = errors.New("goquent: statement is not cached")
+
+// prepared returns the cached statement for q, preparing it on a miss.
+// Statements carrying a sqlcommenter comment with per-request tags from
+// ContextWithQueryTags are not cached, since their text differs per request.
+func (c *stmtCache) prepared(ctx context.Context, q string) (*sql.Stmt, error) {
+	if len(QueryTagsFromContext(ctx)) > 0 && strings.HasSuffix(q, "*/") {
+		return nil, errUncachedStatement
+	}
+	c.mu.Lock()
+	if el, ok := c.items[q]; ok {
+		c.order.MoveToFront(el)
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return el.Value.(*stmtCacheEntry).stmt, nil
+	}
+	c.mu.Unlock()
+
+	c.misses.Add(1)
+	stmt, err := c.base.PrepareContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[q]; ok {
+		// Another goroutine prepared the same SQL first.
+		stmt.Close()
+		c.order.MoveToFront(el)
+		return el.Value.(*stmtCacheEntry).stmt, nil
+	}
+	c.items[q] = c.order.PushFront(&stmtCacheEntry{sql: q, stmt: stmt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.removeLocked(oldest)
+		c.evictions.Add(1)
+	}
+	return stmt, nil
+}
+
+// forget drops stmt from the cache if it is still cached for q.
+func (c *stmtCache) forget(q string, stmt *sql.Stmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[q]; ok && el.Value.(*stmtCacheEntry).stmt == stmt {
+		c.removeLocked(el)
+	}
+}
+
+func (c *stmtCache) removeLocked(el *list.Element) {
+	entry := c.order.Remove(el).(*stmtCacheEntry)
+	delete(c.items, entry.sql)
+	// database/sql defers closing the driver statement until open rows
+	// using it are closed.
+	entry.stmt.Close()
+}
+
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.order.Len() > 0 {
+		c.removeLocked(c.order.Front())
+	}
+}
+
+// InTransaction reports that statements run outside a transaction.
+func (c *stmtCache) InTransaction() bool { return false }
+
+func (c *stmtCache) Query(q string, args ...any) (*sql.Rows, error) {
+	return c.QueryContext(context.Background(), q, args...)
+}
+
+func (c *stmtCache) QueryContext(ctx context.Context, q string, args ...any) (*sql.Rows, error) {
+	for retried := false; ; retried = true {
+		stmt, err := c.prepared(ctx, q)
+		if err != nil {
+			// Statements the driver cannot prepare still run unprepared.
+			return c.base.QueryContext(ctx, q, args...)
+		}
+		rows, err := stmt.QueryContext(ctx, args...)
+		if err != nil && !retried && stalePreparedStmt(err) {
+			c.forget(q, stmt)
+			continue
+		}
+		return rows, err
+	}
+}
+
+func (c *stmtCache) QueryRow(q string, args ...any) *sql.Row {
+	return c.QueryRowContext(context.Background(), q, args...)
+}
+
+func (c *stmtCache) QueryRowContext(ctx context.Context, q string, args ...any) *sql.Row {
+	for retried := false; ; retried = true {
+		stmt, err := c.prepared(ctx, q)
+		if err != nil {
+			return c.base.QueryRowContext(ctx, q, args...)
+		}
+		row := stmt.QueryRowContext(ctx, args...)
+		if err := row.Err(); err != nil && !retried && stalePreparedStmt(err) {
+			c.forget(q, stmt)
+			continue
+		}
+		return row
+	}
+}
+
+func (c *stmtCache) Exec(q string, args ...any) (sql.Result, error) {
+	return c.ExecContext(context.Background(), q, args...)
+}
+
+func (c *stmtCache) ExecContext(ctx context.Context, q string, args ...any) (sql.Result, error) {
+	for retried := false; ; retried = true {
+		stmt, err := c.prepared(ctx, q)
+		if err != nil {
+			return c.base.ExecContext(ctx, q, args...)
+		}
+		res, err := stmt.ExecContext(ctx, args...)
+		if err != nil && !retried && stalePreparedStmt(err) {
+			c.forget(q, stmt)
+			continue
+		}
+		return res, err
+	}
+}
+
+// tx returns an executor that runs cached statements inside tx.
+func (c *stmtCache) tx(tx *sql.Tx) executor {
+	return &stmtCacheTx{cache: c, tx: tx}
+}
+
+// stmtCacheTx binds cached statements to a transaction with Tx.StmtContext.
+// The transaction-specific statements are closed by database/sql when the
+// transaction ends. A statement found stale, e.g. closed by an eviction while
+// the transaction used it, is dropped from the cache and the SQL runs again
+// unprepared on the transaction's connection.
+type stmtCacheTx struct {
+	cache *stmtCache
+	tx    *sql.Tx
+}
+
+// stmt returns the cached statement for q and its transaction-bound copy.
+func (t *stmtCacheTx) stmt(ctx context.Context, q string) (*sql.Stmt, *sql.Stmt, error) {
+	parent, err := t.cache.prepared(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parent, t.tx.StmtContext(ctx, parent), nil
+}
+
+// InTransaction reports that statements run inside a transaction.
+func (t *stmtCacheTx) InTransaction() bool { return true }
+
+func (t *stmtCacheTx) Query(q string, args ...any) (*sql.Rows, error) {
+	return t.QueryContext(context.Background(), q, args...)
+}
+
+func (t *stmtCacheTx) QueryContext(ctx context.Context, q string, args ...any) (*sql.Rows, error) {
+	parent, stmt, err := t.stmt(ctx, q)
+	if err != nil {
+		return t.tx.QueryContext(ctx, q, args...)
+	}
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil && stalePreparedStmt(err) {
+		t.cache.forget(q, parent)
+		return t.tx.QueryContext(ctx, q, args...)
+	}
+	return rows, err
+}
+
+func (t *stmtCacheTx) QueryRow(q string, args ...any) *sql.Row {
+	return t.QueryRowContext(context.Background(), q, args...)
+}
+
+func (t *stmtCacheTx) QueryRowContext(ctx context.Context, q string, args ...any) *sql.Row {
+	parent, stmt, err := t.stmt(ctx, q)
+	if err != nil {
+		return t.tx.QueryRowContext(ctx, q, args...)
+	}
+	row := stmt.QueryRowContext(ctx, args...)
+	if err := row.Err(); err != nil && stalePreparedStmt(err) {
+		t.cache.forget(q, parent)
+		return t.tx.QueryRowContext(ctx, q, args...)
+	}
+	return row
+}
+
+func (t *stmtCacheTx) Exec(q string, args ...any) (sql.Result, error) {
+	return t.ExecContext(context.Background(), q, args...)
+}
+
+func (t *stmtCacheTx) ExecContext(ctx context.Context, q string, args ...any) (sql.Result, error) {
+	parent, stmt, err := t.stmt(ctx, q)
+	if err != nil {
+		return t.tx.ExecContext(ctx, q, args...)
+	}
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil && stalePreparedStmt(err) {
+		t.cache.forget(q, parent)
+		return t.tx.ExecContext(ctx, q, args...)
+	}
+	return res, err
+}
+
+// stalePreparedStmt reports whether err means a prepared statement can no
+// longer be used and must be prepared again.
+func stalePreparedStmt(err error) bool {
+	if errors.Is(err, sqldriver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "statement is closed") ||
+		strings.Contains(msg, "needs to be re-prepared") || // MySQL 1615
+		strings.Contains(msg, "cached plan must not change result type") || // PostgreSQL
+		strings.Contains(msg, "prepared statement") && strings.Contains(msg, "does not exist")
+}
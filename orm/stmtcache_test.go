@@ -0,0 +1,170 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/driver"
+)
+
+func TestStatementCacheReusesPreparedStatements(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithStatementCache(1))
+	ctx := context.Background()
+
+	users := mock.ExpectPrepare("SELECT `id` FROM `users` WHERE `id` = ?").WillBeClosed()
+	users.ExpectQuery().WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	users.ExpectQuery().WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(2)))
+	mock.ExpectPrepare("SELECT `id` FROM `posts` WHERE `id` = ?").
+		ExpectQuery().WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+
+	for _, q := range []struct {
+		table string
+		id    int
+	}{{"users", 1}, {"users", 2}, {"posts", 3}} {
+		var row map[string]any
+		if err := db.Table(q.table).Select("id").Where("id", q.id).WithContext(ctx).FirstMap(&row); err != nil {
+			t.Fatalf("%s %d: %v", q.table, q.id, err)
+		}
+	}
+
+	stats := db.StatementCacheStats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Evictions != 1 || stats.Size != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatementCacheReprepareAfterStaleStatement(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithStatementCache(8))
+	db = db.RequireRawApproval("statement cache test")
+
+	mock.ExpectPrepare("UPDATE users SET name = \\? WHERE id = \\?").
+		ExpectExec().WithArgs("a", 1).
+		WillReturnError(errors.New("Error 1615: Prepared statement needs to be re-prepared"))
+	mock.ExpectPrepare("UPDATE users SET name = \\? WHERE id = \\?").
+		ExpectExec().WithArgs("a", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := db.ExecContext(context.Background(), "UPDATE users SET name = ? WHERE id = ?", "a", 1); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if stats := db.StatementCacheStats(); stats.Misses != 2 || stats.Size != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatementCacheQueryRowReprepareAfterStaleStatement(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithStatementCache(8))
+	db = db.RequireRawApproval("statement cache test")
+
+	mock.ExpectPrepare("SELECT name FROM users WHERE id = \\?").
+		ExpectQuery().WithArgs(1).
+		WillReturnError(errors.New("ERROR: cached plan must not change result type"))
+	mock.ExpectPrepare("SELECT name FROM users WHERE id = \\?").
+		ExpectQuery().WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("alice"))
+
+	row, err := db.QueryRowE(context.Background(), "SELECT name FROM users WHERE id = ?", 1)
+	if err != nil {
+		t.Fatalf("query row: %v", err)
+	}
+	var name string
+	if err := row.Scan(&name); err != nil || name != "alice" {
+		t.Fatalf("scan: name=%q err=%v", name, err)
+	}
+	if stats := db.StatementCacheStats(); stats.Misses != 2 || stats.Size != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatementCacheSkipsPerRequestComments(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithStatementCache(8), WithSQLCommenter(map[string]string{"app": "billing"}))
+	db = db.RequireRawApproval("statement cache test")
+
+	for _, route := range []string{"/a", "/b"} {
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET age = 1 /*app='billing',goquent_fp=") + ".*" + regexp.QuoteMeta("route='%2F"+route[1:]+"'*/")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		ctx := ContextWithQueryTags(context.Background(), map[string]string{"route": route})
+		if _, err := db.ExecContext(ctx, "UPDATE users SET age = 1"); err != nil {
+			t.Fatalf("exec %s: %v", route, err)
+		}
+	}
+	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE users SET age = 1 /*app='billing',goquent_fp=") + "[^,]*" + regexp.QuoteMeta("'*/")).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := db.ExecContext(context.Background(), "UPDATE users SET age = 1"); err != nil {
+		t.Fatalf("exec static comment: %v", err)
+	}
+	if stats := db.StatementCacheStats(); stats.Misses != 1 || stats.Size != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatementCacheInTransaction(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithStatementCache(8))
+	db = db.RequireRawApproval("statement cache test")
+
+	mock.ExpectBegin()
+	// Prepared once for the cache and once on the transaction's connection.
+	mock.ExpectPrepare("UPDATE users SET age = age \\+ 1 WHERE id = \\?")
+	stmt := mock.ExpectPrepare("UPDATE users SET age = age \\+ 1 WHERE id = \\?")
+	stmt.ExpectExec().WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	stmt.ExpectExec().WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := db.Transaction(func(tx Tx) error {
+		for _, id := range []int{1, 2} {
+			if _, err := tx.ExecContext(context.Background(), "UPDATE users SET age = age + 1 WHERE id = ?", id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	if stats := db.StatementCacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatementCacheInTransactionFallsBackAfterStaleStatement(t *testing.T) {
+	db, mock := newSQLMockDB(t, driver.MySQLDialect{}, WithStatementCache(8))
+	db = db.RequireRawApproval("statement cache test")
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE users SET age = age \\+ 1 WHERE id = \\?")
+	mock.ExpectPrepare("UPDATE users SET age = age \\+ 1 WHERE id = \\?").
+		ExpectExec().WithArgs(1).
+		WillReturnError(errors.New("Error 1615: Prepared statement needs to be re-prepared"))
+	mock.ExpectExec("UPDATE users SET age = age \\+ 1 WHERE id = \\?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := db.Transaction(func(tx Tx) error {
+		_, err := tx.ExecContext(context.Background(), "UPDATE users SET age = age + 1 WHERE id = ?", 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	if stats := db.StatementCacheStats(); stats.Size != 0 {
+		t.Fatalf("expected the stale statement to be dropped, got %+v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,176 @@
+package orm
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/query"
+)
+
+// Span attribute keys. The db.* keys follow the OpenTelemetry database
+// semantic conventions.
+const (
+	AttrDBSystem            = "db.system"
+	AttrDBOperation         = "db.operation"
+	AttrDBSQLTable          = "db.sql.table"
+	AttrRiskLevel           = "goquent.risk_level"
+	AttrWarningCodes        = "goquent.warning_codes"
+	AttrPlanFingerprint     = "goquent.plan.fingerprint"
+	AttrApprovalPresent     = "goquent.approval.present"
+	AttrRows                = "goquent.rows"
+	AttrMigrationStatements = "goquent.migration.statements"
+)
+
+// Attribute is a span attribute. Value is a string, bool, int64 or []string.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Span is the part of a tracing span Goquent uses.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts spans. It mirrors the OpenTelemetry trace.Tracer shape so an
+// adapter only converts attributes:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string, attrs ...orm.Attribute) (context.Context, orm.Span) {
+//	    ctx, span := o.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+//	        trace.WithAttributes(toKeyValues(attrs)...))
+//	    return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// WithTracer creates a span for every execution, transaction and migration
+// applied with Migrator.Apply. The tracing interceptor is placed before all
+// other interceptors so their work is inside the span.
+func WithTracer(t Tracer) Option {
+	return func(db *DB) {
+		if t == nil {
+			return
+		}
+		db.tracer = t
+		system := dbSystem(db.drv)
+		trace := func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+			ctx, span := t.Start(ctx, spanName(plan), planAttributes(system, plan)...)
+			defer span.End()
+			res, err := next(ctx, plan)
+			span.SetAttributes(Attribute{Key: AttrRows, Value: res.Rows})
+			if err != nil {
+				span.RecordError(err)
+			}
+			return res, err
+		}
+		db.interceptors = append([]Interceptor{trace}, db.interceptors...)
+	}
+}
+
+func dbSystem(d *driver.Driver) string {
+	if d == nil {
+		return "other_sql"
+	}
+	switch d.Dialect.(type) {
+	case driver.MySQLDialect:
+		return "mysql"
+	case driver.PostgresDialect:
+		return "postgresql"
+	default:
+		return "other_sql"
+	}
+}
+
+// spanName follows the "<operation> <table>" convention.
+func spanName(plan *QueryPlan) string {
+	op := spanOperation(plan)
+	if len(plan.Tables) > 0 && plan.Tables[0].Name != "" {
+		return op + " " + plan.Tables[0].Name
+	}
+	return op
+}
+
+// spanOperation returns the SQL keyword of the plan. Raw and migration plans
+// use the statement's first keyword.
+func spanOperation(plan *QueryPlan) string {
+	switch plan.Operation {
+	case query.OperationRaw, query.OperationMigration:
+		if fields := strings.Fields(plan.SQL); len(fields) > 0 {
+			return strings.ToUpper(strings.TrimLeft(fields[0], "("))
+		}
+	}
+	return strings.ToUpper(string(plan.Operation))
+}
+
+func planAttributes(system string, plan *QueryPlan) []Attribute {
+	attrs := []Attribute{
+		{Key: AttrDBSystem, Value: system},
+		{Key: AttrDBOperation, Value: spanOperation(plan)},
+	}
+	if len(plan.Tables) > 0 && plan.Tables[0].Name != "" {
+		attrs = append(attrs, Attribute{Key: AttrDBSQLTable, Value: plan.Tables[0].Name})
+	}
+	attrs = append(attrs,
+		Attribute{Key: AttrRiskLevel, Value: string(plan.RiskLevel)},
+		Attribute{Key: AttrPlanFingerprint, Value: plan.Fingerprint},
+		Attribute{Key: AttrApprovalPresent, Value: plan.Approval != nil},
+	)
+	if len(plan.Warnings) > 0 {
+		attrs = append(attrs, Attribute{Key: AttrWarningCodes, Value: warningCodes(plan.Warnings)})
+	}
+	return attrs
+}
+
+func warningCodes(warnings []Warning) []string {
+	codes := make([]string, len(warnings))
+	for i, w := range warnings {
+		codes[i] = w.Code
+	}
+	return codes
+}
+
+// startSpan starts a span when a tracer is configured. The returned end
+// function records err and ends the span.
+func (db *DB) startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, func(error)) {
+	if db.tracer == nil {
+		return ctx, func(error) {}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := db.tracer.Start(ctx, name, append([]Attribute{{Key: AttrDBSystem, Value: dbSystem(db.drv)}}, attrs...)...)
+	var once sync.Once
+	return ctx, func(err error) {
+		once.Do(func() {
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.End()
+		})
+	}
+}
+
+// WrapMigrationApply runs apply inside a migration span. Migrator.Apply calls
+// it when the executor is a *DB.
+func (db *DB) WrapMigrationApply(ctx context.Context, plan *MigrationPlan, apply func(context.Context) error) error {
+	attrs := []Attribute{
+		{Key: AttrDBOperation, Value: string(query.OperationMigration)},
+		{Key: AttrRiskLevel, Value: string(plan.RiskLevel)},
+		{Key: AttrApprovalPresent, Value: plan.Approval != nil},
+		{Key: AttrMigrationStatements, Value: int64(len(plan.Statements))},
+	}
+	if len(plan.Warnings) > 0 {
+		attrs = append(attrs, Attribute{Key: AttrWarningCodes, Value: warningCodes(plan.Warnings)})
+	}
+	ctx, end := db.startSpan(ctx, "goquent.migration", attrs...)
+	err := apply(ctx)
+	end(err)
+	return err
+}
@@ -0,0 +1,178 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/faciam-dev/goquent/orm/driver"
+	"github.com/faciam-dev/goquent/orm/migration"
+)
+
+type spanKey struct{}
+
+type recordedSpan struct {
+	name   string
+	parent string
+	attrs  map[string]any
+	err    error
+	ended  int
+}
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &recordedSpan{name: name, attrs: map[string]any{}}
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		s.parent = parent.name
+	}
+	s.SetAttributes(attrs...)
+	r.spans = append(r.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+
+func (s *recordedSpan) End() { s.ended++ }
+
+func TestWithTracerCreatesQuerySpans(t *testing.T) {
+	tracer := &recordingTracer{}
+	var order []string
+	db, mock := newSQLMockDB(t, driver.PostgresDialect{}, WithInterceptors(func(ctx context.Context, plan *QueryPlan, next Handler) (ExecResult, error) {
+		if _, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+			order = append(order, "inside span")
+		}
+		return next(ctx, plan)
+	}), WithTracer(tracer))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE "id" = $1 LIMIT 1`)).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM sessions")).
+		WillReturnError(errors.New("boom"))
+
+	var row map[string]any
+	if err := db.Table("users").Select("id").Where("id", 7).Limit(1).FirstMap(&row); err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	if _, err := db.RequireRawApproval("cleanup").ExecContext(context.Background(), "DELETE FROM sessions"); err == nil {
+		t.Fatal("expected exec error")
+	}
+
+	if len(tracer.spans) != 2 || len(order) != 2 {
+		t.Fatalf("spans=%d interceptor calls in span=%d", len(tracer.spans), len(order))
+	}
+	sel := tracer.spans[0]
+	if sel.name != "SELECT users" || sel.ended != 1 {
+		t.Fatalf("unexpected select span %#v", sel)
+	}
+	want := map[string]any{
+		AttrDBSystem:        "postgresql",
+		AttrDBOperation:     "SELECT",
+		AttrDBSQLTable:      "users",
+		AttrRiskLevel:       "low",
+		AttrApprovalPresent: false,
+		AttrRows:            int64(1),
+	}
+	for k, v := range want {
+		if sel.attrs[k] != v {
+			t.Fatalf("attr %s = %#v, want %#v", k, sel.attrs[k], v)
+		}
+	}
+	if fp, _ := sel.attrs[AttrPlanFingerprint].(string); fp == "" {
+		t.Fatalf("missing fingerprint: %#v", sel.attrs)
+	}
+	raw := tracer.spans[1]
+	if raw.name != "DELETE" || raw.err == nil || raw.attrs[AttrApprovalPresent] != true {
+		t.Fatalf("unexpected raw span %#v", raw)
+	}
+	if codes, _ := raw.attrs[AttrWarningCodes].([]string); len(codes) == 0 || codes[0] != WarningRawSQLUsed {
+		t.Fatalf("unexpected raw warning codes %#v", raw.attrs[AttrWarningCodes])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithTracerCreatesTransactionAndMigrationSpans(t *testing.T) {
+	tracer := &recordingTracer{}
+	db, mock := newSQLMockDB(t, driver.PostgresDialect{}, WithTracer(tracer))
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE audit_logs (id BIGINT PRIMARY KEY)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if err := tx.Rollback(); !errors.Is(err, sql.ErrTxDone) {
+		t.Fatalf("expected ErrTxDone, got %v", err)
+	}
+	if _, err := migration.New("CREATE TABLE audit_logs (id BIGINT PRIMARY KEY);").Apply(context.Background(), db); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(tracer.spans))
+	}
+	if s := tracer.spans[0]; s.name != "goquent.transaction" || s.ended != 1 || s.err != nil {
+		t.Fatalf("unexpected transaction span %#v", s)
+	}
+	mig, stmt := tracer.spans[1], tracer.spans[2]
+	if mig.name != "goquent.migration" || mig.attrs[AttrMigrationStatements] != int64(1) || mig.ended != 1 {
+		t.Fatalf("unexpected migration span %#v", mig)
+	}
+	if stmt.name != "CREATE audit_logs" || stmt.parent != "goquent.migration" || stmt.attrs[AttrDBOperation] != "CREATE" {
+		t.Fatalf("unexpected statement span %#v", stmt)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithTracerNestsQuerySpansUnderTransaction(t *testing.T) {
+	tracer := &recordingTracer{}
+	db, mock := newSQLMockDB(t, driver.PostgresDialect{}, WithTracer(tracer))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "sessions" WHERE "id" = $1`)).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "tokens" WHERE "id" = $1`)).
+		WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := db.Transaction(func(tx Tx) error {
+		if _, err := tx.Table("sessions").Where("id", 1).Delete(); err != nil {
+			return err
+		}
+		_, err := tx.Table("tokens").Where("id", 2).WithContext(tx.Context()).Delete()
+		return err
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	if len(tracer.spans) != 3 || tracer.spans[0].name != "goquent.transaction" {
+		t.Fatalf("unexpected spans %#v", tracer.spans)
+	}
+	for _, s := range tracer.spans[1:] {
+		if s.parent != "goquent.transaction" {
+			t.Fatalf("query span %q is not a child of the transaction span: %#v", s.name, s)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
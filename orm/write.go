@@ -29,7 +29,9 @@ type writeOptions struct {
 	conflictConstraint string
 	conflictTargetRaw  string
 	upsertUpdateCols   []string
+	upsertUpdateExprs  map[string]query.Expr
 	hasUpsertUpdates   bool
+	err                error
 }
 
 // Columns limits write to specified columns.
@@ -89,19 +91,60 @@ func ConflictTargetRaw(target string) WriteOpt {
 	return func(o *writeOptions) { o.conflictTargetRaw = target }
 }
 
+// Assignment sets a conflict UPDATE column to an expression; see Assign.
+type Assignment struct {
+	Column string
+	Expr   Expr
+}
+
+// Assign returns an UpdateColumns entry that sets col to expr instead of the
+// inserted value, for example:
+//
+//	UpdateColumns("name", Assign("hits", NewExpr(`"hits" + ?`, 1)))
+func Assign(col string, expr Expr) Assignment {
+	return Assignment{Column: col, Expr: expr}
+}
+
 // UpdateColumns limits the conflict UPDATE side of Upsert/UpsertReturning.
-// The insert side still uses Columns/Omit plus required conflict or primary-key columns.
-func UpdateColumns(cols ...string) WriteOpt {
+// Each entry is a column name, updated from the inserted row, or an
+// Assignment from Assign. The insert side still uses Columns/Omit plus
+// required conflict or primary-key columns.
+func UpdateColumns(cols ...any) WriteOpt {
 	return func(o *writeOptions) {
-		o.upsertUpdateCols = append([]string(nil), cols...)
+		o.upsertUpdateCols = nil
 		o.hasUpsertUpdates = true
+		for _, col := range cols {
+			switch c := col.(type) {
+			case string:
+				o.upsertUpdateCols = append(o.upsertUpdateCols, c)
+			case Assignment:
+				o.setUpsertUpdateExpr(c.Column, c.Expr)
+			default:
+				o.err = fmt.Errorf("goquent: UpdateColumns accepts column names and Assign values, got %T", col)
+			}
+		}
 	}
 }
 
+// UpdateColumnExpr sets the conflict UPDATE assignment for col to expr, like
+// passing Assign(col, expr) to UpdateColumns. It replaces the default
+// excluded/VALUES assignment when col is also updated from the inserted row.
+func UpdateColumnExpr(col string, expr Expr) WriteOpt {
+	return func(o *writeOptions) { o.setUpsertUpdateExpr(col, expr) }
+}
+
+func (o *writeOptions) setUpsertUpdateExpr(col string, expr Expr) {
+	if o.upsertUpdateExprs == nil {
+		o.upsertUpdateExprs = make(map[string]query.Expr)
+	}
+	o.upsertUpdateExprs[col] = expr
+}
+
 // ConflictDoNothing makes Upsert/UpsertReturning use a no-op conflict action.
 func ConflictDoNothing() WriteOpt {
 	return func(o *writeOptions) {
 		o.upsertUpdateCols = nil
+		o.upsertUpdateExprs = nil
 		o.hasUpsertUpdates = true
 	}
 }
@@ -154,12 +197,25 @@ func (o *writeOptions) isConflictColumn(col string) bool {
 
 func quote(d driver.Dialect, ident string) string { return d.QuoteIdent(ident) }
 
-func buildPlaceholders(d driver.Dialect, n int, start int) []string {
-	ph := make([]string, n)
-	for i := 0; i < n; i++ {
-		ph[i] = d.Placeholder(start + i)
+// insertValues renders the VALUES list of a single-row insert: query.Expr
+// values are rendered as SQL, like in Update, and other values are bound.
+func insertValues(d driver.Dialect, values []any) ([]string, []any, error) {
+	ph := make([]string, len(values))
+	args := make([]any, 0, len(values))
+	for i, v := range values {
+		if expr, ok := v.(query.Expr); ok {
+			exprSQL, exprArgs, err := expr.Render(d, len(args)+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			ph[i] = exprSQL
+			args = append(args, exprArgs...)
+			continue
+		}
+		args = append(args, v)
+		ph[i] = d.Placeholder(len(args))
 	}
-	return ph
+	return ph, args, nil
 }
 
 type returningResult struct {
@@ -188,54 +244,36 @@ func appendReturningClause(d driver.Dialect, sqlStr string, cols []string) (stri
 	return sqlStr + " RETURNING " + strings.Join(rc, ", "), nil
 }
 
-func execReturningRows(ctx context.Context, db *DB, sqlStr string, args ...any) (sql.Result, error) {
-	var (
-		rows *sql.Rows
-		err  error
-	)
-	if ctx != nil {
-		rows, err = db.exec.QueryContext(ctx, sqlStr, args...)
-	} else {
-		rows, err = db.exec.Query(sqlStr, args...)
-	}
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	cols, err := rows.Columns()
-	if err != nil {
-		return nil, err
-	}
-	scanDst := make([]any, len(cols))
-	values := make([]any, len(cols))
-	for i := range scanDst {
-		scanDst[i] = &values[i]
-	}
-
+func execReturningRows(ctx context.Context, db *DB, plan *query.QueryPlan) (sql.Result, error) {
 	var count int64
-	for rows.Next() {
-		if len(scanDst) > 0 {
-			if err := rows.Scan(scanDst...); err != nil {
-				return nil, err
+	err := db.scanPlan(ctx, plan, func(rows *sql.Rows) (int64, error) {
+		cols, err := rows.Columns()
+		if err != nil {
+			return 0, err
+		}
+		scanDst := make([]any, len(cols))
+		values := make([]any, len(cols))
+		for i := range scanDst {
+			scanDst[i] = &values[i]
+		}
+		for rows.Next() {
+			if len(scanDst) > 0 {
+				if err := rows.Scan(scanDst...); err != nil {
+					return 0, err
+				}
 			}
+			count++
 		}
-		count++
-	}
-	if err := rows.Err(); err != nil {
+		return count, rows.Err()
+	})
+	if err != nil {
 		return nil, err
 	}
 	return returningResult{rowsAffected: count}, nil
 }
 
-func queryReturningOne[T any](ctx context.Context, db *DB, sqlStr string, args ...any) (T, error) {
-	var zero T
-	rows, err := db.queryContextTrusted(ctx, sqlStr, args...)
-	if err != nil {
-		return zero, err
-	}
-	defer rows.Close()
-	return scanRowsOne[T](db, rows)
+func queryReturningOne[T any](ctx context.Context, db *DB, plan *query.QueryPlan) (T, error) {
+	return selectOnePlan[T](ctx, db, plan)
 }
 
 func ensureReturningColumns[T any](o *writeOptions) error {
@@ -269,11 +307,11 @@ func returningColumnsForQuery[T any]() ([]string, error) {
 	return cols, nil
 }
 
-func execWriteStatement(ctx context.Context, db *DB, sqlStr string, args []any, returning bool) (sql.Result, error) {
+func execWriteStatement(ctx context.Context, db *DB, plan *query.QueryPlan, returning bool) (sql.Result, error) {
 	if returning {
-		return execReturningRows(ctx, db, sqlStr, args...)
+		return execReturningRows(ctx, db, plan)
 	}
-	return db.execContextTrusted(ctx, sqlStr, args...)
+	return db.execPlan(ctx, plan)
 }
 
 func validateWriteRawSQLFragment(raw string) error {
@@ -296,14 +334,15 @@ func validateWriteRawSQLFragment(raw string) error {
 	return nil
 }
 
-// Insert inserts v into its table.
+// Insert inserts v into its table. Map values of type Expr are rendered as
+// SQL rather than bound.
 func Insert[T any](ctx context.Context, db *DB, v T, opts ...WriteOpt) (sql.Result, error) {
 	o := applyWriteOpts(opts)
 	sqlStr, args, err := buildInsertStatement(db, v, o)
 	if err != nil {
 		return nil, err
 	}
-	return execWriteStatement(ctx, db, sqlStr, args, len(o.returning) > 0)
+	return execWriteStatement(ctx, db, writePlan(query.OperationInsert, writeTableName(v, o), sqlStr, args), len(o.returning) > 0)
 }
 
 // InsertReturning inserts v and scans the Postgres RETURNING row into T.
@@ -317,7 +356,7 @@ func InsertReturning[T any, V any](ctx context.Context, db *DB, v V, opts ...Wri
 	if err != nil {
 		return zero, err
 	}
-	return queryReturningOne[T](ctx, db, sqlStr, args...)
+	return queryReturningOne[T](ctx, db, writePlan(query.OperationInsert, writeTableName(v, o), sqlStr, args))
 }
 
 func buildInsertStatement(db *DB, v any, o *writeOptions) (string, []any, error) {
@@ -372,7 +411,7 @@ func buildInsertStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 				continue
 			}
 			cols = append(cols, fm.Col)
-			args = append(args, fv.Interface())
+			args = append(args, fieldArg(db, fm, fv))
 		}
 	} else {
 		return "", nil, fmt.Errorf("unsupported type %s", typ)
@@ -380,27 +419,30 @@ func buildInsertStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 	if len(cols) == 0 {
 		return "", nil, fmt.Errorf("no columns to insert")
 	}
-	ph := buildPlaceholders(db.drv.Dialect, len(cols), 1)
+	ph, args, err := insertValues(db.drv.Dialect, args)
+	if err != nil {
+		return "", nil, err
+	}
 	quotedCols := make([]string, len(cols))
 	for i, c := range cols {
 		quotedCols[i] = quote(db.drv.Dialect, c)
 	}
 	sqlStr := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quote(db.drv.Dialect, table), strings.Join(quotedCols, ", "), strings.Join(ph, ", "))
-	sqlStr, err := appendReturningClause(db.drv.Dialect, sqlStr, o.returning)
+	sqlStr, err = appendReturningClause(db.drv.Dialect, sqlStr, o.returning)
 	if err != nil {
 		return "", nil, err
 	}
-	return sqlStr, args, nil
+	return sqlStr, bindWriteArgs(db, args), nil
 }
 
 // Update updates record v.
 func Update[T any](ctx context.Context, db *DB, v T, opts ...WriteOpt) (sql.Result, error) {
 	o := applyWriteOpts(opts)
-	sqlStr, args, err := buildUpdateStatement(db, v, o)
+	plan, err := updatePlan(db, v, o)
 	if err != nil {
 		return nil, err
 	}
-	return execWriteStatement(ctx, db, sqlStr, args, len(o.returning) > 0)
+	return execWriteStatement(ctx, db, plan, len(o.returning) > 0)
 }
 
 // UpdateReturning updates v and scans the Postgres RETURNING row into T.
@@ -410,16 +452,35 @@ func UpdateReturning[T any, V any](ctx context.Context, db *DB, v V, opts ...Wri
 	if err := ensureReturningColumns[T](o); err != nil {
 		return zero, err
 	}
-	sqlStr, args, err := buildUpdateStatement(db, v, o)
+	plan, err := updatePlan(db, v, o)
 	if err != nil {
 		return zero, err
 	}
-	return queryReturningOne[T](ctx, db, sqlStr, args...)
+	return queryReturningOne[T](ctx, db, plan)
 }
 
-func buildUpdateStatement(db *DB, v any, o *writeOptions) (string, []any, error) {
+// updatePlan builds the plan of Update[T], checked against the write policy
+// of the table.
+func updatePlan(db *DB, v any, o *writeOptions) (*query.QueryPlan, error) {
+	sqlStr, args, setCols, err := buildUpdateStatement(db, v, o)
+	if err != nil {
+		return nil, err
+	}
+	plan := writePlan(query.OperationUpdate, writeTableName(v, o), sqlStr, args)
+	for _, col := range setCols {
+		plan.Columns = append(plan.Columns, query.ColumnRef{Name: col})
+	}
+	if err := ensureWritePolicy(db, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// buildUpdateStatement returns the UPDATE statement for v and the columns it
+// sets.
+func buildUpdateStatement(db *DB, v any, o *writeOptions) (string, []any, []string, error) {
 	if !o.wherePK {
-		return "", nil, fmt.Errorf("Update[T] without WherePK is not allowed")
+		return "", nil, nil, fmt.Errorf("Update[T] without WherePK is not allowed")
 	}
 	val := reflect.ValueOf(v)
 	typ := val.Type()
@@ -431,10 +492,10 @@ func buildUpdateStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 
 	if isMapStringInterface(typ) {
 		if o.table == "" {
-			return "", nil, fmt.Errorf("Table option required for map writes")
+			return "", nil, nil, fmt.Errorf("Table option required for map writes")
 		}
 		if len(o.pkCols) == 0 {
-			return "", nil, fmt.Errorf("WherePK for map writes requires PK columns via PK option")
+			return "", nil, nil, fmt.Errorf("WherePK for map writes requires PK columns via PK option")
 		}
 		table = o.table
 		iter := val.MapRange()
@@ -461,7 +522,7 @@ func buildUpdateStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 		}
 		for pk := range o.pkCols {
 			if !seen[pk] {
-				return "", nil, fmt.Errorf("WherePK requires pk column %s", pk)
+				return "", nil, nil, fmt.Errorf("WherePK requires pk column %s", pk)
 			}
 		}
 	} else if typ.Kind() == reflect.Struct {
@@ -471,13 +532,14 @@ func buildUpdateStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 		}
 		meta, err := getTypeMeta(typ)
 		if err != nil {
-			return "", nil, err
+			return "", nil, nil, err
 		}
+		updatable := defaultUpdatable(table, o)
 		for _, fm := range meta.FieldsByName {
 			fv := val.FieldByIndex(fm.IndexPath)
 			if fm.PK {
 				whereCols = append(whereCols, fm.Col)
-				whereArgs = append(whereArgs, fv.Interface())
+				whereArgs = append(whereArgs, fieldArg(db, fm, fv))
 				continue
 			}
 			if fm.Readonly {
@@ -494,43 +556,57 @@ func buildUpdateStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 			if fm.OmitEmpty && fv.IsZero() {
 				continue
 			}
+			if !updatable(fm.Col) {
+				continue
+			}
 			setCols = append(setCols, fm.Col)
-			setArgs = append(setArgs, fv.Interface())
+			setArgs = append(setArgs, fieldArg(db, fm, fv))
 		}
 	} else {
-		return "", nil, fmt.Errorf("unsupported type %s", typ)
+		return "", nil, nil, fmt.Errorf("unsupported type %s", typ)
 	}
 	if len(whereCols) == 0 {
-		return "", nil, fmt.Errorf("WherePK requires pk values")
+		return "", nil, nil, fmt.Errorf("WherePK requires pk values")
 	}
 	if len(setCols) == 0 {
-		return "", nil, fmt.Errorf("no columns to update")
+		return "", nil, nil, fmt.Errorf("no columns to update")
 	}
 	setParts := make([]string, len(setCols))
+	args := make([]any, 0, len(setArgs)+len(whereArgs))
 	for i, col := range setCols {
-		setParts[i] = fmt.Sprintf("%s=%s", quote(db.drv.Dialect, col), db.drv.Dialect.Placeholder(i+1))
+		if expr, ok := setArgs[i].(query.Expr); ok {
+			exprSQL, exprArgs, err := expr.Render(db.drv.Dialect, len(args)+1)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			setParts[i] = fmt.Sprintf("%s=%s", quote(db.drv.Dialect, col), exprSQL)
+			args = append(args, exprArgs...)
+			continue
+		}
+		args = append(args, setArgs[i])
+		setParts[i] = fmt.Sprintf("%s=%s", quote(db.drv.Dialect, col), db.drv.Dialect.Placeholder(len(args)))
 	}
 	whereParts := make([]string, len(whereCols))
 	for i, col := range whereCols {
-		whereParts[i] = fmt.Sprintf("%s=%s", quote(db.drv.Dialect, col), db.drv.Dialect.Placeholder(len(setArgs)+i+1))
+		args = append(args, whereArgs[i])
+		whereParts[i] = fmt.Sprintf("%s=%s", quote(db.drv.Dialect, col), db.drv.Dialect.Placeholder(len(args)))
 	}
-	args := append(append([]any(nil), setArgs...), whereArgs...)
 	sqlStr := fmt.Sprintf("UPDATE %s SET %s WHERE %s", quote(db.drv.Dialect, table), strings.Join(setParts, ", "), strings.Join(whereParts, " AND "))
 	sqlStr, err := appendReturningClause(db.drv.Dialect, sqlStr, o.returning)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
-	return sqlStr, args, nil
+	return sqlStr, bindWriteArgs(db, args), setCols, nil
 }
 
 // Upsert inserts or updates v using primary keys.
 func Upsert[T any](ctx context.Context, db *DB, v T, opts ...WriteOpt) (sql.Result, error) {
 	o := applyWriteOpts(opts)
-	sqlStr, args, err := buildUpsertStatement(db, v, o)
+	plan, err := upsertPlan(db, v, o)
 	if err != nil {
 		return nil, err
 	}
-	return execWriteStatement(ctx, db, sqlStr, args, len(o.returning) > 0)
+	return execWriteStatement(ctx, db, plan, len(o.returning) > 0)
 }
 
 // UpsertReturning upserts v and scans the Postgres RETURNING row into T.
@@ -540,11 +616,59 @@ func UpsertReturning[T any, V any](ctx context.Context, db *DB, v V, opts ...Wri
 	if err := ensureReturningColumns[T](o); err != nil {
 		return zero, err
 	}
-	sqlStr, args, err := buildUpsertStatement(db, v, o)
+	plan, err := upsertPlan(db, v, o)
 	if err != nil {
 		return zero, err
 	}
-	return queryReturningOne[T](ctx, db, sqlStr, args...)
+	return queryReturningOne[T](ctx, db, plan)
+}
+
+// upsertPlan builds the plan of Upsert[T], checked against the write policy
+// of the table for the columns its conflict path updates.
+func upsertPlan(db *DB, v any, o *writeOptions) (*query.QueryPlan, error) {
+	sqlStr, args, updateCols, err := buildUpsertStatement(db, v, o)
+	if err != nil {
+		return nil, err
+	}
+	plan := writePlan(query.OperationInsert, writeTableName(v, o), sqlStr, args)
+	plan.Metadata = map[string]any{"insert_mode": "upsert", "update_columns": updateCols}
+	if len(o.upsertUpdateExprs) > 0 {
+		exprs := make(map[string]string, len(o.upsertUpdateExprs))
+		for col, expr := range o.upsertUpdateExprs {
+			exprs[col] = strings.TrimSpace(expr.SQL)
+		}
+		plan.Metadata["update_expressions"] = exprs
+	}
+	if err := ensureWritePolicy(db, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// defaultUpdatable reports whether a struct field may be left in the default
+// SET or upsert update list of a write to table. Columns the table's write
+// policy protects are only updated when named with Columns, so that writing
+// back a loaded record does not trip IMMUTABLE_COLUMN_UPDATED. Map keys are
+// set by the caller and are not filtered.
+func defaultUpdatable(table string, o *writeOptions) func(string) bool {
+	policy, ok := query.PolicyForTable(table)
+	return func(col string) bool {
+		if !ok || policy.Writable(col) {
+			return true
+		}
+		_, named := o.cols[col]
+		return named
+	}
+}
+
+// ensureWritePolicy refuses generic writes that change immutable or
+// insert-only columns when the table's write policy enforces or blocks them.
+// Plans without write policy warnings are left unchecked.
+func ensureWritePolicy(db *DB, plan *query.QueryPlan) error {
+	if !query.ApplyWritePolicy(plan) {
+		return nil
+	}
+	return db.ensureExecutable(plan)
 }
 
 // InsertOnceReturning inserts v once and scans the inserted or existing row.
@@ -560,13 +684,15 @@ func InsertOnceReturning[T any, V any](ctx context.Context, db *DB, v V, opts ..
 		return zero, false, err
 	}
 	o.upsertUpdateCols = nil
+	o.upsertUpdateExprs = nil
 	o.hasUpsertUpdates = true
 
-	sqlStr, args, err := buildUpsertStatement(db, v, o)
+	plan, err := upsertPlan(db, v, o)
 	if err != nil {
 		return zero, false, err
 	}
-	inserted, err := queryReturningOne[T](ctx, db, sqlStr, args...)
+	plan.Metadata["insert_mode"] = "insert_once"
+	inserted, err := queryReturningOne[T](ctx, db, plan)
 	if err == nil {
 		return inserted, true, nil
 	}
@@ -581,9 +707,14 @@ func InsertOnceReturning[T any, V any](ctx context.Context, db *DB, v V, opts ..
 	return existing, false, nil
 }
 
-func buildUpsertStatement(db *DB, v any, o *writeOptions) (string, []any, error) {
+// buildUpsertStatement returns the upsert statement for v and the columns
+// its conflict path updates.
+func buildUpsertStatement(db *DB, v any, o *writeOptions) (string, []any, []string, error) {
+	if o.err != nil {
+		return "", nil, nil, o.err
+	}
 	if !o.wherePK && !o.hasConflictTarget() {
-		return "", nil, fmt.Errorf("Upsert[T] requires WherePK, ConflictColumns, or ConflictConstraint")
+		return "", nil, nil, fmt.Errorf("Upsert[T] requires WherePK, ConflictColumns, or ConflictConstraint")
 	}
 	val := reflect.ValueOf(v)
 	typ := val.Type()
@@ -594,10 +725,10 @@ func buildUpsertStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 
 	if isMapStringInterface(typ) {
 		if o.table == "" {
-			return "", nil, fmt.Errorf("Table option required for map writes")
+			return "", nil, nil, fmt.Errorf("Table option required for map writes")
 		}
 		if o.wherePK && len(o.pkCols) == 0 {
-			return "", nil, fmt.Errorf("WherePK for map writes requires PK columns via PK option")
+			return "", nil, nil, fmt.Errorf("WherePK for map writes requires PK columns via PK option")
 		}
 		table = o.table
 		iter := val.MapRange()
@@ -631,7 +762,7 @@ func buildUpsertStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 		if o.wherePK {
 			for pk := range o.pkCols {
 				if !seen[pk] {
-					return "", nil, fmt.Errorf("WherePK requires pk column %s", pk)
+					return "", nil, nil, fmt.Errorf("WherePK requires pk column %s", pk)
 				}
 			}
 		}
@@ -642,19 +773,19 @@ func buildUpsertStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 		}
 		meta, err := getTypeMeta(typ)
 		if err != nil {
-			return "", nil, err
+			return "", nil, nil, err
 		}
 		for _, fm := range meta.FieldsByName {
 			fv := val.FieldByIndex(fm.IndexPath)
 			if fm.PK {
 				pkCols = append(pkCols, fm.Col)
 				cols = append(cols, fm.Col)
-				args = append(args, fv.Interface())
+				args = append(args, fieldArg(db, fm, fv))
 				continue
 			}
 			if o.isConflictColumn(fm.Col) {
 				cols = append(cols, fm.Col)
-				args = append(args, fv.Interface())
+				args = append(args, fieldArg(db, fm, fv))
 				continue
 			}
 			if fm.Readonly {
@@ -672,40 +803,49 @@ func buildUpsertStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 				continue
 			}
 			cols = append(cols, fm.Col)
-			args = append(args, fv.Interface())
+			args = append(args, fieldArg(db, fm, fv))
 		}
 	} else {
-		return "", nil, fmt.Errorf("unsupported type %s", typ)
+		return "", nil, nil, fmt.Errorf("unsupported type %s", typ)
 	}
 	if o.wherePK && len(pkCols) == 0 {
-		return "", nil, fmt.Errorf("WherePK requires pk values")
+		return "", nil, nil, fmt.Errorf("WherePK requires pk values")
 	}
 	if len(cols) == 0 {
-		return "", nil, fmt.Errorf("no columns to insert")
+		return "", nil, nil, fmt.Errorf("no columns to insert")
 	}
 	if err := ensureConflictColumnsPresent(o.conflictCols, cols); err != nil {
-		return "", nil, err
+		return "", nil, nil, err
+	}
+	ph, args, err := insertValues(db.drv.Dialect, args)
+	if err != nil {
+		return "", nil, nil, err
 	}
-	ph := buildPlaceholders(db.drv.Dialect, len(cols), 1)
 	quotedCols := make([]string, len(cols))
 	for i, c := range cols {
 		quotedCols[i] = quote(db.drv.Dialect, c)
 	}
 	sqlStr := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quote(db.drv.Dialect, table), strings.Join(quotedCols, ", "), strings.Join(ph, ", "))
 	targetCols := conflictTargetColumns(o, pkCols)
-	updateCols, err := upsertUpdateColumns(cols, targetCols, o)
+	updatable := defaultUpdatable(table, o)
+	if isMapStringInterface(typ) {
+		updatable = func(string) bool { return true }
+	}
+	updateCols, err := upsertUpdateColumns(cols, targetCols, o, updatable)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	switch db.drv.Dialect.(type) {
 	case driver.MySQLDialect:
 		if strings.TrimSpace(o.conflictWhere) != "" || strings.TrimSpace(o.conflictConstraint) != "" || strings.TrimSpace(o.conflictTargetRaw) != "" {
-			return "", nil, fmt.Errorf("ConflictWhere, ConflictConstraint, and ConflictTargetRaw are not supported on dialect: %T", db.drv.Dialect)
+			return "", nil, nil, fmt.Errorf("ConflictWhere, ConflictConstraint, and ConflictTargetRaw are not supported on dialect: %T", db.drv.Dialect)
 		}
 		if len(updateCols) > 0 {
-			assigns := make([]string, len(updateCols))
-			for i, c := range updateCols {
-				assigns[i] = fmt.Sprintf("%s=VALUES(%s)", quote(db.drv.Dialect, c), quote(db.drv.Dialect, c))
+			assigns, err := upsertAssignments(db.drv.Dialect, updateCols, o, &args, func(c string) string {
+				return fmt.Sprintf("VALUES(%s)", quote(db.drv.Dialect, c))
+			})
+			if err != nil {
+				return "", nil, nil, err
 			}
 			sqlStr += " ON DUPLICATE KEY UPDATE " + strings.Join(assigns, ", ")
 		} else {
@@ -714,30 +854,32 @@ func buildUpsertStatement(db *DB, v any, o *writeOptions) (string, []any, error)
 	case driver.PostgresDialect:
 		target, err := postgresConflictTarget(db.drv.Dialect, targetCols, o)
 		if err != nil {
-			return "", nil, err
+			return "", nil, nil, err
 		}
 		if len(updateCols) > 0 {
-			assigns := make([]string, len(updateCols))
-			for i, c := range updateCols {
-				assigns[i] = fmt.Sprintf("%s=EXCLUDED.%s", quote(db.drv.Dialect, c), quote(db.drv.Dialect, c))
+			assigns, err := upsertAssignments(db.drv.Dialect, updateCols, o, &args, func(c string) string {
+				return "EXCLUDED." + quote(db.drv.Dialect, c)
+			})
+			if err != nil {
+				return "", nil, nil, err
 			}
 			sqlStr += fmt.Sprintf(" ON CONFLICT %s DO UPDATE SET %s", target, strings.Join(assigns, ", "))
 		} else {
 			sqlStr += fmt.Sprintf(" ON CONFLICT %s DO NOTHING", target)
 		}
 	default:
-		return "", nil, fmt.Errorf("upsert not supported on dialect: %T", db.drv.Dialect)
+		return "", nil, nil, fmt.Errorf("upsert not supported on dialect: %T", db.drv.Dialect)
 	}
 	sqlStr, err = appendReturningClause(db.drv.Dialect, sqlStr, o.returning)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
-	return sqlStr, args, nil
+	return sqlStr, bindWriteArgs(db, args), updateCols, nil
 }
 
 func selectExistingInsertOnceRow[T any](ctx context.Context, db *DB, v any, o *writeOptions) (T, error) {
 	var zero T
-	table, values, pkCols, err := writeLookupValues(v, o)
+	table, values, pkCols, err := writeLookupValues(db, v, o)
 	if err != nil {
 		return zero, err
 	}
@@ -764,13 +906,13 @@ func selectExistingInsertOnceRow[T any](ctx context.Context, db *DB, v any, o *w
 	if err != nil {
 		return zero, err
 	}
-	if err := query.EnsurePlanExecutable(plan); err != nil {
+	if err := db.ensureExecutable(plan); err != nil {
 		return zero, err
 	}
-	return SelectOne[T](ctx, db.RequireRawApproval("goquent generated insert-once lookup"), plan.SQL, plan.Params...)
+	return selectOnePlan[T](ctx, db, plan)
 }
 
-func writeLookupValues(v any, o *writeOptions) (string, map[string]any, []string, error) {
+func writeLookupValues(db *DB, v any, o *writeOptions) (string, map[string]any, []string, error) {
 	val := reflect.ValueOf(v)
 	typ := val.Type()
 	values := make(map[string]any)
@@ -808,7 +950,7 @@ func writeLookupValues(v any, o *writeOptions) (string, map[string]any, []string
 	}
 	for _, fm := range meta.FieldsByName {
 		fv := val.FieldByIndex(fm.IndexPath)
-		values[fm.Col] = fv.Interface()
+		values[fm.Col] = fieldArg(db, fm, fv)
 	}
 	pkCols = append(pkCols, meta.PKCols...)
 	return table, values, pkCols, nil
@@ -821,13 +963,13 @@ func conflictTargetColumns(o *writeOptions, pkCols []string) []string {
 	return append([]string(nil), pkCols...)
 }
 
-func upsertUpdateColumns(cols []string, targetCols []string, o *writeOptions) ([]string, error) {
+func upsertUpdateColumns(cols []string, targetCols []string, o *writeOptions, updatable func(string) bool) ([]string, error) {
 	if o.hasUpsertUpdates {
 		updateCols := dedupeColumns(o.upsertUpdateCols)
 		if err := ensureUpsertUpdateColumnsPresent(updateCols, cols); err != nil {
 			return nil, err
 		}
-		return updateCols, nil
+		return appendExprUpdateColumns(updateCols, o), nil
 	}
 	target := make(map[string]struct{}, len(targetCols))
 	for _, col := range targetCols {
@@ -835,12 +977,51 @@ func upsertUpdateColumns(cols []string, targetCols []string, o *writeOptions) ([
 	}
 	updateCols := make([]string, 0, len(cols))
 	for _, col := range cols {
-		if _, ok := target[col]; ok {
+		if _, ok := target[col]; ok || !updatable(col) {
 			continue
 		}
 		updateCols = append(updateCols, col)
 	}
-	return updateCols, nil
+	return appendExprUpdateColumns(updateCols, o), nil
+}
+
+// appendExprUpdateColumns adds UpdateColumnExpr targets that are not already
+// part of the conflict UPDATE column list, in sorted order.
+func appendExprUpdateColumns(updateCols []string, o *writeOptions) []string {
+	if len(o.upsertUpdateExprs) == 0 {
+		return updateCols
+	}
+	present := make(map[string]struct{}, len(updateCols))
+	for _, col := range updateCols {
+		present[col] = struct{}{}
+	}
+	extra := make([]string, 0, len(o.upsertUpdateExprs))
+	for col := range o.upsertUpdateExprs {
+		if _, ok := present[col]; !ok {
+			extra = append(extra, col)
+		}
+	}
+	sort.Strings(extra)
+	return append(updateCols, extra...)
+}
+
+// upsertAssignments renders conflict UPDATE assignments. Expression arguments
+// are appended to args after the insert values.
+func upsertAssignments(d driver.Dialect, updateCols []string, o *writeOptions, args *[]any, inserted func(string) string) ([]string, error) {
+	assigns := make([]string, len(updateCols))
+	for i, c := range updateCols {
+		if expr, ok := o.upsertUpdateExprs[c]; ok {
+			exprSQL, exprArgs, err := expr.Render(d, len(*args)+1)
+			if err != nil {
+				return nil, err
+			}
+			*args = append(*args, exprArgs...)
+			assigns[i] = fmt.Sprintf("%s=%s", quote(d, c), exprSQL)
+			continue
+		}
+		assigns[i] = fmt.Sprintf("%s=%s", quote(d, c), inserted(c))
+	}
+	return assigns, nil
 }
 
 func dedupeColumns(cols []string) []string {
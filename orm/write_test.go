@@ -455,6 +455,7 @@ func TestInsertOnceReturningInsertedRow(t *testing.T) {
 		db,
 		genericWriteUser{ID: 5, Name: "alice", Age: 32},
 		WherePK(),
+		UpdateColumnExpr("age", NewExpr(`"users"."age" + ?`, 1)),
 	)
 	if err != nil {
 		t.Fatalf("insert once returning: %v", err)
@@ -534,3 +535,155 @@ func TestUpsertPostgresConflictConstraint(t *testing.T) {
 		t.Fatalf("expected named constraint conflict target, got: %s", exec.query)
 	}
 }
+
+func TestUpdateMapWithExprValue(t *testing.T) {
+	db, exec := newCaptureWriteDB(driver.PostgresDialect{})
+
+	_, err := Update(
+		context.Background(),
+		db,
+		map[string]any{"id": 9, "hits": NewExpr(`"hits" + ?`, 1)},
+		Table("pages"),
+		PK("id"),
+		WherePK(),
+	)
+	if err != nil {
+		t.Fatalf("update expr: %v", err)
+	}
+	want := `UPDATE "pages" SET "hits"="hits" + $1 WHERE "id"=$2`
+	if exec.query != want {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", exec.query, want)
+	}
+	if !reflect.DeepEqual(exec.args, []any{1, 9}) {
+		t.Fatalf("unexpected args: %#v", exec.args)
+	}
+}
+
+func TestInsertMapWithExprValue(t *testing.T) {
+	db, exec := newCaptureWriteDB(driver.PostgresDialect{})
+
+	_, err := Insert(context.Background(), db, map[string]any{"hits": NewExpr(`? + 1`, 2)}, Table("pages"))
+	if err != nil {
+		t.Fatalf("insert expr: %v", err)
+	}
+	want := `INSERT INTO "pages" ("hits") VALUES ($1 + 1)`
+	if exec.query != want {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", exec.query, want)
+	}
+	if !reflect.DeepEqual(exec.args, []any{2}) {
+		t.Fatalf("unexpected args: %#v", exec.args)
+	}
+}
+
+func TestUpsertUpdateColumnExpr(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		dialect driver.Dialect
+		want    string
+	}{
+		{name: "postgres", dialect: driver.PostgresDialect{}, want: `ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name", "hits"="pages"."hits" + $3`},
+		{name: "mysql", dialect: driver.MySQLDialect{}, want: "ON DUPLICATE KEY UPDATE `name`=VALUES(`name`), `hits`=`hits` + ?"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, exec := newCaptureWriteDB(tc.dialect)
+			expr := NewExpr("`hits` + ?", 1)
+			if tc.name == "postgres" {
+				expr = NewExpr(`"pages"."hits" + ?`, 1)
+			}
+			_, err := Upsert(
+				context.Background(),
+				db,
+				map[string]any{"id": 1, "name": "home"},
+				Table("pages"),
+				PK("id"),
+				WherePK(),
+				UpdateColumnExpr("hits", expr),
+			)
+			if err != nil {
+				t.Fatalf("upsert expr: %v", err)
+			}
+			if !strings.Contains(exec.query, tc.want) {
+				t.Fatalf("unexpected SQL:\n got: %s\nwant suffix: %s", exec.query, tc.want)
+			}
+			if len(exec.args) != 3 || exec.args[2] != 1 {
+				t.Fatalf("expression args must follow insert args: %#v", exec.args)
+			}
+		})
+	}
+}
+
+func TestUpsertUpdateColumnsAssign(t *testing.T) {
+	db, exec := newCaptureWriteDB(driver.MySQLDialect{})
+	_, err := Upsert(
+		context.Background(),
+		db,
+		map[string]any{"id": 1, "name": "home", "hits": 0},
+		Table("pages"),
+		PK("id"),
+		WherePK(),
+		UpdateColumns("name", Assign("hits", NewExpr("`hits` + ?", 1))),
+	)
+	if err != nil {
+		t.Fatalf("upsert assign: %v", err)
+	}
+	want := "ON DUPLICATE KEY UPDATE `name`=VALUES(`name`), `hits`=`hits` + ?"
+	if !strings.HasSuffix(exec.query, want) {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant suffix: %s", exec.query, want)
+	}
+
+	plan, err := upsertPlan(db, map[string]any{"id": 1, "name": "home"}, applyWriteOpts([]WriteOpt{
+		Table("pages"), PK("id"), WherePK(),
+		UpdateColumns(Assign("hits", NewExpr("`hits` + ?", 1))),
+	}))
+	if err != nil {
+		t.Fatalf("upsert plan: %v", err)
+	}
+	exprs, _ := plan.Metadata["update_expressions"].(map[string]string)
+	if exprs["hits"] != "`hits` + ?" {
+		t.Fatalf("expected update expressions in plan metadata: %#v", plan.Metadata)
+	}
+
+	_, err = Upsert(context.Background(), db, map[string]any{"id": 1}, Table("pages"), PK("id"), WherePK(), UpdateColumns(1))
+	if err == nil || !strings.Contains(err.Error(), "UpdateColumns") {
+		t.Fatalf("expected UpdateColumns type error, got %v", err)
+	}
+}
+
+func TestUpdatePostgresBindsArrayValues(t *testing.T) {
+	db, exec := newCaptureWriteDB(driver.PostgresDialect{})
+
+	_, err := Update(
+		context.Background(),
+		db,
+		map[string]any{"id": 5, "tags": []string{"a", `b"c`}},
+		Table("posts"),
+		PK("id"),
+		Columns("tags"),
+		WherePK(),
+	)
+	if err != nil {
+		t.Fatalf("update arrays: %v", err)
+	}
+	if want := []any{`{"a","b\"c"}`, 5}; !reflect.DeepEqual(exec.args, want) {
+		t.Fatalf("unexpected args: %#v", exec.args)
+	}
+
+	if _, err := Insert(context.Background(), db, map[string]any{"scores": []int64{1, 2}}, Table("posts")); err != nil {
+		t.Fatalf("insert arrays: %v", err)
+	}
+	if want := []any{"{1,2}"}; !reflect.DeepEqual(exec.args, want) {
+		t.Fatalf("unexpected args: %#v", exec.args)
+	}
+}
+
+func TestUpdateMySQLLeavesSliceValues(t *testing.T) {
+	db, exec := newCaptureWriteDB(driver.MySQLDialect{})
+
+	tags := []string{"a"}
+	if _, err := Update(context.Background(), db, map[string]any{"id": 5, "tags": tags}, Table("posts"), PK("id"), Columns("tags"), WherePK()); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got, ok := exec.args[0].([]string); !ok || !reflect.DeepEqual(got, tags) {
+		t.Fatalf("expected slice argument to pass through, got %#v", exec.args[0])
+	}
+}
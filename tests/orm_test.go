@@ -33,9 +33,9 @@ func rawQueryRow(t testing.TB, db *orm.DB, ctx context.Context, q string, args .
 	return row
 }
 
-func setupDB(t testing.TB) *orm.DB {
+func setupDB(t testing.TB, opts ...orm.Option) *orm.DB {
 	dsn, explicit := lookupTestDSN("TEST_MYSQL_DSN", defaultMySQLTestDSN)
-	db := openTestDB(t, orm.MySQL, dsn, explicit)
+	db := openTestDB(t, orm.MySQL, dsn, explicit, opts...)
 	var err error
 	stdDB := db.SQLDB()
 	_, err = stdDB.Exec(`CREATE TABLE IF NOT EXISTS users (
@@ -137,6 +137,38 @@ func BenchmarkScannerStruct(b *testing.B) {
 	}
 }
 
+func BenchmarkScannerStructStatementCache(b *testing.B) {
+	db := setupDB(b, orm.WithStatementCache(64))
+	defer db.Close()
+	for i := 0; i < b.N; i++ {
+		var user User
+		if err := db.Model(&User{}).Where("id", 1).First(&user); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(db.StatementCacheStats().Hits)/float64(b.N), "hits/op")
+}
+
+func BenchmarkInsertStatementCache(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []orm.Option
+	}{
+		{name: "direct"},
+		{name: "cached", opts: []orm.Option{orm.WithStatementCache(64)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			db := setupDB(b, bc.opts...)
+			defer db.Close()
+			for i := 0; i < b.N; i++ {
+				if _, err := db.Table("profiles").Insert(map[string]any{"user_id": 1, "bio": "bench"}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func TestInsert(t *testing.T) {
 	db := setupDB(t)
 	defer db.Close()
@@ -27,12 +27,12 @@ func isConnectionUnavailable(err error) bool {
 	return errors.As(err, &opErr)
 }
 
-func openTestDB(t testing.TB, driverName, dsn string, explicit bool) *orm.DB {
+func openTestDB(t testing.TB, driverName, dsn string, explicit bool, opts ...orm.Option) *orm.DB {
 	t.Helper()
 
 	var lastErr error
 	for i := 0; i < 20; i++ {
-		db, err := orm.OpenWithDriver(driverName, dsn)
+		db, err := orm.OpenWithDriverOptions(driverName, dsn, opts...)
 		if err == nil {
 			return db
 		}